@@ -9,17 +9,18 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/adrg/xdg"
 	"github.com/fsnotify/fsnotify"
-	gitignore "github.com/sabhiram/go-gitignore"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 
+	"shotgun_code/internal/ignore"
 	"shotgun_code/internal/labgradient"
+	"shotgun_code/internal/llm/provider"
+	"shotgun_code/internal/walker"
 )
 
 const maxOutputSizeBytes = 10_000_000 // 10MB
@@ -46,9 +47,16 @@ type LLMSettings struct {
 }
 
 type AppSettings struct {
-	CustomIgnoreRules string      `json:"customIgnoreRules"`
-	CustomPromptRules string      `json:"customPromptRules"`
-	LLMSettings       LLMSettings `json:"llmSettings"`
+	CustomIgnoreRules string            `json:"customIgnoreRules"`
+	CustomPromptRules string            `json:"customPromptRules"`
+	IncludePatterns   string            `json:"includePatterns"`
+	SymlinkPolicy     string            `json:"symlinkPolicy"`    // "skip", "list" or "follow"; empty means "list"
+	MaxFileSizeBytes  int64             `json:"maxFileSizeBytes"` // Per-file ceiling for context generation; 0 means the default
+	LargeFilePolicy   string            `json:"largeFilePolicy"`  // "omit" or "truncate"; empty means "truncate"
+	IncludeSymbolMap  bool              `json:"includeSymbolMap"` // List symbols of unselected files in generated context
+	AutoContextMode   string            `json:"autoContextMode"`  // "single", "iterative" or "offline"; empty means "single"
+	Retrieval         RetrievalSettings `json:"retrieval"`
+	LLMSettings       LLMSettings       `json:"llmSettings"`
 }
 
 type App struct {
@@ -56,13 +64,21 @@ type App struct {
 	contextGenerator            *ContextGenerator
 	fileWatcher                 *Watchman
 	settings                    AppSettings
-	currentCustomIgnorePatterns *gitignore.GitIgnore
+	currentCustomIgnorePatterns *ignore.RuleSet
 	configPath                  string
 	useGitignore                bool
 	useCustomIgnore             bool
-	projectGitignore            *gitignore.GitIgnore // Compiled .gitignore for the current project
+	currentIncludePatterns      *includeMatcher // Compiled include globs, applied only when useIncludePatterns is set
+	useIncludePatterns          bool
+	ignoreMu                    sync.Mutex    // Guards projectGitignore, used from bound methods and the watcher
+	projectGitignore            *ignore.Stack // Nested .gitignore files and git excludes for the current project
+	searchMu                    sync.Mutex
+	searchIndex                 *fileSearchIndex // Built by the first SearchFiles call
+	symbolCache                 symbolCache      // Symbols of project files, for the symbol map
+	retrieval                   retrievalState   // Embedding index of the current project, when retrieval is used
 	autoContextService          *AutoContextService
 	historyManager              *HistoryManager
+	promptTemplates             *PromptTemplateRegistry
 	llmCache                    cachedProvider
 	autoContextButtonTexture    string
 }
@@ -76,6 +92,7 @@ func (a *App) startup(ctx context.Context) {
 	a.contextGenerator = NewContextGenerator(a)
 	a.autoContextService = NewAutoContextService()
 	a.historyManager = NewHistoryManager(a)
+	a.promptTemplates = NewPromptTemplateRegistry(a)
 	a.fileWatcher = NewWatchman(a)
 	a.useGitignore = true    // Default to true, matching frontend
 	a.useCustomIgnore = true // Default to true, matching frontend
@@ -93,6 +110,9 @@ func (a *App) startup(ctx context.Context) {
 	if err := a.historyManager.LoadHistory(); err != nil {
 		runtime.LogWarningf(a.ctx, "Failed to load prompt history: %v", err)
 	}
+	if err := a.promptTemplates.Load(); err != nil {
+		runtime.LogWarningf(a.ctx, "Failed to load prompt templates: %v", err)
+	}
 
 	// Ensure CustomPromptRules has a default if it's empty after loading
 	if strings.TrimSpace(a.settings.CustomPromptRules) == "" {
@@ -132,6 +152,14 @@ type FileNode struct {
 	Path            string      `json:"path"`    // Full path
 	RelPath         string      `json:"relPath"` // Path relative to selected root
 	IsDir           bool        `json:"isDir"`
+	IsSymlink       bool        `json:"isSymlink,omitempty"`   // Listed link whose target is not read
+	Size            int64       `json:"size"`                  // File size in bytes; for directories, the total included in the context
+	ModTime         int64       `json:"modTime"`               // Unix milliseconds; latest file below for directories
+	Language        string      `json:"language,omitempty"`    // Detected from the file name, empty when unknown
+	Tokens          int64       `json:"tokens"`                // Estimated tokens; for directories, the total included in the context
+	FileCount       int         `json:"fileCount,omitempty"`   // Files below a directory included in the context
+	HasChildren     bool        `json:"hasChildren,omitempty"` // Directory has entries to expand
+	IsOversized     bool        `json:"isOversized,omitempty"` // Above the per-file ceiling for context generation
 	Children        []*FileNode `json:"children,omitempty"`
 	IsGitignored    bool        `json:"isGitignored"`    // True if path matches a .gitignore rule
 	IsCustomIgnored bool        `json:"isCustomIgnored"` // True if path matches a ignore.glob rule
@@ -145,25 +173,14 @@ func (a *App) SelectDirectory() (string, error) {
 // ListFiles lists files and folders in a directory, parsing .gitignore if present
 func (a *App) ListFiles(dirPath string) ([]*FileNode, error) {
 	runtime.LogDebugf(a.ctx, "ListFiles called for directory: %s", dirPath)
+	return a.listFileTree(dirPath)
+}
 
-	a.projectGitignore = nil        // Reset for the new directory
-	var gitIgn *gitignore.GitIgnore // For .gitignore in the project directory
-	gitignorePath := filepath.Join(dirPath, ".gitignore")
-	runtime.LogDebugf(a.ctx, "Attempting to find .gitignore at: %s", gitignorePath)
-	if _, err := os.Stat(gitignorePath); err == nil {
-		runtime.LogDebugf(a.ctx, ".gitignore found at: %s", gitignorePath)
-		gitIgn, err = gitignore.CompileIgnoreFile(gitignorePath)
-		if err != nil {
-			runtime.LogWarningf(a.ctx, "Error compiling .gitignore file at %s: %v", gitignorePath, err)
-			gitIgn = nil
-		} else {
-			a.projectGitignore = gitIgn // Store the compiled project-specific gitignore
-			runtime.LogDebug(a.ctx, ".gitignore compiled successfully.")
-		}
-	} else {
-		runtime.LogDebugf(a.ctx, ".gitignore not found at %s (os.Stat error: %v)", gitignorePath, err)
-		gitIgn = nil
-	}
+// listFileTree builds the FileNode tree for ListFiles without touching the Wails runtime.
+func (a *App) listFileTree(dirPath string) ([]*FileNode, error) {
+	// Rules from every .gitignore in the tree, .git/info/exclude and core.excludesFile.
+	// Nested .gitignore files are loaded lazily as the walker enters each directory.
+	gitIgn := a.newProjectIgnoreStack(dirPath)
 
 	// App-level custom ignore patterns are in a.currentCustomIgnorePatterns
 
@@ -177,95 +194,17 @@ func (a *App) ListFiles(dirPath string) ([]*FileNode, error) {
 		IsCustomIgnored: a.currentCustomIgnorePatterns != nil && a.currentCustomIgnorePatterns.MatchesPath("."),
 	}
 
-	children, err := buildTreeRecursive(context.TODO(), dirPath, dirPath, gitIgn, a.currentCustomIgnorePatterns, 0)
+	tree, err := walker.Walk(context.TODO(), dirPath, a.listingWalkOptions(gitIgn))
 	if err != nil {
 		return []*FileNode{rootNode}, fmt.Errorf("error building children tree for %s: %w", dirPath, err)
 	}
-	rootNode.Children = children
-
-	return []*FileNode{rootNode}, nil
-}
-
-func buildTreeRecursive(ctx context.Context, currentPath, rootPath string, gitIgn *gitignore.GitIgnore, customIgn *gitignore.GitIgnore, depth int) ([]*FileNode, error) {
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	default:
+	totals := a.treeTotals()
+	rootNode.Children = fileNodesFromEntries(tree.Children, totals)
+	for _, child := range rootNode.Children {
+		rootNode.addTotals(child, totals)
 	}
 
-	entries, err := os.ReadDir(currentPath)
-	if err != nil {
-		return nil, err
-	}
-
-	var nodes []*FileNode
-	for _, entry := range entries {
-		nodePath := filepath.Join(currentPath, entry.Name())
-		relPath, _ := filepath.Rel(rootPath, nodePath)
-		// For gitignore matching, paths should generally be relative to the .gitignore file (rootPath)
-		// and use OS-specific separators. go-gitignore handles this.
-
-		isGitignored := false
-		isCustomIgnored := false
-		pathToMatch := relPath
-		if entry.IsDir() {
-			if !strings.HasSuffix(pathToMatch, string(os.PathSeparator)) {
-				pathToMatch += string(os.PathSeparator)
-			}
-		}
-
-		if gitIgn != nil {
-			isGitignored = gitIgn.MatchesPath(pathToMatch)
-		}
-		if customIgn != nil {
-			isCustomIgnored = customIgn.MatchesPath(pathToMatch)
-		}
-
-		if depth < 2 || strings.Contains(relPath, "node_modules") || strings.HasSuffix(relPath, ".log") {
-			fmt.Printf("Checking path: '%s' (original relPath: '%s'), IsDir: %v, Gitignored: %v, CustomIgnored: %v\n", pathToMatch, relPath, entry.IsDir(), isGitignored, isCustomIgnored)
-		}
-
-		node := &FileNode{
-			Name:            entry.Name(),
-			Path:            nodePath,
-			RelPath:         relPath,
-			IsDir:           entry.IsDir(),
-			IsGitignored:    isGitignored,
-			IsCustomIgnored: isCustomIgnored,
-		}
-
-		if entry.IsDir() {
-			// If it's a directory, recursively call buildTree.
-			// Recursion stops only for folders ignored by custom rules.
-			// For folders matched by .gitignore, recursion continues so the UI can show their contents
-			// and allow selective inclusion of files inside gitignored folders.
-			if !isCustomIgnored {
-				children, err := buildTreeRecursive(ctx, nodePath, rootPath, gitIgn, customIgn, depth+1)
-				if err != nil {
-					if errors.Is(err, context.Canceled) {
-						return nil, err // Propagate cancellation
-					}
-					// runtime.LogWarnf(ctx, "Error building subtree for %s: %v", nodePath, err) // Use ctx if available
-					runtime.LogWarningf(context.Background(), "Error building subtree for %s: %v", nodePath, err) // Fallback for now
-					// Decide: skip this dir or return error up. For now, skip with log.
-				} else {
-					node.Children = children
-				}
-			}
-		}
-		nodes = append(nodes, node)
-	}
-	// Sort nodes: directories first, then files, then alphabetically
-	sort.SliceStable(nodes, func(i, j int) bool {
-		if nodes[i].IsDir && !nodes[j].IsDir {
-			return true
-		}
-		if !nodes[i].IsDir && nodes[j].IsDir {
-			return false
-		}
-		return strings.ToLower(nodes[i].Name) < strings.ToLower(nodes[j].Name)
-	})
-	return nodes, nil
+	return []*FileNode{rootNode}, nil
 }
 
 // ContextGenerator manages the asynchronous generation of shotgun context
@@ -353,146 +292,155 @@ func (a *App) RequestShotgunContextGeneration(rootDir string, excludedPaths []st
 	a.contextGenerator.requestShotgunContextGenerationInternal(rootDir, excludedPaths)
 }
 
-func (a *App) RequestAutoContextSelection(rootDir string, excludedPaths []string, userTask string) ([]string, error) {
+func (a *App) RequestAutoContextSelection(rootDir string, excludedPaths []string, userTask string) (AutoContextSelection, error) {
 	if a.autoContextService == nil {
-		return nil, errors.New("auto-context service is not initialized")
+		return AutoContextSelection{}, errors.New("auto-context service is not initialized")
 	}
 	rootDir = strings.TrimSpace(rootDir)
 	if rootDir == "" {
-		return nil, errors.New("project root is required")
+		return AutoContextSelection{}, errors.New("project root is required")
 	}
+	// Without a configured provider, files are ranked offline instead.
+	mode := a.autoContextMode()
 	if !a.HasActiveLlmKey() {
-		return nil, errors.New("no active LLM configuration found")
+		mode = autoContextModeOffline
 	}
 
-	// Prepare excluded paths map
-	excludedMap := make(map[string]bool)
-	for _, p := range excludedPaths {
-		excludedMap[normalizeRelativePath(p)] = true
+	task := strings.TrimSpace(userTask)
+	opts := a.selectionWalkOptions(excludedPaths)
+	if a.settings.Retrieval.Enabled && task != "" {
+		// Show the model only the files most similar to the task.
+		keep, err := a.retrievalPrefilter(rootDir, task)
+		if errors.Is(err, errRetrievalIndexBuilding) {
+			runtime.LogInfo(a.ctx, "Auto-context retrieval pre-filter skipped: the index is still being built")
+		} else if err != nil {
+			runtime.LogWarningf(a.ctx, "Auto-context retrieval pre-filter skipped: %v", err)
+		} else if len(keep) > 0 {
+			opts.Filters = append(opts.Filters, walker.MatchFiles(retrievalFilterName, func(rel string) bool { return keep[rel] }))
+			opts.PruneEmptyDirs = true
+			runtime.LogInfof(a.ctx, "Auto-context pre-filtered to %d files by retrieval", len(keep))
+		}
 	}
 
-	tree, err := buildAutoContextTree(rootDir, excludedMap)
-	if err != nil {
+	tree, treeEntries, err := buildAutoContextTree(a.ctx, rootDir, opts)
+	hierarchical := errors.Is(err, errAutoContextTreeTooLarge)
+	if err != nil && !hierarchical {
 		a.emitAutoContextError(fmt.Sprintf("failed to build project tree: %v", err))
-		return nil, err
+		return AutoContextSelection{}, err
 	}
 
-	task := strings.TrimSpace(userTask)
-	// Build LLM prompt for auto-context selection
-	prompt, err := a.autoContextService.BuildPrompt(tree, task, "")
-	if err != nil {
-		a.emitAutoContextError(fmt.Sprintf("failed to render auto-context prompt: %v", err))
-		return nil, err
-	}
-
-	cfg := buildProviderConfig(a.settings.LLMSettings)
-	providerInstance, err := a.getOrCreateProvider(cfg)
-	if err != nil {
-		a.emitAutoContextError(fmt.Sprintf("failed to configure provider: %v", err))
-		return nil, err
+	var llm provider.LLMProvider
+	via := "offline ranking"
+	if mode != autoContextModeOffline {
+		cfg := buildProviderConfig(a.settings.LLMSettings)
+		llm, err = a.getOrCreateProvider(cfg)
+		if err != nil {
+			a.emitAutoContextError(fmt.Sprintf("failed to configure provider: %v", err))
+			return AutoContextSelection{}, err
+		}
+		via = fmt.Sprintf("%s (%s)", cfg.Provider, cfg.Model)
+		if hierarchical {
+			runtime.LogInfof(a.ctx, "Auto-context tree exceeds %d characters, selecting hierarchically", maxAutoContextTreeChars)
+		}
 	}
 
-	// Execute LLM call
-	raw, apiCall, err := providerInstance.Generate(a.ctx, prompt)
-
-	// Log to shared prompt history for diagnostics (Step 3 view).
-	if a.historyManager != nil {
-		historyLabel := "AUTO CONTEXT"
-		if task != "" {
-			const maxLabelRunes = 80
-			runes := []rune(task)
-			if len(runes) > maxLabelRunes {
-				historyLabel = "AUTO CONTEXT: " + string(runes[:maxLabelRunes]) + "…"
-			} else {
-				historyLabel = "AUTO CONTEXT: " + task
-			}
+	// Each model call is logged to the shared prompt history for diagnostics (Step 3 view).
+	parsed, err := a.autoContextService.selectAutoContext(a.ctx, llm, rootDir, treeEntries, tree, hierarchical, mode, task, a.largeFileSettings().MaxFileSizeBytes, func(ex autoContextExchange) {
+		label := autoContextHistoryLabel(task)
+		switch {
+		case hierarchical:
+			label += fmt.Sprintf(" (call %d, up to %d)", ex.Round, ex.MaxRounds)
+		case mode == autoContextModeIterative:
+			label += fmt.Sprintf(" (round %d/%d)", ex.Round, ex.MaxRounds)
 		}
-
-		responseForHistory := raw
-		if err != nil {
-			responseForHistory = fmt.Sprintf("ERROR during auto-context LLM call: %v", err)
+		if ex.Repair {
+			label += " (repair)"
 		}
-		a.historyManager.AddItem(historyLabel, prompt, responseForHistory, apiCall)
+		if errors.Is(ex.Err, provider.ErrSchemaUnsupported) {
+			runtime.LogWarningf(a.ctx, "Structured auto-context call rejected, retrying without a schema: %v", ex.Err)
+		}
+		a.recordAutoContextHistory(label, ex.Prompt, ex.Response, ex.APICall, ex.Err)
+	})
+	if err != nil {
+		a.emitAutoContextError(err.Error())
+		return AutoContextSelection{}, err
 	}
 
+	selected, err := resolveLLMSelection(rootDir, treeEntries, parsed.Files)
 	if err != nil {
-		a.emitAutoContextError(fmt.Sprintf("provider error: %v", err))
-		return nil, err
+		a.emitAutoContextError(fmt.Sprintf("unable to match LLM selection to files: %v", err))
+		return AutoContextSelection{}, err
 	}
 
-	parsed, err := a.autoContextService.ParseResponse(raw)
-	if err != nil {
-		a.emitAutoContextError(fmt.Sprintf("failed to parse LLM response: %v", err))
-		return nil, err
+	runtime.LogInfof(a.ctx, "Auto-context selected %d files via %s", len(selected), via)
+	return AutoContextSelection{Files: selected, Reasoning: parsed.Reasoning}, nil
+}
+
+func autoContextHistoryLabel(task string) string {
+	if task == "" {
+		return "AUTO CONTEXT"
+	}
+	const maxLabelRunes = 80
+	runes := []rune(task)
+	if len(runes) > maxLabelRunes {
+		return "AUTO CONTEXT: " + string(runes[:maxLabelRunes]) + "…"
 	}
+	return "AUTO CONTEXT: " + task
+}
 
-	selected, err := resolveLLMSelection(rootDir, parsed.Files)
-	if err != nil {
-		a.emitAutoContextError(fmt.Sprintf("unable to match LLM selection to files: %v", err))
-		return nil, err
+// recordAutoContextHistory logs an auto-context call to the shared prompt history.
+func (a *App) recordAutoContextHistory(label, prompt, response, apiCall string, callErr error) {
+	if a.historyManager == nil {
+		return
 	}
+	if callErr != nil {
+		response = fmt.Sprintf("ERROR during auto-context LLM call: %v", callErr)
+	}
+	a.historyManager.AddItem(label, prompt, response, apiCall)
+}
 
-	runtime.LogInfof(a.ctx, "Auto-context selected %d files via %s (%s)", len(selected), cfg.Provider, cfg.Model)
-	return selected, nil
+// processableItems is the work ahead of the generator, used for progress tracking.
+type processableItems struct {
+	total     int // Operations: the root line, each tree entry and each file content read
+	oversized int // Files above the per-file size ceiling
 }
 
 // countProcessableItems estimates the total number of operations for progress tracking.
 // Operations: 1 for root dir line, 1 for each dir/file entry in tree, 1 for each file content read.
-func (a *App) countProcessableItems(jobCtx context.Context, rootDir string, excludedMap map[string]bool) (int, error) {
-	count := 1 // For the root directory line itself
-
-	var counterHelper func(currentPath string) error
-	counterHelper = func(currentPath string) error {
-		select {
-		case <-jobCtx.Done():
-			return jobCtx.Err()
-		default:
-		}
-
-		entries, err := os.ReadDir(currentPath)
-		if err != nil {
-			runtime.LogWarningf(a.ctx, "countProcessableItems: error reading dir %s: %v", currentPath, err)
-			return nil // Continue counting other parts if a subdir is inaccessible
+// Omitted large and binary files have no content read. It counts the same walked tree the generator
+// renders, so both always agree.
+func countProcessableItems(tree *walker.Entry, policy string) processableItems {
+	var items processableItems
+	tree.Visit(func(entry *walker.Entry) error {
+		items.total++ // For the root line or the tree entry (dir or file)
+		if !entry.HasContent() || entry.Marked(walker.FilterBinary) {
+			return nil
 		}
-
-		for _, entry := range entries {
-			path := filepath.Join(currentPath, entry.Name())
-			relPath, _ := filepath.Rel(rootDir, path)
-
-			if excludedMap[relPath] {
-				continue
-			}
-
-			count++ // For the tree entry (dir or file)
-
-			if entry.IsDir() {
-				err := counterHelper(path)
-				if err != nil { // Propagate cancellation or critical errors
-					return err
-				}
-			} else {
-				count++ // For reading the file content
+		if entry.Marked(walker.FilterSize) {
+			items.oversized++
+			if policy == largeFilePolicyOmit {
+				return nil
 			}
 		}
+		items.total++ // For reading the file content
 		return nil
-	}
-
-	err := counterHelper(rootDir)
-	if err != nil {
-		return 0, err // Return error if counting was interrupted (e.g. context cancelled)
-	}
-	return count, nil
+	})
+	return items
 }
 
 type generationProgressState struct {
-	processedItems int
-	totalItems     int
+	processedItems  int
+	totalItems      int
+	oversizedFiles  int    // Files above the per-file ceiling
+	largeFilePolicy string // What happens to them: "omit" or "truncate"
 }
 
 func (a *App) emitProgress(state *generationProgressState) {
-	runtime.EventsEmit(a.ctx, "shotgunContextGenerationProgress", map[string]int{
-		"current": state.processedItems,
-		"total":   state.totalItems,
+	runtime.EventsEmit(a.ctx, "shotgunContextGenerationProgress", map[string]any{
+		"current":         state.processedItems,
+		"total":           state.totalItems,
+		"oversized":       state.oversizedFiles,
+		"largeFilePolicy": state.largeFilePolicy,
 	})
 }
 
@@ -502,17 +450,28 @@ func (a *App) generateShotgunOutputWithProgress(jobCtx context.Context, rootDir
 		return "", err
 	}
 
-	excludedMap := make(map[string]bool)
-	for _, p := range excludedPaths {
-		excludedMap[p] = true
+	output, items, err := a.generateShotgunOutput(jobCtx, rootDir, excludedPaths, a.emitProgress)
+	if items.oversized > 0 {
+		largeFiles := a.largeFileSettings()
+		runtime.LogInfof(a.ctx, "%d files exceed the per-file limit of %d bytes (policy: %s)", items.oversized, largeFiles.MaxFileSizeBytes, largeFiles.Policy)
 	}
+	return output, err
+}
 
-	totalItems, err := a.countProcessableItems(jobCtx, rootDir, excludedMap)
+// generateShotgunOutput renders the context for the selection, reporting progress
+// through progress. It does not touch the Wails runtime.
+func (a *App) generateShotgunOutput(jobCtx context.Context, rootDir string, excludedPaths []string, progress func(*generationProgressState)) (string, processableItems, error) {
+	opts := a.selectionWalkOptions(excludedPaths)
+	// Binary files are listed in the tree, but their content is left out.
+	opts.Filters = append(opts.Filters, walker.Binary(walker.Mark))
+	tree, err := walker.Walk(jobCtx, rootDir, opts)
 	if err != nil {
-		return "", fmt.Errorf("failed to count processable items: %w", err)
+		return "", processableItems{}, fmt.Errorf("failed to walk project tree: %w", err)
 	}
-	progressState := &generationProgressState{processedItems: 0, totalItems: totalItems}
-	a.emitProgress(progressState) // Initial progress (0 / total)
+	largeFiles := a.largeFileSettings()
+	items := countProcessableItems(tree, largeFiles.Policy)
+	progressState := &generationProgressState{processedItems: 0, totalItems: items.total, oversizedFiles: items.oversized, largeFilePolicy: largeFiles.Policy}
+	progress(progressState) // Initial progress (0 / total)
 
 	var output strings.Builder
 	var fileContents strings.Builder
@@ -520,132 +479,102 @@ func (a *App) generateShotgunOutputWithProgress(jobCtx context.Context, rootDir
 	// Root directory line
 	output.WriteString(filepath.Base(rootDir) + string(os.PathSeparator) + "\n")
 	progressState.processedItems++
-	a.emitProgress(progressState)
+	progress(progressState)
 	if output.Len() > maxOutputSizeBytes {
-		return "", fmt.Errorf("%w: content limit of %d bytes exceeded after root dir line (size: %d bytes)", ErrContextTooLong, maxOutputSizeBytes, output.Len())
+		return "", items, fmt.Errorf("%w: content limit of %d bytes exceeded after root dir line (size: %d bytes)", ErrContextTooLong, maxOutputSizeBytes, output.Len())
 	}
 
-	// buildShotgunTreeRecursive is a recursive helper for generating the tree string and file contents
-	var buildShotgunTreeRecursive func(pCtx context.Context, currentPath, prefix string) error
-	buildShotgunTreeRecursive = func(pCtx context.Context, currentPath, prefix string) error {
+	err = walker.Render(tree, func(entry *walker.Entry, line string) error {
 		select {
-		case <-pCtx.Done():
-			return pCtx.Err()
+		case <-jobCtx.Done():
+			return jobCtx.Err()
 		default:
 		}
 
-		entries, err := os.ReadDir(currentPath)
-		if err != nil {
-			runtime.LogWarningf(a.ctx, "buildShotgunTreeRecursive: error reading dir %s: %v", currentPath, err)
-			// Decide if this error should halt the entire process or just skip this directory
-			// For now, returning nil to skip, but log it. Could also return the error.
-			return nil // Or return err if this should stop everything
-		}
-
-		// Sort entries like in ListFiles for consistent tree
-		sort.SliceStable(entries, func(i, j int) bool {
-			entryI := entries[i]
-			entryJ := entries[j]
-			isDirI := entryI.IsDir()
-			isDirJ := entryJ.IsDir()
-			if isDirI && !isDirJ {
-				return true
-			}
-			if !isDirI && isDirJ {
-				return false
-			}
-			return strings.ToLower(entryI.Name()) < strings.ToLower(entryJ.Name())
-		})
-
-		// Create a temporary slice to hold non-excluded entries for correct prefixing
-		var visibleEntries []fs.DirEntry
-		for _, entry := range entries {
-			path := filepath.Join(currentPath, entry.Name())
-			relPath, _ := filepath.Rel(rootDir, path)
-			if !excludedMap[relPath] {
-				visibleEntries = append(visibleEntries, entry)
+		oversized := entry.HasContent() && entry.Marked(walker.FilterSize)
+		binary := entry.HasContent() && entry.Marked(walker.FilterBinary)
+		if binary {
+			line += fmt.Sprintf(" (%s, binary, omitted)", formatFileSize(entry.Size))
+		} else if oversized {
+			action := "omitted"
+			if largeFiles.Policy == largeFilePolicyTruncate {
+				action = "truncated"
 			}
+			line += fmt.Sprintf(" (%s, %s)", formatFileSize(entry.Size), action)
 		}
+		output.WriteString(line + "\n")
 
-		for i, entry := range visibleEntries {
-			select {
-			case <-pCtx.Done():
-				return pCtx.Err()
-			default:
-			}
+		progressState.processedItems++ // For tree entry
+		progress(progressState)
 
-			path := filepath.Join(currentPath, entry.Name())
-			relPath, _ := filepath.Rel(rootDir, path)
-
-			isLast := i == len(visibleEntries)-1
-
-			branch := "├── "
-			nextPrefix := prefix + "│   "
-			if isLast {
-				branch = "└── "
-				nextPrefix = prefix + "    "
-			}
-			output.WriteString(prefix + branch + entry.Name() + "\n")
-
-			progressState.processedItems++ // For tree entry
-			a.emitProgress(progressState)
-
-			if output.Len()+fileContents.Len() > maxOutputSizeBytes {
-				return fmt.Errorf("%w: content limit of %d bytes exceeded during tree generation (size: %d bytes)", ErrContextTooLong, maxOutputSizeBytes, output.Len()+fileContents.Len())
-			}
+		if output.Len()+fileContents.Len() > maxOutputSizeBytes {
+			return fmt.Errorf("%w: content limit of %d bytes exceeded during tree generation (size: %d bytes)", ErrContextTooLong, maxOutputSizeBytes, output.Len()+fileContents.Len())
+		}
 
-			if entry.IsDir() {
-				err := buildShotgunTreeRecursive(pCtx, path, nextPrefix)
-				if err != nil {
-					if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
-						return err
-					}
-					fmt.Printf("Error processing subdirectory %s: %v\n", path, err)
-				}
-			} else {
-				select { // Check before heavy I/O
-				case <-pCtx.Done():
-					return pCtx.Err()
-				default:
-				}
-				content, err := os.ReadFile(path)
-				if err != nil {
-					fmt.Printf("Error reading file %s: %v\n", path, err)
-					content = []byte(fmt.Sprintf("Error reading file: %v", err))
-				}
+		if !entry.HasContent() {
+			return nil // Directories and symlinks that were not followed have no content
+		}
+		if binary || (oversized && largeFiles.Policy == largeFilePolicyOmit) {
+			return nil
+		}
 
-				// Ensure forward slashes for the name attribute, consistent with documentation.
-				relPathForwardSlash := filepath.ToSlash(relPath)
+		content, truncated, err := readFileLimited(entry.Path, largeFiles.MaxFileSizeBytes)
+		if err != nil {
+			fmt.Printf("Error reading file %s: %v\n", entry.Path, err)
+			content = []byte(fmt.Sprintf("Error reading file: %v", err))
+		}
 
-				fileContents.WriteString(fmt.Sprintf("<file path=\"%s\">\n", relPathForwardSlash))
-				fileContents.WriteString(string(content))
-				fileContents.WriteString("\n</file>\n") // Each file block ends with a newline
+		// Ensure forward slashes for the name attribute, consistent with documentation.
+		if truncated {
+			fileContents.WriteString(fmt.Sprintf("<file path=\"%s\" truncated=\"first %d bytes\">\n", entry.SlashPath(), largeFiles.MaxFileSizeBytes))
+		} else {
+			fileContents.WriteString(fmt.Sprintf("<file path=\"%s\">\n", entry.SlashPath()))
+		}
+		fileContents.WriteString(string(content))
+		if truncated {
+			fileContents.WriteString(truncationMarker(int64(len(content)), max(entry.Size, largeFiles.MaxFileSizeBytes+1)))
+		}
+		fileContents.WriteString("\n</file>\n") // Each file block ends with a newline
 
-				progressState.processedItems++ // For file content
-				a.emitProgress(progressState)
+		progressState.processedItems++ // For file content
+		progress(progressState)
 
-				if output.Len()+fileContents.Len() > maxOutputSizeBytes { // Final check after append
-					return fmt.Errorf("%w: content limit of %d bytes exceeded after appending file %s (total size: %d bytes)", ErrContextTooLong, maxOutputSizeBytes, relPath, output.Len()+fileContents.Len())
-				}
-			}
+		if output.Len()+fileContents.Len() > maxOutputSizeBytes { // Final check after append
+			return fmt.Errorf("%w: content limit of %d bytes exceeded after appending file %s (total size: %d bytes)", ErrContextTooLong, maxOutputSizeBytes, entry.RelPath, output.Len()+fileContents.Len())
 		}
 		return nil
-	}
-
-	err = buildShotgunTreeRecursive(jobCtx, rootDir, "")
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to build tree for shotgun: %w", err)
+		return "", items, fmt.Errorf("failed to build tree for shotgun: %w", err)
 	}
 
 	if err := jobCtx.Err(); err != nil { // Check for cancellation before final string operations
-		return "", err
+		return "", items, err
+	}
+
+	// Optional third layer: exported symbols of the files that are not selected.
+	symbolMap := ""
+	if a.settings.IncludeSymbolMap {
+		// Files read for the map extend the progress count.
+		contentItems := progressState.totalItems
+		symbolMap, err = a.buildSymbolMap(jobCtx, rootDir, tree, maxOutputSizeBytes-output.Len()-fileContents.Len()-1, func(done, total int) {
+			progressState.totalItems = contentItems + total
+			progressState.processedItems = contentItems + done
+			progress(progressState)
+		})
+		if err != nil {
+			return "", items, err
+		}
+		if symbolMap != "" {
+			symbolMap += "\n"
+		}
 	}
 
-	// The final output is the tree, a newline, then all concatenated file contents.
+	// The final output is the tree, a newline, the optional symbol map, then all concatenated file contents.
 	// If fileContents is empty, we still want the newline after the tree.
 	// If fileContents is not empty, it already ends with a newline, so an extra one might not be desired
 	// depending on how it's structured. Given each <file> block ends with \n, this should be fine.
-	return output.String() + "\n" + strings.TrimRight(fileContents.String(), "\n"), nil
+	return output.String() + "\n" + symbolMap + strings.TrimRight(fileContents.String(), "\n"), items, nil
 }
 
 // --- Watchman Implementation ---
@@ -656,13 +585,18 @@ type Watchman struct {
 	fsWatcher   *fsnotify.Watcher
 	watchedDirs map[string]bool // Tracks directories explicitly added to fsnotify
 
+	// Ignore files outside the watched tree (.git/info/exclude, git config,
+	// core.excludesFile) and the directories watched only to see them change.
+	ignoreSources    map[string]bool
+	ignoreSourceDirs map[string]bool
+
 	// lastKnownState map[string]fileMeta // Removed, fsnotify handles state
 	mu         sync.Mutex // Changed to Mutex for simplicity with Start/Stop/Refresh
 	cancelFunc context.CancelFunc
 
 	// Store current patterns to be used by scanDirectoryStateInternal
-	currentProjectGitignore *gitignore.GitIgnore
-	currentCustomPatterns   *gitignore.GitIgnore
+	currentProjectGitignore *ignore.Stack
+	currentCustomPatterns   *ignore.RuleSet
 }
 
 func NewWatchman(app *App) *Watchman {
@@ -678,7 +612,13 @@ func (a *App) StartFileWatcher(rootDirPath string) error {
 	if a.fileWatcher == nil {
 		return fmt.Errorf("file watcher not initialized")
 	}
-	return a.fileWatcher.Start(rootDirPath)
+	if err := a.fileWatcher.Start(rootDirPath); err != nil {
+		return err
+	}
+	if a.settings.Retrieval.Enabled {
+		a.startRetrievalBuild(rootDirPath) // Ready by the time auto-context pre-filters
+	}
+	return nil
 }
 
 // StopFileWatcher is called by JavaScript to stop the current watcher.
@@ -704,8 +644,9 @@ func (w *Watchman) Start(newRootDir string) error {
 	w.mu.Unlock()
 
 	// Initialize patterns based on App's current state
+	projectIgnore := w.app.projectIgnoreStack(newRootDir)
 	if w.app.useGitignore {
-		w.currentProjectGitignore = w.app.projectGitignore
+		w.currentProjectGitignore = projectIgnore
 	} else {
 		w.currentProjectGitignore = nil
 	}
@@ -732,11 +673,48 @@ func (w *Watchman) Start(newRootDir string) error {
 
 	runtime.LogInfof(w.app.ctx, "Watchman: Starting for directory %s", newRootDir)
 	w.addPathsToWatcherRecursive(newRootDir) // Add initial paths
+	w.watchIgnoreSources(projectIgnore.GlobalSources())
 
-	go w.run(ctx)
+	go w.run(ctx, w.fsWatcher)
 	return nil
 }
 
+// watchIgnoreSources watches the directories holding the given ignore files, so
+// edits to them (including editors replacing the file) reach run. .git is never
+// part of the watched tree, and the other files live outside the project.
+func (w *Watchman) watchIgnoreSources(sources []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.ignoreSources = make(map[string]bool, len(sources))
+	w.ignoreSourceDirs = make(map[string]bool)
+	if w.fsWatcher == nil {
+		return
+	}
+	for _, source := range sources {
+		w.ignoreSources[source] = true
+		dir := filepath.Dir(source)
+		if w.watchedDirs[dir] || w.ignoreSourceDirs[dir] {
+			continue
+		}
+		if err := w.fsWatcher.Add(dir); err != nil {
+			// Missing directories are common (no ~/.config/git); creating them later
+			// is not picked up until the next rescan.
+			runtime.LogDebugf(w.app.ctx, "Watchman: Not watching ignore file directory %s: %v", dir, err)
+			continue
+		}
+		w.ignoreSourceDirs[dir] = true
+	}
+}
+
+// watchRelPath formats a project-relative path for the ignore matchers, which
+// expect a trailing slash on directories so that patterns like "build/" match.
+func watchRelPath(relPath string, isDir bool) string {
+	if isDir {
+		return relPath + string(filepath.Separator)
+	}
+	return relPath
+}
+
 func (w *Watchman) Stop() {
 	w.mu.Lock()
 	defer w.mu.Unlock()
@@ -757,12 +735,11 @@ func (w *Watchman) Stop() {
 	w.watchedDirs = make(map[string]bool) // Clear watched directories
 }
 
-func (w *Watchman) run(ctx context.Context) {
+func (w *Watchman) run(ctx context.Context, fsW *fsnotify.Watcher) {
 	defer func() {
-		if w.fsWatcher != nil {
-			// This close is a safeguard; Stop() should ideally be called.
-			w.fsWatcher.Close()
-		}
+		// This close is a safeguard; Stop() should ideally be called. It closes the
+		// watcher this goroutine was started with, not one created by a later Start.
+		fsW.Close()
 		runtime.LogInfo(w.app.ctx, "Watchman: Goroutine stopped.")
 	}()
 
@@ -780,7 +757,7 @@ func (w *Watchman) run(ctx context.Context) {
 			runtime.LogInfof(w.app.ctx, "Watchman: Context cancelled, shutting down watcher for %s.", shutdownRootDir)
 			return
 
-		case event, ok := <-w.fsWatcher.Events:
+		case event, ok := <-fsW.Events:
 			if !ok {
 				runtime.LogInfo(w.app.ctx, "Watchman: fsnotify events channel closed.")
 				return
@@ -792,22 +769,52 @@ func (w *Watchman) run(ctx context.Context) {
 			// Safely copy ignore patterns
 			projIgn := w.currentProjectGitignore
 			custIgn := w.currentCustomPatterns
+			isIgnoreSource := w.ignoreSources[event.Name]
+			onlyIgnoreSourceDir := w.ignoreSourceDirs[filepath.Dir(event.Name)] && !w.watchedDirs[filepath.Dir(event.Name)]
+			wasWatchedDir := w.watchedDirs[event.Name]
 			w.mu.Unlock()
 
 			if currentRootDir == "" { // Watcher might have been stopped
 				continue
 			}
 
+			// A changed ignore file can change verdicts anywhere below it, and folders it
+			// stops ignoring are not watched yet: reload the rules and start over.
+			if event.Op&fsnotify.Chmod == 0 && (isIgnoreSource || filepath.Base(event.Name) == ".gitignore") {
+				runtime.LogInfof(w.app.ctx, "Watchman: Ignore rules changed in %s, re-scanning.", event.Name)
+				if projIgn != nil {
+					projIgn.Reload()
+				}
+				w.app.markSearchIndexDirty() // Ignore flags may have flipped anywhere
+				if err := w.RefreshIgnoresAndRescan(); err != nil {
+					runtime.LogErrorf(w.app.ctx, "Watchman: Re-scan after ignore change failed: %v", err)
+				}
+				return // The rescan started a new goroutine
+			}
+			if onlyIgnoreSourceDir {
+				continue // Some other file next to an ignore file
+			}
+
 			relEventPath, err := filepath.Rel(currentRootDir, event.Name)
 			if err != nil {
 				runtime.LogWarningf(w.app.ctx, "Watchman: Could not get relative path for event %s (root: %s): %v", event.Name, currentRootDir, err)
 				continue
 			}
 
-			// Check if the event path is ignored
-			isIgnoredByGit := projIgn != nil && projIgn.MatchesPath(relEventPath)
-			isIgnoredByCustom := custIgn != nil && custIgn.MatchesPath(relEventPath)
+			// Check if the event path is ignored. Removed directories can no longer be
+			// stat'ed, so the watched set tells whether the path was a directory.
+			isDir := wasWatchedDir
+			if info, statErr := os.Lstat(event.Name); statErr == nil {
+				isDir = info.IsDir()
+			}
+			matchPath := watchRelPath(relEventPath, isDir)
+			isIgnoredByGit := projIgn != nil && projIgn.MatchesPath(matchPath)
+			isIgnoredByCustom := custIgn != nil && custIgn.MatchesPath(matchPath)
 
+			// The search index lists ignored paths too, flagged, so it sees every event.
+			if event.Op&fsnotify.Chmod == 0 {
+				w.app.updateSearchIndex(currentRootDir, event)
+			}
 			if isIgnoredByGit || isIgnoredByCustom {
 				runtime.LogDebugf(w.app.ctx, "Watchman: Ignoring event for %s as it's an ignored path.", event.Name)
 				continue
@@ -815,6 +822,7 @@ func (w *Watchman) run(ctx context.Context) {
 
 			// Handle relevant events (excluding Chmod)
 			if event.Op&fsnotify.Chmod == 0 {
+				w.app.noteRetrievalChange(currentRootDir, event)
 				runtime.LogInfof(w.app.ctx, "Watchman: Relevant change detected for %s in %s", event.Name, currentRootDir)
 				w.app.notifyFileChange(currentRootDir)
 			}
@@ -824,8 +832,8 @@ func (w *Watchman) run(ctx context.Context) {
 				info, statErr := os.Stat(event.Name)
 				if statErr == nil && info.IsDir() {
 					// Check if this new directory itself is ignored before adding
-					isNewDirIgnoredByGit := projIgn != nil && projIgn.MatchesPath(relEventPath)
-					isNewDirIgnoredByCustom := custIgn != nil && custIgn.MatchesPath(relEventPath)
+					isNewDirIgnoredByGit := projIgn != nil && projIgn.MatchesPath(watchRelPath(relEventPath, true))
+					isNewDirIgnoredByCustom := custIgn != nil && custIgn.MatchesPath(watchRelPath(relEventPath, true))
 					if !isNewDirIgnoredByGit && !isNewDirIgnoredByCustom {
 						runtime.LogDebugf(w.app.ctx, "Watchman: New directory created %s, adding to watcher.", event.Name)
 						w.addPathsToWatcherRecursive(event.Name) // This will add event.Name and its children
@@ -851,7 +859,7 @@ func (w *Watchman) run(ctx context.Context) {
 				w.mu.Unlock()
 			}
 
-		case err, ok := <-w.fsWatcher.Errors:
+		case err, ok := <-fsW.Errors:
 			if !ok {
 				runtime.LogInfo(w.app.ctx, "Watchman: fsnotify errors channel closed.")
 				return
@@ -902,8 +910,8 @@ func (w *Watchman) addPathsToWatcherRecursive(baseDirToAdd string) {
 			}
 		}
 
-		isIgnoredByGit := projIgn != nil && projIgn.MatchesPath(relPath)
-		isIgnoredByCustom := custIgn != nil && custIgn.MatchesPath(relPath)
+		isIgnoredByGit := relPath != "." && projIgn != nil && projIgn.MatchesPath(watchRelPath(relPath, true))
+		isIgnoredByCustom := relPath != "." && custIgn != nil && custIgn.MatchesPath(watchRelPath(relPath, true))
 
 		if isIgnoredByGit || isIgnoredByCustom {
 			runtime.LogDebugf(w.app.ctx, "Watchman.addPathsToWatcherRecursive: Skipping ignored directory: %s", path)
@@ -928,50 +936,41 @@ func (a *App) notifyFileChange(rootDir string) {
 	runtime.EventsEmit(a.ctx, "projectFilesChanged", rootDir)
 }
 
-// RefreshIgnoresAndRescan is called when ignore settings change in the App.
+// notifySettingsChanged asks the frontend to reload the open project after a setting
+// that changes what the tree lists or marks, e.g. include mode or the symlink policy,
+// and hands the new walk to retrieval refreshes.
+func (a *App) notifySettingsChanged() {
+	a.noteRetrievalSettings()
+	if a.fileWatcher == nil {
+		return
+	}
+	a.fileWatcher.mu.Lock()
+	rootDir := a.fileWatcher.rootDir
+	a.fileWatcher.mu.Unlock()
+	if rootDir != "" {
+		a.notifyFileChange(rootDir)
+	}
+}
+
+// RefreshIgnoresAndRescan is called when ignore settings or ignore files change. It
+// restarts the watcher with the current rules, so folders they no longer ignore are
+// watched, and tells the frontend to reload the tree.
 func (w *Watchman) RefreshIgnoresAndRescan() error {
 	w.mu.Lock()
-	if w.rootDir == "" {
-		w.mu.Unlock()
+	currentRootDir := w.rootDir
+	w.mu.Unlock()
+	if currentRootDir == "" {
 		runtime.LogInfo(w.app.ctx, "Watchman.RefreshIgnoresAndRescan: No rootDir, skipping.")
 		return nil
 	}
 	runtime.LogInfo(w.app.ctx, "Watchman.RefreshIgnoresAndRescan: Refreshing ignore patterns and re-scanning.")
 
-	// Update patterns based on App's current state
-	if w.app.useGitignore {
-		w.currentProjectGitignore = w.app.projectGitignore
-	} else {
-		w.currentProjectGitignore = nil
-	}
-	if w.app.useCustomIgnore {
-		w.currentCustomPatterns = w.app.currentCustomIgnorePatterns
-	} else {
-		w.currentCustomPatterns = nil
-	}
-	currentRootDir := w.rootDir
-	defer w.mu.Unlock()
-
-	// Stop existing watcher (closes, clears watchedDirs)
-	if w.cancelFunc != nil {
-		w.cancelFunc()
-	}
-	if w.fsWatcher != nil {
-		w.fsWatcher.Close()
-	}
-	w.watchedDirs = make(map[string]bool)
-
-	// Create new watcher
-	var err error
-	w.fsWatcher, err = fsnotify.NewWatcher()
-	if err != nil {
-		runtime.LogErrorf(w.app.ctx, "Watchman.RefreshIgnoresAndRescan: Error creating new fsnotify watcher: %v", err)
-		return fmt.Errorf("failed to create new fsnotify watcher: %w", err)
+	// Start picks up the App's current patterns and replaces the fsnotify watcher.
+	if err := w.Start(currentRootDir); err != nil {
+		runtime.LogErrorf(w.app.ctx, "Watchman.RefreshIgnoresAndRescan: Error restarting the watcher: %v", err)
+		return err
 	}
-
-	w.addPathsToWatcherRecursive(currentRootDir) // Add paths with new rules
-	w.app.notifyFileChange(currentRootDir)       // Notify frontend to refresh its view
-
+	w.app.notifyFileChange(currentRootDir) // Notify frontend to refresh its view
 	return nil
 }
 
@@ -983,19 +982,8 @@ func (a *App) compileCustomIgnorePatterns() error {
 		runtime.LogDebug(a.ctx, "Custom ignore rules are empty, no patterns compiled.")
 		return nil
 	}
-	lines := strings.Split(strings.ReplaceAll(a.settings.CustomIgnoreRules, "\r\n", "\n"), "\n")
-	var validLines []string
-	for _, line := range lines {
-		// CompileIgnoreLines should handle empty/comment lines appropriately based on .gitignore syntax
-		validLines = append(validLines, line)
-	}
-
-	ign := gitignore.CompileIgnoreLines(validLines...)
-	// Поскольку CompileIgnoreLines в этой версии не возвращает ошибку,
-	// проверка на err удалена.
-	// Если ign будет nil (например, если все строки были пустыми или комментариями,
-	// и библиотека так обрабатывает), то это будет корректно обработано ниже.
-	a.currentCustomIgnorePatterns = ign
+	// ParseRules keeps line numbers so ExplainIgnore can point at the matching rule.
+	a.currentCustomIgnorePatterns = ignore.ParseRules(customIgnoreRulesSource, "", a.settings.CustomIgnoreRules)
 	runtime.LogInfo(a.ctx, "Successfully compiled custom ignore patterns.")
 	return nil
 }
@@ -1048,6 +1036,12 @@ func (a *App) loadSettings() {
 	if errCompile := a.compileCustomIgnorePatterns(); errCompile != nil {
 		// Error already logged in compileCustomIgnorePatterns
 	}
+	if errInclude := a.compileIncludePatterns(); errInclude != nil {
+		runtime.LogWarningf(a.ctx, "Ignoring saved include patterns: %v", errInclude)
+	}
+	a.retrieval.mu.Lock()
+	a.retrieval.enabled = a.settings.Retrieval.Enabled
+	a.retrieval.mu.Unlock()
 }
 
 func (a *App) saveSettings() error {
@@ -1092,6 +1086,7 @@ func (a *App) SetCustomIgnoreRules(rules string) error {
 	// or save them and let the user know they are not effective.
 	// For now, compile then save. If compile fails, the old patterns (or nil) remain active.
 	compileErr := a.compileCustomIgnorePatterns()
+	a.noteRetrievalSettings()
 
 	saveErr := a.saveSettings()
 	if saveErr != nil {
@@ -1130,6 +1125,7 @@ func (a *App) SetCustomPromptRules(rules string) error {
 func (a *App) SetUseGitignore(enabled bool) error {
 	a.useGitignore = enabled
 	runtime.LogInfof(a.ctx, "App setting useGitignore changed to: %v", enabled)
+	a.noteRetrievalSettings()
 	if a.fileWatcher != nil && a.fileWatcher.rootDir != "" {
 		// Assuming watcher is for the current project if active.
 		return a.fileWatcher.RefreshIgnoresAndRescan()
@@ -1141,6 +1137,7 @@ func (a *App) SetUseGitignore(enabled bool) error {
 func (a *App) SetUseCustomIgnore(enabled bool) error {
 	a.useCustomIgnore = enabled
 	runtime.LogInfof(a.ctx, "App setting useCustomIgnore changed to: %v", enabled)
+	a.noteRetrievalSettings()
 	if a.fileWatcher != nil && a.fileWatcher.rootDir != "" {
 		// Assuming watcher is for the current project if active.
 		return a.fileWatcher.RefreshIgnoresAndRescan()
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"shotgun_code/internal/ignore"
+	"shotgun_code/internal/llm/provider"
+	"shotgun_code/internal/tokens"
+)
+
+const defaultAutoContextEvalSuite = "testdata/autocontext/suite.json"
+
+// autoContextEvalSuite is a set of golden auto-context cases, read from JSON. The
+// model calls of each case are kept in cassettes/<case name> next to the suite; the
+// shipped cassettes hold hand-written replies, not calls recorded from a vendor.
+type autoContextEvalSuite struct {
+	Cases []autoContextEvalCase `json:"cases"`
+}
+
+// autoContextEvalCase is one task on a fixture project and the files a good
+// selection contains.
+type autoContextEvalCase struct {
+	Name     string   `json:"name"`
+	Fixture  string   `json:"fixture"` // Project directory, relative to the suite file
+	Task     string   `json:"task"`
+	Mode     string   `json:"mode,omitempty"` // Auto-context mode; empty means "single"
+	Expected []string `json:"expected"`
+}
+
+// autoContextEvalOutcome is the score of one case against one model.
+type autoContextEvalOutcome struct {
+	Case      string
+	Mode      string
+	Precision float64
+	Recall    float64
+	Tokens    int64 // Estimated prompt and reply tokens of every model call
+	Calls     int
+	Missed    []string
+	Extra     []string
+	Err       error
+}
+
+// autoContextEvalCassette is the directory holding the recorded calls of c.
+func autoContextEvalCassette(baseDir string, c autoContextEvalCase) string {
+	return filepath.Join(baseDir, "cassettes", c.Name)
+}
+
+// replayAutoContextEval serves each case from its cassette, without a model.
+func replayAutoContextEval(baseDir string) func(autoContextEvalCase) (provider.LLMProvider, error) {
+	return func(c autoContextEvalCase) (provider.LLMProvider, error) {
+		return provider.Factory(provider.Config{Provider: "replay", CassetteDir: autoContextEvalCassette(baseDir, c)})
+	}
+}
+
+// runAutoContextEval implements the eval-autocontext command: every case of a suite
+// runs through auto-context with each model given, or replayed from its cassette,
+// and the selections are scored against the expected files.
+func runAutoContextEval(args []string) error {
+	flags := flag.NewFlagSet("eval-autocontext", flag.ContinueOnError)
+	suitePath := flags.String("suite", defaultAutoContextEvalSuite, "suite file listing the cases")
+	models := flags.String("models", "", "comma-separated provider:model pairs to evaluate, e.g. openai:gpt-4.1-mini; keys come from <PROVIDER>_API_KEY. Without models the cases' cassettes are replayed")
+	record := flags.Bool("record", false, "record the calls of the single model given into the cases' cassettes, replacing earlier recordings")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	suite, err := loadAutoContextEvalSuite(*suitePath)
+	if err != nil {
+		return err
+	}
+	var configs []provider.Config
+	for _, pair := range strings.Split(*models, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		name, model, ok := strings.Cut(pair, ":")
+		if !ok || name == "" || model == "" {
+			return fmt.Errorf("invalid model %q, expected provider:model", pair)
+		}
+		configs = append(configs, provider.Config{Provider: name, Model: model, APIKey: os.Getenv(strings.ToUpper(name) + "_API_KEY")})
+	}
+	if *record && len(configs) != 1 {
+		return errors.New("-record needs exactly one model")
+	}
+
+	ctx := context.Background()
+	service := NewAutoContextService()
+	baseDir := filepath.Dir(*suitePath)
+	failed := 0
+	run := func(label string, llmFor func(autoContextEvalCase) (provider.LLMProvider, error)) {
+		outcomes := make([]autoContextEvalOutcome, 0, len(suite.Cases))
+		for _, c := range suite.Cases {
+			outcome := evalAutoContextCase(ctx, service, baseDir, c, llmFor)
+			if outcome.Err != nil {
+				failed++
+			}
+			outcomes = append(outcomes, outcome)
+		}
+		printAutoContextEvalReport(os.Stdout, label, outcomes)
+	}
+
+	if len(configs) == 0 {
+		run("cassettes", replayAutoContextEval(baseDir))
+	}
+	for _, cfg := range configs {
+		llm, err := provider.Factory(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to configure %s: %w", cfg.Provider, err)
+		}
+		llmFor := func(autoContextEvalCase) (provider.LLMProvider, error) { return llm, nil }
+		if *record {
+			llmFor = func(c autoContextEvalCase) (provider.LLMProvider, error) {
+				recording := cfg
+				recording.Record, recording.CassetteDir = true, autoContextEvalCassette(baseDir, c)
+				if err := os.RemoveAll(recording.CassetteDir); err != nil {
+					return nil, fmt.Errorf("failed to clear cassette: %w", err)
+				}
+				return provider.Factory(recording)
+			}
+		}
+		run(cfg.Provider+":"+cfg.Model, llmFor)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d auto-context eval runs failed", failed)
+	}
+	return nil
+}
+
+func loadAutoContextEvalSuite(path string) (autoContextEvalSuite, error) {
+	var suite autoContextEvalSuite
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return suite, fmt.Errorf("failed to read eval suite: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&suite); err != nil {
+		return suite, fmt.Errorf("failed to parse eval suite %s: %w", path, err)
+	}
+	for i, c := range suite.Cases {
+		if c.Name == "" || c.Fixture == "" || strings.TrimSpace(c.Task) == "" || len(c.Expected) == 0 {
+			return suite, fmt.Errorf("eval case %d needs a name, a fixture, a task and expected files", i+1)
+		}
+		switch c.Mode {
+		case "", autoContextModeSingle, autoContextModeIterative, autoContextModeOffline:
+		default:
+			return suite, fmt.Errorf("eval case %s: unknown mode %q", c.Name, c.Mode)
+		}
+	}
+	return suite, nil
+}
+
+// evalAutoContextCase runs one case through the same selection path as
+// RequestAutoContextSelection and scores the resolved files.
+func evalAutoContextCase(ctx context.Context, service *AutoContextService, baseDir string, c autoContextEvalCase, llmFor func(autoContextEvalCase) (provider.LLMProvider, error)) autoContextEvalOutcome {
+	outcome := autoContextEvalOutcome{Case: c.Name, Mode: c.Mode}
+	if outcome.Mode == "" {
+		outcome.Mode = autoContextModeSingle
+	}
+	rootDir := filepath.Join(baseDir, filepath.FromSlash(c.Fixture))
+	opts := newAutoContextEvalApp().ruleWalkOptions(rootDir)
+	opts.OnError = nil // Logs through the Wails runtime, which the command does not run
+	treeText, tree, err := buildAutoContextTree(ctx, rootDir, opts)
+	hierarchical := errors.Is(err, errAutoContextTreeTooLarge)
+	if err != nil && !hierarchical {
+		outcome.Err = err
+		return outcome
+	}
+
+	var llm provider.LLMProvider
+	if outcome.Mode != autoContextModeOffline {
+		if llm, err = llmFor(c); err != nil {
+			outcome.Err = err
+			return outcome
+		}
+	}
+	result, err := service.selectAutoContext(ctx, llm, rootDir, tree, treeText, hierarchical, outcome.Mode, c.Task, defaultMaxFileSizeBytes, func(ex autoContextExchange) {
+		outcome.Calls++
+		outcome.Tokens += tokens.EstimateText(ex.Prompt) + tokens.EstimateText(ex.Response)
+	})
+	if err != nil {
+		outcome.Err = err
+		return outcome
+	}
+	selected, err := resolveLLMSelection(rootDir, tree, result.Files)
+	if err != nil {
+		outcome.Err = err
+		return outcome
+	}
+
+	expected := make(map[string]bool, len(c.Expected))
+	for _, p := range c.Expected {
+		expected[normalizeRelativePath(p)] = true
+	}
+	hits := 0
+	for _, f := range selected {
+		if expected[f.Path] {
+			hits++
+			delete(expected, f.Path)
+		} else {
+			outcome.Extra = append(outcome.Extra, f.Path)
+		}
+	}
+	for p := range expected {
+		outcome.Missed = append(outcome.Missed, p)
+	}
+	sort.Strings(outcome.Missed)
+	if len(selected) > 0 {
+		outcome.Precision = float64(hits) / float64(len(selected))
+	}
+	outcome.Recall = float64(hits) / float64(hits+len(outcome.Missed))
+	return outcome
+}
+
+// newAutoContextEvalApp holds the settings a fresh install walks projects with:
+// both ignore toggles on and the default custom rules.
+func newAutoContextEvalApp() *App {
+	a := &App{ctx: context.Background(), useGitignore: true, useCustomIgnore: true}
+	a.settings.CustomIgnoreRules = defaultCustomIgnoreRulesContent
+	a.currentCustomIgnorePatterns = ignore.ParseRules(customIgnoreRulesSource, "", a.settings.CustomIgnoreRules)
+	return a
+}
+
+func printAutoContextEvalReport(out io.Writer, label string, outcomes []autoContextEvalOutcome) {
+	fmt.Fprintf(out, "== %s ==\n", label)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CASE\tMODE\tPRECISION\tRECALL\tTOKENS\tCALLS\tNOTES")
+	var precision, recall float64
+	var total int64
+	scored := 0
+	for _, o := range outcomes {
+		total += o.Tokens
+		if o.Err != nil {
+			fmt.Fprintf(w, "%s\t%s\t-\t-\t%d\t%d\terror: %v\n", o.Case, o.Mode, o.Tokens, o.Calls, o.Err)
+			continue
+		}
+		scored++
+		precision += o.Precision
+		recall += o.Recall
+		var notes []string
+		if len(o.Missed) > 0 {
+			notes = append(notes, "missed "+strings.Join(o.Missed, ", "))
+		}
+		if len(o.Extra) > 0 {
+			notes = append(notes, "extra "+strings.Join(o.Extra, ", "))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%.2f\t%.2f\t%d\t%d\t%s\n", o.Case, o.Mode, o.Precision, o.Recall, o.Tokens, o.Calls, strings.Join(notes, "; "))
+	}
+	w.Flush()
+	if scored > 0 {
+		precision /= float64(scored)
+		recall /= float64(scored)
+	}
+	// Tokens are not priced: vendor prices change and differ per account.
+	fmt.Fprintf(out, "%d/%d cases scored, mean precision %.2f, mean recall %.2f, ~%d tokens\n\n", scored, len(outcomes), precision, recall, total)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+// Floors for the replayed suite; a change to prompts, parsing or walking that
+// lowers them needs new cassettes or a deliberate floor change.
+const (
+	minAutoContextEvalRecall    = 0.9
+	minAutoContextEvalPrecision = 0.6
+)
+
+func TestAutoContextEvalSuite(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // Keep the user's global git excludes out
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	suite, err := loadAutoContextEvalSuite(defaultAutoContextEvalSuite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := NewAutoContextService()
+	baseDir := filepath.Dir(defaultAutoContextEvalSuite)
+	var recall, precision float64
+	for _, c := range suite.Cases {
+		outcome := evalAutoContextCase(context.Background(), service, baseDir, c, replayAutoContextEval(baseDir))
+		if outcome.Err != nil {
+			t.Errorf("%s: %v", c.Name, outcome.Err)
+			continue
+		}
+		t.Logf("%s: precision %.2f, recall %.2f, missed %v, extra %v", c.Name, outcome.Precision, outcome.Recall, outcome.Missed, outcome.Extra)
+		recall += outcome.Recall
+		precision += outcome.Precision
+	}
+	n := float64(len(suite.Cases))
+	if recall/n < minAutoContextEvalRecall {
+		t.Errorf("mean recall %.2f is below %.2f", recall/n, minAutoContextEvalRecall)
+	}
+	if precision/n < minAutoContextEvalPrecision {
+		t.Errorf("mean precision %.2f is below %.2f", precision/n, minAutoContextEvalPrecision)
+	}
+}
+
+func TestNormalizeCandidateForRoot(t *testing.T) {
+	listed := map[string]bool{"notes/store.py": true, "README.md": true}
+	known := func(rel string) bool { return listed[rel] }
+	tests := []struct{ candidate, want string }{
+		{"notes/store.py", "notes/store.py"},       // Listed as given, although it starts with the root name
+		{"notes/notes/store.py", "notes/store.py"}, // Prefixed with the root name
+		{"./README.md", "README.md"},
+		{"notes", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeCandidateForRoot("/work/notes", tt.candidate, known); got != tt.want {
+			t.Errorf("normalizeCandidateForRoot(%q) = %q, want %q", tt.candidate, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"shotgun_code/internal/llm/provider"
+	"shotgun_code/internal/walker"
+)
+
+// maxAutoContextCalls bounds the model calls of one hierarchical auto-context run.
+const maxAutoContextCalls = 8
+
+func hierarchicalFormatInstructions(canExpand bool) string {
+	var b strings.Builder
+	b.WriteString("Respond ONLY with a JSON object that matches this schema:\n" +
+		"```\n{\n" + autoContextFilesSchemaText + "\n  \"expand\": [\"relative/path/of/collapsed/directory\"],\n  \"reasoning\": \"optional short description\"\n}\n```\n" +
+		autoContextFilesGuidance)
+	if canExpand {
+		b.WriteString("Collapsed directories are shown with their file count. To see inside one, list its path in \"expand\"; " +
+			"it will be shown in a later call. Put files, or directories that are relevant as a whole, in \"files\". " +
+			"Selections from all calls are merged, so only select what this view shows. Both arrays may be empty.\n")
+	} else {
+		b.WriteString("This is the last call: \"expand\" is ignored, select files or whole directories now.\n")
+	}
+	b.WriteString("No code fences, commentary, or explanations outside the JSON object.")
+	return b.String()
+}
+
+// BuildHierarchicalPrompt renders the prompt for one call of hierarchical auto-context.
+func (s *AutoContextService) BuildHierarchicalPrompt(view, userTask, understanding string, canExpand bool) (string, error) {
+	formatted, err := s.renderTemplate(view, userTask, understanding)
+	if err != nil {
+		return "", err
+	}
+	return formatted + "\n\n" + hierarchicalFormatInstructions(canExpand), nil
+}
+
+// autoContextPage is a directory to show in a hierarchical view, starting at the
+// child with index from; later pages continue a listing that was cut off.
+type autoContextPage struct {
+	dir  *walker.Entry
+	from int
+}
+
+// RunHierarchical selects files from a tree too large to show at once. The first
+// call sees the top levels with collapsed directories summarised by file count;
+// the model picks files and names directories to expand, which are shown in later
+// calls, up to maxAutoContextCalls. A directory with too many entries for one view
+// is continued in later views. The files of all calls are merged. It does not
+// touch the Wails runtime.
+func (s *AutoContextService) RunHierarchical(ctx context.Context, llm provider.LLMProvider, rootDir string, tree *walker.Entry, task string, record func(autoContextExchange)) (AutoContextResult, error) {
+	entries := make(map[string]*walker.Entry)
+	tree.Visit(func(entry *walker.Entry) error {
+		if entry.RelPath != "." {
+			entries[entry.SlashPath()] = entry
+		}
+		return nil
+	})
+	known := func(rel string) bool { return entries[rel] != nil }
+
+	queue := []autoContextPage{{dir: tree}}
+	queued := map[*walker.Entry]bool{tree: true}
+	var files []AutoContextFile
+	var picked, reasons []string
+	seenFiles := make(map[string]bool)
+
+	for call := 1; call <= maxAutoContextCalls && len(queue) > 0; call++ {
+		if err := ctx.Err(); err != nil {
+			return AutoContextResult{}, err
+		}
+		// Show as many queued directories as fit in one view.
+		var views []string
+		var shown []string
+		size := 0
+		for len(queue) > 0 {
+			page := queue[0]
+			view, next := renderAutoContextView(rootDir, page.dir, page.from)
+			if len(views) > 0 && size+len(view) > maxAutoContextTreeChars {
+				break
+			}
+			views = append(views, view)
+			shown = append(shown, displayPage(page))
+			size += len(view)
+			queue = queue[1:]
+			if next > 0 {
+				queue = append(queue, autoContextPage{dir: page.dir, from: next})
+			}
+		}
+
+		var notes strings.Builder
+		notes.WriteString("The project tree is too large to show at once, so it is explored one part at a time. ")
+		fmt.Fprintf(&notes, "This view shows: %s.", strings.Join(shown, ", "))
+		if len(picked) > 0 {
+			fmt.Fprintf(&notes, "\nAlready selected in earlier views: %s", strings.Join(picked, ", "))
+		}
+
+		canExpand := call < maxAutoContextCalls
+		prompt, err := s.BuildHierarchicalPrompt(strings.Join(views, "\n"), task, notes.String(), canExpand)
+		if err != nil {
+			return AutoContextResult{}, err
+		}
+		result, err := s.generateAutoContext(ctx, llm, prompt, autoContextSchema("expand"), func(ex autoContextExchange) {
+			if record != nil {
+				ex.Round, ex.MaxRounds = call, maxAutoContextCalls
+				record(ex)
+			}
+		})
+		if errors.Is(err, errAutoContextEmptySelection) {
+			continue // Nothing relevant in this part of the tree
+		}
+		if err != nil {
+			return AutoContextResult{}, fmt.Errorf("call %d: %w", call, err)
+		}
+
+		for _, f := range append(result.Files, autoContextFilesFromPaths(result.Inspect)...) {
+			f.Path = normalizeCandidateForRoot(rootDir, f.Path, known)
+			if f.Path != "" && !seenFiles[f.Path] {
+				seenFiles[f.Path] = true
+				files = append(files, f)
+				picked = append(picked, f.Path)
+			}
+		}
+		if result.Reasoning != "" {
+			reasons = append(reasons, result.Reasoning)
+		}
+		if !canExpand {
+			continue
+		}
+		for _, candidate := range result.Expand {
+			dir, ok := entries[normalizeCandidateForRoot(rootDir, candidate, known)]
+			if ok && dir.IsDir && !queued[dir] {
+				queued[dir] = true
+				queue = append(queue, autoContextPage{dir: dir})
+			}
+		}
+	}
+
+	if len(files) == 0 {
+		return AutoContextResult{}, errAutoContextEmptySelection
+	}
+	return AutoContextResult{Files: files, Reasoning: strings.Join(reasons, " ")}, nil
+}
+
+func displayPage(page autoContextPage) string {
+	name := page.dir.SlashPath() + "/"
+	if page.dir.RelPath == "." {
+		name = "the project root"
+	}
+	if page.from > 0 {
+		name += fmt.Sprintf(" (continued from entry %d)", page.from+1)
+	}
+	return name
+}
+
+// renderAutoContextView renders dir's subtree within maxAutoContextTreeChars, showing
+// as many levels as fit. Directories below the cut-off are collapsed to one line with
+// their file count. If even one level does not fit, or from is not 0, the children
+// from that index on are listed flat and cut with a note where the view is full;
+// next is then the index of the first child left for a later view, 0 when none is.
+func renderAutoContextView(rootDir string, dir *walker.Entry, from int) (view string, next int) {
+	header := filepath.Base(rootDir) + "/"
+	if dir.RelPath != "." {
+		header = dir.SlashPath() + "/"
+	}
+	if from > 0 {
+		return renderAutoContextPage(header+" (continued)", dir, from)
+	}
+	depth := 0
+	dir.Visit(func(entry *walker.Entry) error {
+		depth = max(depth, entry.Depth-dir.Depth)
+		return nil
+	})
+	for ; depth >= 1; depth-- {
+		var b strings.Builder
+		b.WriteString(header + "\n")
+		if renderCollapsed(&b, dir, "", depth, maxAutoContextTreeChars) {
+			return b.String(), 0
+		}
+	}
+	// A single level is too wide: list what fits.
+	return renderAutoContextPage(header, dir, 0)
+}
+
+// renderAutoContextPage lists dir's children from index from on, one line each,
+// until the view is full. Every page shows at least one child.
+func renderAutoContextPage(header string, dir *walker.Entry, from int) (view string, next int) {
+	var b strings.Builder
+	b.WriteString(header + "\n")
+	for i := from; i < len(dir.Children); i++ {
+		line := "├── " + collapsedName(dir.Children[i], true) + "\n"
+		if i > from && b.Len()+len(line) > maxAutoContextTreeChars-100 {
+			fmt.Fprintf(&b, "└── … %d more entries, shown in a later view\n", len(dir.Children)-i)
+			return b.String(), i
+		}
+		b.WriteString(line)
+	}
+	return b.String(), 0
+}
+
+// renderCollapsed writes the tree below dir down to levels deep. It reports false
+// as soon as the output would exceed limit bytes.
+func renderCollapsed(b *strings.Builder, dir *walker.Entry, prefix string, levels, limit int) bool {
+	for i, entry := range dir.Children {
+		branch, nextPrefix := "├── ", prefix+"│   "
+		if i == len(dir.Children)-1 {
+			branch, nextPrefix = "└── ", prefix+"    "
+		}
+		collapsed := entry.IsDir && levels == 1
+		b.WriteString(prefix + branch + collapsedName(entry, collapsed) + "\n")
+		if b.Len() > limit {
+			return false
+		}
+		if entry.IsDir && !collapsed && !renderCollapsed(b, entry, nextPrefix, levels-1, limit) {
+			return false
+		}
+	}
+	return true
+}
+
+func collapsedName(entry *walker.Entry, collapsed bool) string {
+	if !entry.IsDir {
+		return entry.Name
+	}
+	if !collapsed || len(entry.Children) == 0 {
+		return entry.Name + "/"
+	}
+	count := 0
+	entry.Visit(func(e *walker.Entry) error {
+		if !e.IsDir {
+			count++
+		}
+		return nil
+	})
+	return fmt.Sprintf("%s/ (collapsed, %d files)", entry.Name, count)
+}
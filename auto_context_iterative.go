@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"shotgun_code/internal/llm/provider"
+	"shotgun_code/internal/symbols"
+	"shotgun_code/internal/walker"
+)
+
+// Auto-context modes.
+const (
+	autoContextModeSingle    = "single"    // One call choosing from the tree
+	autoContextModeIterative = "iterative" // Tree plus symbols; the model may inspect files over several rounds
+	autoContextModeOffline   = "offline"   // BM25 ranking of the files against the task, no model
+)
+
+const (
+	maxAutoContextRounds           = 3
+	maxAutoContextSymbolChars      = 20_000
+	maxAutoContextInspectChars     = 60_000 // All inspected file contents together
+	maxAutoContextInspectFileChars = 20_000
+)
+
+// autoContextExchange is one model call of an auto-context run, reported so the
+// caller can log it to the prompt history.
+type autoContextExchange struct {
+	Round     int
+	MaxRounds int
+	Prompt    string
+	Response  string
+	APICall   string
+	Err       error
+	Repair    bool // A retry after a reply that did not parse
+}
+
+func iterativeFormatInstructions(round, maxRounds int) string {
+	var b strings.Builder
+	b.WriteString("Respond ONLY with a JSON object that matches this schema:\n" +
+		"```\n{\n  \"inspect\": [\"relative/path/to/read/first\"],\n" + autoContextFilesSchemaText + "\n  \"reasoning\": \"optional short description\"\n}\n```\n" +
+		autoContextFilesGuidance)
+	fmt.Fprintf(&b, "This is round %d of %d. ", round, maxRounds)
+	if round < maxRounds {
+		b.WriteString("The repo notes list the exported symbols of each file. If names and symbols are not enough to decide, " +
+			"put up to 10 files you need to read in \"inspect\" and leave \"files\" empty; their contents will be added to the repo notes " +
+			"in the next round. Once you are confident, leave \"inspect\" empty and return the final \"files\".\n")
+	} else {
+		b.WriteString("This is the final round: return the final \"files\" now and leave \"inspect\" empty.\n")
+	}
+	b.WriteString("No code fences, commentary, or explanations outside the JSON object.")
+	return b.String()
+}
+
+// RunIterative selects files in up to maxAutoContextRounds model calls. The first
+// round sees the tree and the exported symbols of every file in it; in each later
+// round the files the model asked to inspect are added to CURRENT_UNDERSTANDING.
+// Only files present in tree can be inspected. It does not touch the Wails runtime.
+func (s *AutoContextService) RunIterative(ctx context.Context, llm provider.LLMProvider, rootDir string, tree *walker.Entry, treeText, task string, sizeLimit int64, record func(autoContextExchange)) (AutoContextResult, error) {
+	available := make(map[string]string) // Slash path -> absolute path
+	var files []symbolFile
+	tree.Visit(func(entry *walker.Entry) error {
+		if entry.HasContent() {
+			available[entry.SlashPath()] = entry.Path
+			if !entry.Marked(walker.FilterSize) {
+				files = append(files, symbolFile{relPath: entry.SlashPath(), path: entry.Path})
+			}
+		}
+		return nil
+	})
+
+	var cache symbolCache
+	symbolMap, err := writeSymbolMap(ctx, files, func(f symbolFile) []symbols.Symbol {
+		return cache.extract(f.path, sizeLimit)
+	}, maxAutoContextSymbolChars, nil)
+	if err != nil {
+		return AutoContextResult{}, fmt.Errorf("failed to build symbol summaries: %w", err)
+	}
+	var notes strings.Builder
+	if symbolMap != "" {
+		notes.WriteString("Exported symbols per file (line, kind, name):\n" + symbolMap)
+	}
+
+	inspected := make(map[string]bool)
+	inspectedChars := 0
+	for round := 1; round <= maxAutoContextRounds; round++ {
+		prompt, err := s.BuildIterativePrompt(treeText, task, notes.String(), round, maxAutoContextRounds)
+		if err != nil {
+			return AutoContextResult{}, err
+		}
+		result, err := s.generateAutoContext(ctx, llm, prompt, autoContextSchema("inspect"), func(ex autoContextExchange) {
+			if record != nil {
+				ex.Round, ex.MaxRounds = round, maxAutoContextRounds
+				record(ex)
+			}
+		})
+		if err != nil {
+			return AutoContextResult{}, fmt.Errorf("round %d: %w", round, err)
+		}
+		if len(result.Inspect) == 0 || round == maxAutoContextRounds {
+			if len(result.Files) == 0 {
+				// Out of rounds while still inspecting: the inspected files are the best guess.
+				result.Files = autoContextFilesFromPaths(result.Inspect)
+			}
+			result.Inspect = nil
+			return result, nil
+		}
+
+		var missing, overBudget []string
+		for _, candidate := range result.Inspect {
+			rel := normalizeCandidateForRoot(rootDir, candidate, func(rel string) bool { return available[rel] != "" })
+			if inspected[rel] {
+				continue
+			}
+			absPath, ok := available[rel]
+			if !ok {
+				missing = append(missing, candidate)
+				continue
+			}
+			content, truncated, err := readFileLimited(absPath, maxAutoContextInspectFileChars)
+			if err != nil || walker.IsBinary(content) {
+				missing = append(missing, candidate)
+				continue
+			}
+			if inspectedChars+len(content) > maxAutoContextInspectChars {
+				overBudget = append(overBudget, rel)
+				continue
+			}
+			inspected[rel] = true
+			inspectedChars += len(content)
+			fmt.Fprintf(&notes, "\n<file path=\"%s\">\n%s\n</file>\n", rel, content)
+			if truncated {
+				fmt.Fprintf(&notes, "(%s was cut after %d bytes)\n", rel, maxAutoContextInspectFileChars)
+			}
+		}
+		if len(missing) > 0 {
+			fmt.Fprintf(&notes, "\nNot available for inspection (not in the tree, ignored or binary): %s\n", strings.Join(missing, ", "))
+		}
+		if len(overBudget) > 0 {
+			fmt.Fprintf(&notes, "\nNot inspected, the inspection budget is used up: %s\n", strings.Join(overBudget, ", "))
+		}
+	}
+	return AutoContextResult{}, fmt.Errorf("no selection after %d rounds", maxAutoContextRounds)
+}
+
+// autoContextMode returns the configured auto-context mode, defaulting to a single call.
+func (a *App) autoContextMode() string {
+	switch a.settings.AutoContextMode {
+	case autoContextModeIterative, autoContextModeOffline:
+		return a.settings.AutoContextMode
+	}
+	return autoContextModeSingle
+}
+
+// GetAutoContextMode returns "single", "iterative" or "offline".
+func (a *App) GetAutoContextMode() string {
+	return a.autoContextMode()
+}
+
+// SetAutoContextMode switches auto-context between a single call, iterative rounds
+// in which the model can inspect files before choosing, and offline ranking without
+// a model. Offline ranking is also used whenever no provider is configured.
+func (a *App) SetAutoContextMode(mode string) error {
+	switch mode {
+	case autoContextModeSingle, autoContextModeIterative, autoContextModeOffline:
+	default:
+		return fmt.Errorf("unknown auto-context mode %q (expected %q, %q or %q)", mode, autoContextModeSingle, autoContextModeIterative, autoContextModeOffline)
+	}
+	a.settings.AutoContextMode = mode
+	runtime.LogInfof(a.ctx, "App setting autoContextMode changed to: %s", mode)
+	return a.saveSettings()
+}
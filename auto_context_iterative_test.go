@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"shotgun_code/internal/llm/provider"
+	"shotgun_code/internal/walker"
+)
+
+// scriptedLLM answers Generate calls with its replies in order and keeps the prompts.
+type scriptedLLM struct {
+	replies []string
+	prompts []string
+}
+
+func (s *scriptedLLM) ListModels(context.Context) ([]provider.ModelInfo, error) { return nil, nil }
+
+func (s *scriptedLLM) Generate(_ context.Context, prompt string) (string, string, error) {
+	s.prompts = append(s.prompts, prompt)
+	if len(s.prompts) > len(s.replies) {
+		return "", "", fmt.Errorf("unexpected call %d", len(s.prompts))
+	}
+	return s.replies[len(s.prompts)-1], fmt.Sprintf("call %d", len(s.prompts)), nil
+}
+
+func (s *scriptedLLM) Chat(context.Context, []provider.Message) (string, string, error) {
+	return "", "", fmt.Errorf("scriptedLLM does not chat")
+}
+
+// iterativeReply is a reply asking to inspect paths and choosing files.
+func iterativeReply(inspect, files []string) string {
+	quote := func(paths []string) string {
+		quoted := make([]string, len(paths))
+		for i, p := range paths {
+			quoted[i] = fmt.Sprintf("%q", p)
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	}
+	return fmt.Sprintf(`{"inspect": %s, "files": %s, "reasoning": ""}`, quote(inspect), quote(files))
+}
+
+func runIterative(t *testing.T, files map[string]string, replies ...string) (AutoContextResult, *scriptedLLM, []autoContextExchange) {
+	t.Helper()
+	root := writeProject(t, files)
+	tree, err := walker.Walk(context.Background(), root, walker.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	llm := &scriptedLLM{replies: replies}
+	var exchanges []autoContextExchange
+	result, err := NewAutoContextService().RunIterative(context.Background(), llm, root, tree, "TREE", "Fix Run", defaultMaxFileSizeBytes,
+		func(ex autoContextExchange) { exchanges = append(exchanges, ex) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result, llm, exchanges
+}
+
+func resultFilePaths(result AutoContextResult) []string {
+	var paths []string
+	for _, f := range result.Files {
+		paths = append(paths, f.Path)
+	}
+	return paths
+}
+
+func inspectedBlock(rel string) string {
+	return fmt.Sprintf("<file path=%q>", rel)
+}
+
+func TestRunIterativeInspectsFiles(t *testing.T) {
+	result, llm, exchanges := runIterative(t, map[string]string{
+		"src/app.go":      "package app\n\nfunc Run() {}\n",
+		"src/util.go":     "package app\n\nfunc Helper() {}\n",
+		"assets/logo.png": "\x89PNG\x00\x00\x00",
+	},
+		iterativeReply([]string{"src/app.go", "missing.go", "assets/logo.png"}, nil),
+		iterativeReply(nil, []string{"src/app.go"}),
+	)
+	if got := resultFilePaths(result); !slices.Equal(got, []string{"src/app.go"}) {
+		t.Errorf("files = %v, want [src/app.go]", got)
+	}
+	if len(llm.prompts) != 2 {
+		t.Fatalf("made %d calls, want 2", len(llm.prompts))
+	}
+	first, second := llm.prompts[0], llm.prompts[1]
+	if !strings.Contains(first, "Run") || strings.Contains(first, inspectedBlock("src/app.go")) {
+		t.Error("round 1 should list symbols but no file contents")
+	}
+	if !strings.Contains(second, inspectedBlock("src/app.go")+"\npackage app\n\nfunc Run() {}\n") {
+		t.Errorf("round 2 lacks the contents of src/app.go:\n%s", second)
+	}
+	if !strings.Contains(second, "Not available for inspection (not in the tree, ignored or binary): missing.go, assets/logo.png\n") {
+		t.Errorf("round 2 lacks the note on missing and binary files:\n%s", second)
+	}
+	for i, ex := range exchanges {
+		if ex.Round != i+1 || ex.MaxRounds != maxAutoContextRounds || ex.Prompt != llm.prompts[i] {
+			t.Errorf("exchange %d = round %d of %d, want round %d of %d with its prompt", i, ex.Round, ex.MaxRounds, i+1, maxAutoContextRounds)
+		}
+	}
+}
+
+func TestRunIterativeRoundCap(t *testing.T) {
+	result, llm, _ := runIterative(t, map[string]string{
+		"src/app.go":  "package app\n\nfunc Run() {}\n",
+		"src/util.go": "package app\n\nfunc Helper() {}\n",
+	},
+		iterativeReply([]string{"src/app.go"}, nil),
+		// Asking for src/app.go again adds nothing.
+		iterativeReply([]string{"src/app.go", "src/util.go"}, nil),
+		iterativeReply([]string{"src/util.go"}, nil),
+	)
+	if len(llm.prompts) != maxAutoContextRounds {
+		t.Fatalf("made %d calls, want %d", len(llm.prompts), maxAutoContextRounds)
+	}
+	// The last round still inspects: its inspect list becomes the selection.
+	if got := resultFilePaths(result); !slices.Equal(got, []string{"src/util.go"}) || result.Inspect != nil {
+		t.Errorf("files = %v inspect = %v, want [src/util.go] and no inspect list", got, result.Inspect)
+	}
+	last := llm.prompts[2]
+	if n := strings.Count(last, inspectedBlock("src/app.go")); n != 1 {
+		t.Errorf("src/app.go inspected %d times, want once", n)
+	}
+	if !strings.Contains(last, inspectedBlock("src/util.go")) {
+		t.Error("round 3 lacks the contents of src/util.go")
+	}
+	if strings.Contains(last, "Not available") || strings.Contains(last, "Not inspected") {
+		t.Errorf("re-inspecting a file should not be reported:\n%s", last)
+	}
+	if !strings.Contains(last, "This is the final round") {
+		t.Error("round 3 does not say it is the final round")
+	}
+}
+
+func TestRunIterativeInspectBudget(t *testing.T) {
+	fill := strings.Repeat("x", maxAutoContextInspectFileChars-1)
+	_, llm, _ := runIterative(t, map[string]string{
+		"data/a.txt":    fill,
+		"data/b.txt":    fill,
+		"data/huge.txt": fill + strings.Repeat("y", 5_000),
+		"data/d.txt":    fill,
+	},
+		iterativeReply([]string{"data/a.txt", "data/b.txt", "data/huge.txt", "data/d.txt"}, nil),
+		iterativeReply(nil, []string{"data/a.txt"}),
+	)
+	second := llm.prompts[1]
+	for _, rel := range []string{"data/a.txt", "data/b.txt", "data/huge.txt"} {
+		if !strings.Contains(second, inspectedBlock(rel)) {
+			t.Errorf("round 2 lacks the contents of %s", rel)
+		}
+	}
+	if strings.Contains(second, "yyyyy") {
+		t.Error("data/huge.txt was not cut at the per-file limit")
+	}
+	if want := fmt.Sprintf("(data/huge.txt was cut after %d bytes)", maxAutoContextInspectFileChars); !strings.Contains(second, want) {
+		t.Errorf("round 2 lacks %q", want)
+	}
+	if strings.Contains(second, inspectedBlock("data/d.txt")) ||
+		!strings.Contains(second, "Not inspected, the inspection budget is used up: data/d.txt\n") {
+		t.Error("data/d.txt should be reported over the inspection budget")
+	}
+}
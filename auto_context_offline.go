@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"shotgun_code/internal/lexical"
+	"shotgun_code/internal/walker"
+)
+
+const (
+	maxOfflineAutoContextFiles = 15
+	// offlineMinRelativeScore drops files scoring below this fraction of the best
+	// file, so a task matching one area does not pull in every file sharing a word.
+	offlineMinRelativeScore = 0.3
+	// offlineEditTargetScore is the relative score from which a file is presented
+	// as an edit target rather than a reference.
+	offlineEditTargetScore = 0.6
+)
+
+var testFilePattern = regexp.MustCompile(`(^|/)(tests?|__tests__|spec)/|_test\.[a-z]+$|\.(test|spec)\.[a-z]+$|(^|/)test_[^/]*\.py$`)
+
+// RunOffline selects files without a model: the files in tree are ranked against
+// task with BM25 over identifier and path terms. Scores are relative to the best
+// file. It does not touch the Wails runtime.
+func (s *AutoContextService) RunOffline(ctx context.Context, tree *walker.Entry, task string, sizeLimit int64) (AutoContextResult, error) {
+	if strings.TrimSpace(task) == "" {
+		return AutoContextResult{}, errors.New("offline auto-context needs a task description to rank files against")
+	}
+	var docs []lexical.Document
+	err := tree.Visit(func(entry *walker.Entry) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !entry.HasContent() || entry.Marked(walker.FilterSize) {
+			return nil
+		}
+		content, _, err := readFileLimited(entry.Path, sizeLimit)
+		if err != nil || walker.IsBinary(content) {
+			return nil
+		}
+		docs = append(docs, lexical.Document{Path: entry.SlashPath(), Content: string(content)})
+		return nil
+	})
+	if err != nil {
+		return AutoContextResult{}, err
+	}
+
+	ranked := lexical.NewIndex(docs).Search(task, maxOfflineAutoContextFiles)
+	if len(ranked) == 0 {
+		return AutoContextResult{}, errAutoContextEmptySelection
+	}
+	best := ranked[0].Score
+	var result AutoContextResult
+	for _, r := range ranked {
+		score := r.Score / best
+		if score < offlineMinRelativeScore {
+			break
+		}
+		role := autoContextRoleReference
+		switch {
+		case testFilePattern.MatchString(r.Path):
+			role = autoContextRoleTest
+		case score >= offlineEditTargetScore:
+			role = autoContextRoleEditTarget
+		}
+		result.Files = append(result.Files, AutoContextFile{
+			Path:   r.Path,
+			Score:  &score,
+			Reason: "matches " + joinTerms(r.Matched, 4),
+			Role:   role,
+		})
+	}
+	result.Reasoning = fmt.Sprintf("Offline BM25 ranking of %d files against the task terms; no model was used.", len(docs))
+	return result, nil
+}
+
+// joinTerms lists up to n terms, quoted, and how many more there are.
+func joinTerms(terms []string, n int) string {
+	quoted := make([]string, 0, min(len(terms), n))
+	for _, term := range terms[:min(len(terms), n)] {
+		quoted = append(quoted, fmt.Sprintf("%q", term))
+	}
+	text := strings.Join(quoted, ", ")
+	if len(terms) > n {
+		text += fmt.Sprintf(" and %d more", len(terms)-n)
+	}
+	return text
+}
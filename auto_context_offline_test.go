@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"shotgun_code/internal/walker"
+)
+
+func runOffline(t *testing.T, files map[string]string, task string) (AutoContextResult, error) {
+	t.Helper()
+	root := writeProject(t, files)
+	tree, err := walker.Walk(context.Background(), root, walker.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewAutoContextService().RunOffline(context.Background(), tree, task, defaultMaxFileSizeBytes)
+}
+
+func TestRunOfflineCutoffAndRoles(t *testing.T) {
+	result, err := runOffline(t, map[string]string{
+		"billing/invoice.go":      "invoice total tax invoice lines",
+		"billing/invoice_test.go": "invoice total",
+		"billing/ledger.go":       "ledger entries for each invoice total",
+		"billing/report.go":       "report the total " + strings.Repeat("lorem ipsum ", 4),
+		// Mentions the task once in a long file: below the relative cutoff.
+		"docs/changelog.md": "invoice " + strings.Repeat("lorem ipsum dolor amet ", 30),
+		"ui/theme.css":      "colors",
+	}, "Fix the invoice total")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		path, role, reason string
+	}{
+		{"billing/invoice.go", autoContextRoleEditTarget, `matches "invoice", "total"`},
+		{"billing/invoice_test.go", autoContextRoleTest, `matches "invoice", "total"`},
+		{"billing/ledger.go", autoContextRoleEditTarget, `matches "invoice", "total"`},
+		{"billing/report.go", autoContextRoleReference, `matches "total"`},
+	}
+	if len(result.Files) != len(want) {
+		t.Fatalf("got %d files %+v, want %d", len(result.Files), result.Files, len(want))
+	}
+	for i, w := range want {
+		f := result.Files[i]
+		if f.Path != w.path || f.Role != w.role || f.Reason != w.reason {
+			t.Errorf("file %d = %s %s %q, want %s %s %q", i, f.Path, f.Role, f.Reason, w.path, w.role, w.reason)
+		}
+		if f.Score == nil || *f.Score < offlineMinRelativeScore || *f.Score > 1 {
+			t.Errorf("%s: score %v outside [%v, 1]", f.Path, f.Score, offlineMinRelativeScore)
+		}
+	}
+	if *result.Files[0].Score != 1 {
+		t.Errorf("best score = %v, want 1", *result.Files[0].Score)
+	}
+	if !strings.Contains(result.Reasoning, "6 files") {
+		t.Errorf("reasoning %q does not count the 6 ranked files", result.Reasoning)
+	}
+}
+
+func TestRunOfflineCapsFiles(t *testing.T) {
+	files := make(map[string]string)
+	for i := range maxOfflineAutoContextFiles + 5 {
+		files[fmt.Sprintf("widgets/w%02d.go", i)] = "widget"
+	}
+	result, err := runOffline(t, files, "widget")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Files) != maxOfflineAutoContextFiles {
+		t.Fatalf("got %d files, want the cap of %d", len(result.Files), maxOfflineAutoContextFiles)
+	}
+	// Equal scores fall back to path order.
+	if first, last := result.Files[0].Path, result.Files[len(result.Files)-1].Path; first != "widgets/w00.go" || last != "widgets/w14.go" {
+		t.Errorf("files run from %s to %s, want widgets/w00.go to widgets/w14.go", first, last)
+	}
+}
+
+func TestRunOfflineErrors(t *testing.T) {
+	files := map[string]string{"main.go": "package main"}
+	if _, err := runOffline(t, files, "  "); err == nil {
+		t.Error("blank task: want an error")
+	}
+	if _, err := runOffline(t, files, "unrelated words"); !errors.Is(err, errAutoContextEmptySelection) {
+		t.Errorf("no match: err = %v, want errAutoContextEmptySelection", err)
+	}
+}
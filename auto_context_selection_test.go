@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"shotgun_code/internal/ignore"
+	"shotgun_code/internal/walker"
+)
+
+func TestResolveLLMSelectionUsesWalkedTree(t *testing.T) {
+	root := writeProject(t, map[string]string{
+		"src/app.go":      "package src\n",
+		"src/util.go":     "package src\n",
+		"src/vendor/x.go": "package vendor\n",
+		"src/skip.go":     "package src\n",
+		"docs/guide.md":   "# guide\n",
+		"vendor/lib/l.go": "package lib\n",
+		"assets/logo.png": "\x89PNG\x00",
+	})
+	a := &App{ctx: context.Background(), useCustomIgnore: true}
+	a.currentCustomIgnorePatterns = ignore.ParseRules(customIgnoreRulesSource, "", "vendor/\n")
+	tree, err := walker.Walk(context.Background(), root, a.selectionWalkOptions([]string{filepath.FromSlash("src/skip.go")}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	score := 0.5
+	selected, err := resolveLLMSelection(root, tree, []AutoContextFile{
+		{Path: "src", Score: &score},
+		{Path: "vendor/lib/l.go"},
+		{Path: "vendor"},
+		{Path: "src/skip.go"},
+		{Path: "missing.go"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range selected {
+		got = append(got, f.Path)
+	}
+	if want := []string{"src/app.go", "src/util.go"}; !slices.Equal(got, want) {
+		t.Errorf("selection = %v, want %v", got, want)
+	}
+}
+
+func TestHierarchicalViewPagesWideDirectories(t *testing.T) {
+	dir := &walker.Entry{Name: "wide", RelPath: "wide", IsDir: true, Depth: 1}
+	for i := range 3000 {
+		name := fmt.Sprintf("file_with_a_fairly_long_name_%04d.go", i)
+		dir.Children = append(dir.Children, &walker.Entry{Name: name, RelPath: filepath.Join("wide", name), Depth: 2})
+	}
+
+	var listed []string
+	from, pages := 0, 0
+	for {
+		view, next := renderAutoContextView("/project", dir, from)
+		pages++
+		if len(view) > maxAutoContextTreeChars {
+			t.Fatalf("page %d is %d chars", pages, len(view))
+		}
+		for _, line := range strings.Split(view, "\n") {
+			if name, ok := strings.CutPrefix(line, "├── "); ok {
+				listed = append(listed, name)
+			}
+		}
+		if next == 0 {
+			break
+		}
+		if !strings.Contains(view, "shown in a later view") {
+			t.Fatalf("page %d is cut without a note", pages)
+		}
+		from = next
+	}
+	if pages < 2 || len(listed) != len(dir.Children) {
+		t.Fatalf("%d pages listed %d of %d entries", pages, len(listed), len(dir.Children))
+	}
+	for i, name := range listed {
+		if name != dir.Children[i].Name {
+			t.Fatalf("entry %d = %s, want %s", i, name, dir.Children[i].Name)
+		}
+	}
+}
+
+func TestParseResponseFileEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "extra keys on a file entry",
+			reply: `{"files": [{"path": "a.go", "score": 0.9, "confidence": "high", "reason": "r", "role": "edit target"}], "reasoning": ""}`,
+			want:  []string{"a.go"},
+		},
+		{
+			name:  "bare paths and objects",
+			reply: `{"files": ["a.go", {"path": "./b.go"}]}`,
+			want:  []string{"a.go", "b.go"},
+		},
+		{
+			name:    "unknown top-level key",
+			reply:   `{"files": ["a.go"], "notes": "extra"}`,
+			wantErr: true,
+		},
+		{
+			name:    "entry of the wrong type",
+			reply:   `{"files": [42]}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		result, err := NewAutoContextService().ParseResponse(tt.reply)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got := resultFilePaths(result); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: files = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
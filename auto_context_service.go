@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"embed"
 	"encoding/json"
 	"errors"
@@ -15,6 +16,7 @@ import (
 	"github.com/tmc/langchaingo/schema"
 
 	"shotgun_code/internal/llm/provider"
+	"shotgun_code/internal/walker"
 )
 
 //go:embed design/prompts/contextPreparation.md
@@ -25,13 +27,65 @@ const (
 	maxAutoContextTreeChars = 15_000
 )
 
-var errAutoContextTreeTooLarge = errors.New("auto context file tree exceeds the allowed size")
+var (
+	errAutoContextTreeTooLarge   = errors.New("auto context file tree exceeds the allowed size")
+	errAutoContextEmptySelection = errors.New("response did not include any valid files")
+)
 
 type autoContextParser struct{}
 
+// autoContextFilesSchemaText and autoContextFilesGuidance describe the "files"
+// entry in the format instructions of every auto-context mode.
+const (
+	autoContextFilesSchemaText = `  "files": [{"path": "relative/path/from/project/root", "score": 0.9, "reason": "one line on why it is needed", "role": "edit target"}],`
+	autoContextFilesGuidance   = "For each file give \"score\" (0 to 1, how sure you are it is needed), a one-line \"reason\" and a \"role\": " +
+		"\"edit target\" (likely to change), \"reference\" (needed to understand the change) or \"test\" (tests to update or run).\n"
+)
+
 type AutoContextResult struct {
-	Files     []string `json:"files"`
-	Reasoning string   `json:"reasoning,omitempty"`
+	Files     []AutoContextFile `json:"files"`
+	Inspect   []string          `json:"inspect,omitempty"` // Iterative mode: files to read before deciding
+	Expand    []string          `json:"expand,omitempty"`  // Hierarchical mode: collapsed directories to show next
+	Reasoning string            `json:"reasoning,omitempty"`
+}
+
+// Roles of a file picked by auto-context.
+const (
+	autoContextRoleEditTarget = "edit target" // Likely to change
+	autoContextRoleReference  = "reference"   // Needed to understand the change
+	autoContextRoleTest       = "test"        // Tests to update or run
+)
+
+// AutoContextFile is one path picked by auto-context. Score (0 to 1) is the model's
+// confidence that the file is needed; it is nil when the model gave a bare path.
+type AutoContextFile struct {
+	Path   string   `json:"path"`
+	Score  *float64 `json:"score,omitempty"`
+	Reason string   `json:"reason,omitempty"`
+	Role   string   `json:"role,omitempty"`
+}
+
+// UnmarshalJSON accepts either a pick object or a bare path string. Keys a pick
+// object has beyond the schema, e.g. a "confidence" some models add, are ignored.
+func (f *AutoContextFile) UnmarshalJSON(data []byte) error {
+	var path string
+	if err := json.Unmarshal(data, &path); err == nil {
+		*f = AutoContextFile{Path: path}
+		return nil
+	}
+	type plain AutoContextFile
+	var decoded plain
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*f = AutoContextFile(decoded)
+	return nil
+}
+
+// AutoContextSelection is what RequestAutoContextSelection returns to the frontend.
+type AutoContextSelection struct {
+	Files     []AutoContextFile `json:"files"`
+	Reasoning string            `json:"reasoning,omitempty"`
 }
 
 func (autoContextParser) Parse(text string) (AutoContextResult, error) {
@@ -44,7 +98,8 @@ func (autoContextParser) ParseWithPrompt(text string, _ schema.PromptValue) (Aut
 
 func (autoContextParser) GetFormatInstructions() string {
 	return "Respond ONLY with a JSON object that matches this schema:\n" +
-		"```\n{\n  \"files\": [\"relative/path/from/project/root\"],\n  \"reasoning\": \"optional short description\"\n}\n```\n" +
+		"```\n{\n" + autoContextFilesSchemaText + "\n  \"reasoning\": \"optional short description\"\n}\n```\n" +
+		autoContextFilesGuidance +
 		"No code fences, commentary, or explanations outside the JSON object."
 }
 
@@ -90,6 +145,24 @@ func (s *AutoContextService) ensureTemplate() error {
 }
 
 func (s *AutoContextService) BuildPrompt(fileTree, userTask, understanding string) (string, error) {
+	formatted, err := s.renderTemplate(fileTree, userTask, understanding)
+	if err != nil {
+		return "", err
+	}
+	return formatted + "\n\n" + s.parser.GetFormatInstructions(), nil
+}
+
+// BuildIterativePrompt renders the prompt for one round of iterative auto-context,
+// where the model may ask to inspect files before committing to a selection.
+func (s *AutoContextService) BuildIterativePrompt(fileTree, userTask, understanding string, round, maxRounds int) (string, error) {
+	formatted, err := s.renderTemplate(fileTree, userTask, understanding)
+	if err != nil {
+		return "", err
+	}
+	return formatted + "\n\n" + iterativeFormatInstructions(round, maxRounds), nil
+}
+
+func (s *AutoContextService) renderTemplate(fileTree, userTask, understanding string) (string, error) {
 	if err := s.ensureTemplate(); err != nil {
 		return "", err
 	}
@@ -102,8 +175,7 @@ func (s *AutoContextService) BuildPrompt(fileTree, userTask, understanding strin
 	if err != nil {
 		return "", fmt.Errorf("failed to render auto-context prompt: %w", err)
 	}
-
-	return strings.TrimSpace(formatted) + "\n\n" + s.parser.GetFormatInstructions(), nil
+	return strings.TrimSpace(formatted), nil
 }
 
 func (s *AutoContextService) ParseResponse(text string) (AutoContextResult, error) {
@@ -134,77 +206,128 @@ func parseAutoContextJSON(text string) (AutoContextResult, error) {
 		return AutoContextResult{}, fmt.Errorf("failed to decode auto-context response: %w", err)
 	}
 
-	normalized := make([]string, 0, len(result.Files))
-	for _, f := range result.Files {
+	result.Files = normalizeAutoContextFiles(result.Files)
+	result.Inspect = normalizeRelativePaths(result.Inspect)
+	result.Expand = normalizeRelativePaths(result.Expand)
+	if len(result.Files) == 0 && len(result.Inspect) == 0 && len(result.Expand) == 0 {
+		return AutoContextResult{}, errAutoContextEmptySelection
+	}
+	return result, nil
+}
+
+// normalizeAutoContextFiles normalizes paths, clamps scores to [0, 1] and maps
+// roles onto the known ones. Picks without a path are dropped.
+func normalizeAutoContextFiles(files []AutoContextFile) []AutoContextFile {
+	normalized := make([]AutoContextFile, 0, len(files))
+	for _, f := range files {
+		f.Path = normalizeRelativePath(f.Path)
+		if f.Path == "" {
+			continue
+		}
+		if f.Score != nil {
+			score := min(max(*f.Score, 0), 1)
+			f.Score = &score
+		}
+		f.Reason = strings.TrimSpace(f.Reason)
+		f.Role = normalizeAutoContextRole(f.Role)
+		normalized = append(normalized, f)
+	}
+	return normalized
+}
+
+func normalizeAutoContextRole(role string) string {
+	switch strings.ToLower(strings.NewReplacer("_", " ", "-", " ").Replace(strings.TrimSpace(role))) {
+	case "":
+		return ""
+	case "edit target", "edit", "target", "edit targets":
+		return autoContextRoleEditTarget
+	case "test", "tests":
+		return autoContextRoleTest
+	default:
+		return autoContextRoleReference
+	}
+}
+
+// autoContextFilesFromPaths turns bare paths into unscored picks.
+func autoContextFilesFromPaths(paths []string) []AutoContextFile {
+	files := make([]AutoContextFile, 0, len(paths))
+	for _, p := range paths {
+		files = append(files, AutoContextFile{Path: p})
+	}
+	return files
+}
+
+func normalizeRelativePaths(paths []string) []string {
+	normalized := make([]string, 0, len(paths))
+	for _, f := range paths {
 		f = normalizeRelativePath(f)
 		if f != "" {
 			normalized = append(normalized, f)
 		}
 	}
-	if len(normalized) == 0 {
-		return AutoContextResult{}, errors.New("response did not include any valid files")
-	}
-	result.Files = normalized
-	return result, nil
+	return normalized
 }
 
-func buildAutoContextTree(rootDir string, excludedMap map[string]bool) (string, error) {
+// buildAutoContextTree renders the ASCII tree the model chooses from. It walks the
+// project with the same options as context generation so both see the same files,
+// and returns the walked tree alongside for callers that need the entries. When the
+// rendering exceeds maxAutoContextTreeChars it returns errAutoContextTreeTooLarge
+// together with the walked tree, so callers can fall back to hierarchical selection.
+func buildAutoContextTree(ctx context.Context, rootDir string, opts walker.Options) (string, *walker.Entry, error) {
+	tree, err := walker.Walk(ctx, rootDir, opts)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read directory %s: %w", rootDir, err)
+	}
+
 	var builder strings.Builder
 	builder.WriteString(filepath.Base(rootDir) + string(os.PathSeparator) + "\n")
+	err = walker.Render(tree, func(_ *walker.Entry, line string) error {
+		builder.WriteString(line + "\n")
+		if builder.Len() > maxAutoContextTreeChars {
+			return errAutoContextTreeTooLarge
+		}
+		return nil
+	})
+	if err != nil {
+		return "", tree, err
+	}
+	return builder.String(), tree, nil
+}
 
-	var walk func(string, string) error
-	walk = func(currentPath, prefix string) error {
-		entries, err := os.ReadDir(currentPath)
+// selectAutoContext runs one auto-context mode over a walked project. treeText is
+// the rendered tree; hierarchical reports that it was too large to send, so the
+// model explores the tree level by level instead. llm is not used offline. Errors
+// say which mode failed. It does not touch the Wails runtime.
+func (s *AutoContextService) selectAutoContext(ctx context.Context, llm provider.LLMProvider, rootDir string, tree *walker.Entry, treeText string, hierarchical bool, mode, task string, sizeLimit int64, record func(autoContextExchange)) (AutoContextResult, error) {
+	switch {
+	case mode == autoContextModeOffline:
+		result, err := s.RunOffline(ctx, tree, task, sizeLimit)
 		if err != nil {
-			return fmt.Errorf("failed to read directory %s: %w", currentPath, err)
+			return AutoContextResult{}, fmt.Errorf("offline auto-context failed: %w", err)
 		}
-		sort.SliceStable(entries, func(i, j int) bool {
-			if entries[i].IsDir() && !entries[j].IsDir() {
-				return true
-			}
-			if !entries[i].IsDir() && entries[j].IsDir() {
-				return false
-			}
-			return strings.ToLower(entries[i].Name()) < strings.ToLower(entries[j].Name())
-		})
-
-		visibleEntries := make([]os.DirEntry, 0, len(entries))
-		for _, entry := range entries {
-			relPath, _ := filepath.Rel(rootDir, filepath.Join(currentPath, entry.Name()))
-			if excludedMap[normalizeRelativePath(relPath)] {
-				continue
-			}
-			visibleEntries = append(visibleEntries, entry)
+		return result, nil
+	case hierarchical:
+		result, err := s.RunHierarchical(ctx, llm, rootDir, tree, task, record)
+		if err != nil {
+			return AutoContextResult{}, fmt.Errorf("hierarchical auto-context failed: %w", err)
 		}
-
-		for idx, entry := range visibleEntries {
-			branch := "├── "
-			nextPrefix := prefix + "│   "
-			if idx == len(visibleEntries)-1 {
-				branch = "└── "
-				nextPrefix = prefix + "    "
-			}
-			builder.WriteString(prefix + branch + entry.Name() + "\n")
-			if builder.Len() > maxAutoContextTreeChars {
-				return errAutoContextTreeTooLarge
-			}
-
-			if entry.IsDir() {
-				if err := walk(filepath.Join(currentPath, entry.Name()), nextPrefix); err != nil {
-					return err
-				}
-			}
+		return result, nil
+	case mode == autoContextModeIterative:
+		result, err := s.RunIterative(ctx, llm, rootDir, tree, treeText, task, sizeLimit, record)
+		if err != nil {
+			return AutoContextResult{}, fmt.Errorf("iterative auto-context failed: %w", err)
 		}
-		return nil
+		return result, nil
 	}
-
-	if err := walk(rootDir, ""); err != nil {
-		return "", err
+	prompt, err := s.BuildPrompt(treeText, task, "")
+	if err != nil {
+		return AutoContextResult{}, fmt.Errorf("failed to render auto-context prompt: %w", err)
 	}
-	if builder.Len() > maxAutoContextTreeChars {
-		return "", errAutoContextTreeTooLarge
+	result, err := s.generateAutoContext(ctx, llm, prompt, autoContextSchema(), record)
+	if err != nil {
+		return AutoContextResult{}, fmt.Errorf("auto-context call failed: %w", err)
 	}
-	return builder.String(), nil
+	return result, nil
 }
 
 func normalizeRelativePath(rel string) string {
@@ -222,11 +345,18 @@ func normalizeRelativePath(rel string) string {
 // "relative to rootDir" form. It accepts either strictly relative paths like
 // "frontend/src/..." or paths prefixed with the project root name, e.g.:
 //   "shotgun_code/frontend/src/..." when rootDir == ".../shotgun_code".
-func normalizeCandidateForRoot(rootDir, candidate string) string {
+// A path known holds as given is kept, so a project "notes" may have a "notes/"
+// package. known reports whether the tree shown to the model lists a path; asking
+// it rather than the file system keeps filtered-out files from shadowing the
+// prefixed form.
+func normalizeCandidateForRoot(rootDir, candidate string, known func(rel string) bool) string {
 	candidate = normalizeRelativePath(candidate)
 	if candidate == "" {
 		return ""
 	}
+	if known(candidate) {
+		return candidate
+	}
 
 	rootBase := filepath.Base(rootDir)
 	if rootBase == "" || rootBase == "." {
@@ -254,41 +384,51 @@ func normalizeCandidateForRoot(rootDir, candidate string) string {
 	return candidate
 }
 
-func resolveLLMSelection(rootDir string, candidates []string) ([]string, error) {
+// resolveLLMSelection matches picks to the files of tree, the walk the model was
+// shown, so files the walker filters left out are never selected. A picked directory
+// stands for every file below it in tree, each inheriting the pick's score, reason
+// and role unless the file is also picked by itself. Among picks of the same kind,
+// the higher score wins.
+func resolveLLMSelection(rootDir string, tree *walker.Entry, candidates []AutoContextFile) ([]AutoContextFile, error) {
 	if len(candidates) == 0 {
 		return nil, errors.New("no candidate paths provided")
 	}
-	selected := make(map[string]struct{})
-	for _, candidate := range candidates {
-		candidate = normalizeCandidateForRoot(rootDir, candidate)
-		if candidate == "" {
-			continue
+	entries := make(map[string]*walker.Entry)
+	tree.Visit(func(entry *walker.Entry) error {
+		if entry.RelPath != "." {
+			entries[entry.SlashPath()] = entry
 		}
-		absPath := filepath.Join(rootDir, filepath.FromSlash(candidate))
-		info, err := os.Stat(absPath)
-		if err != nil {
+		return nil
+	})
+	selected := make(map[string]AutoContextFile)
+	explicit := make(map[string]bool)
+	add := func(rel string, pick AutoContextFile, direct bool) {
+		pick.Path = rel
+		if existing, ok := selected[rel]; ok {
+			if explicit[rel] && !direct {
+				return
+			}
+			if explicit[rel] == direct && autoContextScore(existing) >= autoContextScore(pick) {
+				return
+			}
+		}
+		selected[rel] = pick
+		explicit[rel] = direct
+	}
+	for _, candidate := range candidates {
+		entry, ok := entries[normalizeCandidateForRoot(rootDir, candidate.Path, func(rel string) bool { return entries[rel] != nil })]
+		if !ok {
 			continue
 		}
-		if info.IsDir() {
-			filepath.WalkDir(absPath, func(path string, d os.DirEntry, err error) error {
-				if err != nil {
-					return nil
-				}
-				if d.IsDir() {
-					return nil
-				}
-				rel, relErr := filepath.Rel(rootDir, path)
-				if relErr != nil {
-					return nil
-				}
-				rel = normalizeRelativePath(rel)
-				if rel != "" {
-					selected[rel] = struct{}{}
+		if entry.IsDir {
+			entry.Visit(func(file *walker.Entry) error {
+				if file.HasContent() {
+					add(file.SlashPath(), candidate, false)
 				}
 				return nil
 			})
-		} else {
-			selected[filepath.ToSlash(candidate)] = struct{}{}
+		} else if entry.HasContent() {
+			add(entry.SlashPath(), candidate, true)
 		}
 	}
 
@@ -296,14 +436,23 @@ func resolveLLMSelection(rootDir string, candidates []string) ([]string, error)
 		return nil, errors.New("no existing files matched the LLM selection")
 	}
 
-	sorted := make([]string, 0, len(selected))
-	for rel := range selected {
-		sorted = append(sorted, rel)
+	sorted := make([]AutoContextFile, 0, len(selected))
+	for _, pick := range selected {
+		sorted = append(sorted, pick)
 	}
-	sort.Strings(sorted)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
 	return sorted, nil
 }
 
+// autoContextScore orders picks by score, unscored picks last.
+func autoContextScore(f AutoContextFile) float64 {
+	if f.Score == nil {
+		return -1
+	}
+	return *f.Score
+}
+
+// buildProviderConfig configures the active provider.
 func buildProviderConfig(settings LLMSettings) provider.Config {
 	return provider.Config{
 		Provider: settings.ActiveProvider,
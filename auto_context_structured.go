@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"shotgun_code/internal/llm/provider"
+)
+
+// maxAutoContextRepairEchoChars bounds how much of an unparsable reply is quoted
+// back to the model in the repair prompt.
+const maxAutoContextRepairEchoChars = 4_000
+
+var autoContextListDescriptions = map[string]string{
+	"inspect": "Relative paths of files to read before deciding",
+	"expand":  "Relative paths of collapsed directories to show next",
+}
+
+// autoContextSchema returns the JSON schema of an auto-context reply: "files",
+// "reasoning" and the given extra path lists (e.g. "inspect" in iterative mode).
+// All properties are required, as strict structured-output modes demand; the model
+// returns empty arrays for lists it does not use.
+func autoContextSchema(extra ...string) provider.Schema {
+	pathList := func(description string) map[string]any {
+		return map[string]any{
+			"type":        "array",
+			"items":       map[string]any{"type": "string"},
+			"description": description,
+		}
+	}
+	properties := map[string]any{
+		"files": map[string]any{
+			"type":        "array",
+			"description": "Files or directories to include",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"path":   map[string]any{"type": "string", "description": "Relative path from the project root"},
+					"score":  map[string]any{"type": "number", "description": "Confidence from 0 to 1 that the file is needed"},
+					"reason": map[string]any{"type": "string", "description": "One line on why the file is needed"},
+					"role": map[string]any{
+						"type": "string",
+						"enum": []string{autoContextRoleEditTarget, autoContextRoleReference, autoContextRoleTest},
+					},
+				},
+				"required":             []string{"path", "score", "reason", "role"},
+				"additionalProperties": false,
+			},
+		},
+		"reasoning": map[string]any{"type": "string", "description": "Short description of the selection"},
+	}
+	required := []string{"files"}
+	for _, name := range extra {
+		properties[name] = pathList(autoContextListDescriptions[name])
+		required = append(required, name)
+	}
+	required = append(required, "reasoning")
+
+	return provider.Schema{
+		Name: "auto_context_selection",
+		Definition: map[string]any{
+			"type":                 "object",
+			"properties":           properties,
+			"required":             required,
+			"additionalProperties": false,
+		},
+	}
+}
+
+// generateAutoContext makes one auto-context call and parses the reply. It uses the
+// provider's native structured output when there is one; if the reply still does not
+// parse, it retries once with the parse error appended to the prompt. An empty
+// selection is returned as errAutoContextEmptySelection without a retry. Every call
+// is passed to record, the retry's with Repair set.
+func (s *AutoContextService) generateAutoContext(ctx context.Context, llm provider.LLMProvider, prompt string, schema provider.Schema, record func(autoContextExchange)) (AutoContextResult, error) {
+	raw, err := callAutoContext(ctx, llm, prompt, schema, record)
+	if err != nil {
+		return AutoContextResult{}, err
+	}
+	result, parseErr := s.ParseResponse(raw)
+	if parseErr == nil || errors.Is(parseErr, errAutoContextEmptySelection) {
+		return result, parseErr
+	}
+
+	repairPrompt := buildAutoContextRepairPrompt(prompt, raw, parseErr)
+	raw, err = callAutoContext(ctx, llm, repairPrompt, schema, func(ex autoContextExchange) {
+		ex.Repair = true
+		record(ex)
+	})
+	if err != nil {
+		return AutoContextResult{}, fmt.Errorf("repair call after %v: %w", parseErr, err)
+	}
+	result, err = s.ParseResponse(raw)
+	if err != nil && !errors.Is(err, errAutoContextEmptySelection) {
+		return AutoContextResult{}, fmt.Errorf("reply still invalid after a repair attempt: %w", err)
+	}
+	return result, err
+}
+
+// callAutoContext prefers structured output. Not every model behind a provider
+// supports it, so a call the provider rejects with provider.ErrSchemaUnsupported is
+// repeated without the schema; the prompt carries the format instructions either
+// way. Other errors are returned as they are. Each call is passed to record.
+func callAutoContext(ctx context.Context, llm provider.LLMProvider, prompt string, schema provider.Schema, record func(autoContextExchange)) (string, error) {
+	if structured, ok := llm.(provider.StructuredGenerator); ok {
+		raw, apiCall, err := structured.GenerateStructured(ctx, prompt, schema)
+		record(autoContextExchange{Prompt: prompt, Response: raw, APICall: apiCall, Err: err})
+		if !errors.Is(err, provider.ErrSchemaUnsupported) {
+			return raw, err
+		}
+	}
+	raw, apiCall, err := llm.Generate(ctx, prompt)
+	record(autoContextExchange{Prompt: prompt, Response: raw, APICall: apiCall, Err: err})
+	return raw, err
+}
+
+func buildAutoContextRepairPrompt(prompt, reply string, parseErr error) string {
+	runes := []rune(reply)
+	if len(runes) > maxAutoContextRepairEchoChars {
+		reply = string(runes[:maxAutoContextRepairEchoChars]) + "…"
+	}
+	return fmt.Sprintf("%s\n\nYour previous reply could not be parsed:\n<reply>\n%s\n</reply>\nError: %v\n"+
+		"Reply again with only the corrected JSON object.", prompt, reply, parseErr)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"shotgun_code/internal/llm/provider"
+)
+
+// structuredLLM is a scriptedLLM with native structured output. Structured calls
+// fail with err when it is set and are otherwise answered from the script.
+type structuredLLM struct {
+	scriptedLLM
+	err   error
+	calls int
+}
+
+func (s *structuredLLM) GenerateStructured(ctx context.Context, prompt string, _ provider.Schema) (string, string, error) {
+	s.calls++
+	if s.err != nil {
+		return "", "structured call", s.err
+	}
+	return s.Generate(ctx, prompt)
+}
+
+func runGenerateAutoContext(t *testing.T, llm provider.LLMProvider) (AutoContextResult, []autoContextExchange, error) {
+	t.Helper()
+	var exchanges []autoContextExchange
+	result, err := NewAutoContextService().generateAutoContext(context.Background(), llm, "PROMPT", autoContextSchema(), func(ex autoContextExchange) {
+		exchanges = append(exchanges, ex)
+	})
+	return result, exchanges, err
+}
+
+const validAutoContextReply = `{"files": ["main.go"], "reasoning": ""}`
+
+func TestGenerateAutoContextRepairsReply(t *testing.T) {
+	llm := &scriptedLLM{replies: []string{"Sure! main.go is the one.", validAutoContextReply}}
+	result, exchanges, err := runGenerateAutoContext(t, llm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resultFilePaths(result); !slices.Equal(got, []string{"main.go"}) {
+		t.Errorf("files = %v, want [main.go]", got)
+	}
+	if len(exchanges) != 2 || exchanges[0].Repair || !exchanges[1].Repair {
+		t.Fatalf("exchanges = %+v, want the call and then its repair", exchanges)
+	}
+	repair := exchanges[1].Prompt
+	if !strings.HasPrefix(repair, "PROMPT") || !strings.Contains(repair, "<reply>\nSure! main.go is the one.\n</reply>") {
+		t.Errorf("repair prompt does not quote the prompt and the bad reply:\n%s", repair)
+	}
+	if exchanges[1].Response != validAutoContextReply || exchanges[1].APICall != "call 2" {
+		t.Errorf("repair exchange = %q %q, want the second reply", exchanges[1].Response, exchanges[1].APICall)
+	}
+}
+
+func TestGenerateAutoContextRepairFails(t *testing.T) {
+	llm := &scriptedLLM{replies: []string{"not json", "still not json"}}
+	_, exchanges, err := runGenerateAutoContext(t, llm)
+	if err == nil || !strings.Contains(err.Error(), "still invalid after a repair attempt") {
+		t.Errorf("err = %v, want the reply reported invalid after the repair", err)
+	}
+	if len(exchanges) != 2 {
+		t.Errorf("made %d calls, want 2", len(exchanges))
+	}
+}
+
+func TestGenerateAutoContextEmptySelectionIsNotRepaired(t *testing.T) {
+	llm := &scriptedLLM{replies: []string{`{"files": [], "reasoning": "nothing fits"}`}}
+	if _, exchanges, err := runGenerateAutoContext(t, llm); !errors.Is(err, errAutoContextEmptySelection) || len(exchanges) != 1 {
+		t.Errorf("err = %v after %d calls, want errAutoContextEmptySelection after 1", err, len(exchanges))
+	}
+}
+
+func TestGenerateAutoContextSchemaFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		fallback bool
+	}{
+		{"schema unsupported", fmt.Errorf("%w: openai chat API returned non-2xx status 400", provider.ErrSchemaUnsupported), true},
+		{"rate limit", errors.New("openai chat API returned non-2xx status 429"), false},
+		{"unauthorized", errors.New("openai chat API returned non-2xx status 401"), false},
+		{"cancelled", context.Canceled, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := &structuredLLM{scriptedLLM: scriptedLLM{replies: []string{validAutoContextReply}}, err: tt.err}
+			result, exchanges, err := runGenerateAutoContext(t, llm)
+			if llm.calls != 1 {
+				t.Errorf("made %d structured calls, want 1", llm.calls)
+			}
+			if len(exchanges) == 0 || !errors.Is(exchanges[0].Err, tt.err) || exchanges[0].APICall != "structured call" {
+				t.Fatalf("exchanges = %+v, want the failed structured call first", exchanges)
+			}
+			if !tt.fallback {
+				if !errors.Is(err, tt.err) || len(exchanges) != 1 || len(llm.prompts) != 0 {
+					t.Errorf("err = %v after %d exchanges and %d plain calls, want %v without a retry", err, len(exchanges), len(llm.prompts), tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(exchanges) != 2 || exchanges[1].Err != nil || exchanges[1].Response != validAutoContextReply || exchanges[1].Prompt != "PROMPT" {
+				t.Errorf("exchanges = %+v, want the failed call and the plain one", exchanges)
+			}
+			if got := resultFilePaths(result); !slices.Equal(got, []string{"main.go"}) {
+				t.Errorf("files = %v, want [main.go]", got)
+			}
+		})
+	}
+}
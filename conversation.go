@@ -0,0 +1,320 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"shotgun_code/internal/llm/provider"
+)
+
+const conversationPreviewChars = 200
+
+// Conversation is a multi-turn exchange with the model. It starts from a final
+// prompt, usually the whole shotgun context, and is continued with follow-ups that
+// are sent together with the earlier turns, so the context is not rebuilt for them.
+type Conversation struct {
+	ID        string                `json:"id"`
+	Title     string                `json:"title"` // The user task it started from
+	CreatedAt time.Time             `json:"createdAt"`
+	UpdatedAt time.Time             `json:"updatedAt"`
+	Messages  []ConversationMessage `json:"messages"`
+}
+
+// ConversationMessage is one turn. Assistant turns record which model answered.
+type ConversationMessage struct {
+	Role      string    `json:"role"` // "system", "user" or "assistant"
+	Content   string    `json:"content"`
+	Timestamp time.Time `json:"timestamp"`
+	Provider  string    `json:"provider,omitempty"`
+	Model     string    `json:"model,omitempty"`
+	APICall   string    `json:"apiCall,omitempty"`
+}
+
+// ConversationSummary lists a conversation without its messages.
+type ConversationSummary struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Turns     int       `json:"turns"`
+	LastReply string    `json:"lastReply"` // Start of the latest assistant turn
+}
+
+func (c Conversation) clone() Conversation {
+	c.Messages = append([]ConversationMessage(nil), c.Messages...)
+	return c
+}
+
+func (c Conversation) providerMessages() []provider.Message {
+	messages := make([]provider.Message, len(c.Messages))
+	for i, m := range c.Messages {
+		messages[i] = provider.Message{Role: m.Role, Content: m.Content}
+	}
+	return messages
+}
+
+// AddConversation stores a new conversation.
+func (hm *HistoryManager) AddConversation(c Conversation) {
+	hm.mu.Lock()
+	hm.history.Conversations = append([]Conversation{c.clone()}, hm.history.Conversations...)
+	hm.mu.Unlock()
+	hm.saveInBackground()
+}
+
+// turnLock serializes the turns of one conversation.
+type turnLock struct {
+	sync.Mutex
+	holders int  // Turns holding or waiting for the lock; guarded by HistoryManager.mu
+	dropped bool // The conversation is gone; the last holder removes the lock
+}
+
+// lockConversation serializes the turns of the conversation id and returns the
+// unlock function.
+func (hm *HistoryManager) lockConversation(id string) func() {
+	hm.mu.Lock()
+	if hm.turnLocks == nil {
+		hm.turnLocks = make(map[string]*turnLock)
+	}
+	lock, ok := hm.turnLocks[id]
+	if !ok {
+		lock = &turnLock{}
+		hm.turnLocks[id] = lock
+	}
+	lock.holders++
+	hm.mu.Unlock()
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		hm.mu.Lock()
+		lock.holders--
+		if lock.dropped && lock.holders == 0 {
+			delete(hm.turnLocks, id)
+		}
+		hm.mu.Unlock()
+	}
+}
+
+// dropTurnLock removes the turn lock of the deleted conversation id, at once if
+// no turn holds it and otherwise when the last one ends. hm.mu must be held.
+func (hm *HistoryManager) dropTurnLock(id string) {
+	lock, ok := hm.turnLocks[id]
+	switch {
+	case !ok:
+	case lock.holders == 0:
+		delete(hm.turnLocks, id)
+	default:
+		lock.dropped = true
+	}
+}
+
+// AppendToConversation adds messages to the conversation id and returns it.
+func (hm *HistoryManager) AppendToConversation(id string, messages ...ConversationMessage) (Conversation, error) {
+	hm.mu.Lock()
+	var updated Conversation
+	found := false
+	for i := range hm.history.Conversations {
+		c := &hm.history.Conversations[i]
+		if c.ID != id {
+			continue
+		}
+		c.Messages = append(c.Messages, messages...)
+		c.UpdatedAt = time.Now()
+		updated, found = c.clone(), true
+		break
+	}
+	hm.mu.Unlock()
+	if !found {
+		return Conversation{}, fmt.Errorf("conversation %s not found", id)
+	}
+	hm.saveInBackground()
+	return updated, nil
+}
+
+// GetConversation returns the conversation id.
+func (hm *HistoryManager) GetConversation(id string) (Conversation, bool) {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+	for _, c := range hm.history.Conversations {
+		if c.ID == id {
+			return c.clone(), true
+		}
+	}
+	return Conversation{}, false
+}
+
+// ConversationSummaries lists the stored conversations, most recently updated first.
+func (hm *HistoryManager) ConversationSummaries() []ConversationSummary {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+	summaries := make([]ConversationSummary, 0, len(hm.history.Conversations))
+	for _, c := range hm.history.Conversations {
+		summary := ConversationSummary{ID: c.ID, Title: c.Title, UpdatedAt: c.UpdatedAt}
+		for _, m := range c.Messages {
+			if m.Role == provider.RoleAssistant {
+				summary.Turns++
+				summary.LastReply = m.Content
+			}
+		}
+		if runes := []rune(summary.LastReply); len(runes) > conversationPreviewChars {
+			summary.LastReply = string(runes[:conversationPreviewChars]) + "…"
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.SliceStable(summaries, func(i, j int) bool { return summaries[i].UpdatedAt.After(summaries[j].UpdatedAt) })
+	return summaries
+}
+
+// DeleteConversation removes the conversation id.
+func (hm *HistoryManager) DeleteConversation(id string) error {
+	hm.mu.Lock()
+	kept := hm.history.Conversations[:0]
+	found := false
+	for _, c := range hm.history.Conversations {
+		if c.ID == id {
+			found = true
+			continue
+		}
+		kept = append(kept, c)
+	}
+	hm.history.Conversations = kept
+	hm.dropTurnLock(id)
+	hm.mu.Unlock()
+	if !found {
+		return fmt.Errorf("conversation %s not found", id)
+	}
+	return hm.SaveHistory()
+}
+
+// --- App Methods Binding ---
+
+// StartConversation sends finalPrompt as the first turn of a new conversation and
+// returns it with the reply. The turn is also logged to the prompt history.
+func (a *App) StartConversation(userTask, finalPrompt string) (Conversation, error) {
+	if a.historyManager == nil {
+		return Conversation{}, errors.New("history manager is not initialized")
+	}
+	if strings.TrimSpace(finalPrompt) == "" {
+		return Conversation{}, errors.New("prompt is required")
+	}
+	now := time.Now()
+	conversation := Conversation{
+		ID:        fmt.Sprintf("%d", now.UnixNano()),
+		Title:     strings.TrimSpace(userTask),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Messages:  []ConversationMessage{{Role: provider.RoleUser, Content: finalPrompt, Timestamp: now}},
+	}
+	reply, err := a.chat(conversation, userTask)
+	if err != nil {
+		return Conversation{}, err
+	}
+	conversation.Messages = append(conversation.Messages, reply)
+	a.historyManager.AddConversation(conversation)
+	return conversation, nil
+}
+
+// ContinueConversation sends message as the next user turn of the conversation id,
+// together with every earlier turn, and returns the conversation with the reply.
+// A failed call leaves the conversation unchanged so the turn can be retried.
+// Follow-ups sent while a turn is in flight wait for it and include its reply.
+func (a *App) ContinueConversation(id, message string) (Conversation, error) {
+	return a.continueConversation(id, message, func(conversation Conversation) (ConversationMessage, error) {
+		return a.chat(conversation, "FOLLOW-UP: "+conversation.Title)
+	})
+}
+
+// continueConversation implements ContinueConversation; send returns the reply to
+// the conversation ending with the new turn.
+func (a *App) continueConversation(id, message string, send func(Conversation) (ConversationMessage, error)) (Conversation, error) {
+	if a.historyManager == nil {
+		return Conversation{}, errors.New("history manager is not initialized")
+	}
+	if strings.TrimSpace(message) == "" {
+		return Conversation{}, errors.New("message is required")
+	}
+	unlock := a.historyManager.lockConversation(id)
+	defer unlock()
+	conversation, ok := a.historyManager.GetConversation(id)
+	if !ok {
+		return Conversation{}, fmt.Errorf("conversation %s not found", id)
+	}
+	turn := ConversationMessage{Role: provider.RoleUser, Content: message, Timestamp: time.Now()}
+	conversation.Messages = append(conversation.Messages, turn)
+	reply, err := send(conversation)
+	if err != nil {
+		return Conversation{}, err
+	}
+	return a.historyManager.AppendToConversation(id, turn, reply)
+}
+
+// GetConversation returns the conversation id with all of its turns.
+func (a *App) GetConversation(id string) (Conversation, error) {
+	if a.historyManager == nil {
+		return Conversation{}, errors.New("history manager is not initialized")
+	}
+	conversation, ok := a.historyManager.GetConversation(id)
+	if !ok {
+		return Conversation{}, fmt.Errorf("conversation %s not found", id)
+	}
+	return conversation, nil
+}
+
+// ListConversations returns the stored conversations, most recently updated first.
+func (a *App) ListConversations() []ConversationSummary {
+	if a.historyManager == nil {
+		return []ConversationSummary{}
+	}
+	return a.historyManager.ConversationSummaries()
+}
+
+// DeleteConversation removes the conversation id.
+func (a *App) DeleteConversation(id string) error {
+	if a.historyManager == nil {
+		return errors.New("history manager is not initialized")
+	}
+	return a.historyManager.DeleteConversation(id)
+}
+
+// chat sends the conversation to the active provider and returns the reply turn.
+// The newest user turn and the reply are logged to the prompt history under label.
+func (a *App) chat(conversation Conversation, label string) (ConversationMessage, error) {
+	if !a.HasActiveLlmKey() {
+		return ConversationMessage{}, errors.New("no active LLM configuration found")
+	}
+	cfg := buildProviderConfig(a.settings.LLMSettings)
+	providerInstance, err := a.getOrCreateProvider(cfg)
+	if err != nil {
+		return ConversationMessage{}, fmt.Errorf("failed to create provider: %w", err)
+	}
+
+	wailsRuntime.LogInfof(a.ctx, "Sending conversation turn %d via %s (%s)...", len(conversation.Messages), cfg.Provider, cfg.Model)
+	response, apiCall, err := providerInstance.Chat(a.ctx, conversation.providerMessages())
+
+	historyResponse := response
+	if err != nil {
+		historyResponse = fmt.Sprintf("ERROR during conversation turn: %v", err)
+	}
+	a.historyManager.addItem(PromptHistoryItem{
+		UserTask:          label,
+		ConstructedPrompt: conversation.Messages[len(conversation.Messages)-1].Content,
+		Response:          historyResponse,
+		APICall:           apiCall,
+		ConversationID:    conversation.ID,
+	})
+	if err != nil {
+		return ConversationMessage{}, fmt.Errorf("LLM conversation turn failed: %w", err)
+	}
+	return ConversationMessage{
+		Role:      provider.RoleAssistant,
+		Content:   response,
+		Timestamp: time.Now(),
+		Provider:  cfg.Provider,
+		Model:     cfg.Model,
+		APICall:   apiCall,
+	}, nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"shotgun_code/internal/llm/provider"
+)
+
+func TestContinueConversationSerializesTurns(t *testing.T) {
+	a := newTestHistoryApp(t)
+	a.historyManager.AddConversation(Conversation{ID: "c", Messages: []ConversationMessage{
+		{Role: provider.RoleUser, Content: "context"},
+		{Role: provider.RoleAssistant, Content: "ready"},
+	}})
+
+	// Each reply names how many turns the model saw, so a turn sent without the
+	// reply to the previous follow-up shows up as a repeated count.
+	send := func(c Conversation) (ConversationMessage, error) {
+		time.Sleep(10 * time.Millisecond)
+		return ConversationMessage{Role: provider.RoleAssistant, Content: fmt.Sprintf("seen %d", len(c.Messages))}, nil
+	}
+	var wg sync.WaitGroup
+	for i := range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := a.continueConversation("c", fmt.Sprintf("follow-up %d", i), send); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	conversation, _ := a.historyManager.GetConversation("c")
+	if len(conversation.Messages) != 8 {
+		t.Fatalf("conversation has %d turns, want 8", len(conversation.Messages))
+	}
+	for i, m := range conversation.Messages[2:] {
+		wantRole := provider.RoleUser
+		if i%2 == 1 {
+			wantRole = provider.RoleAssistant
+			if want := fmt.Sprintf("seen %d", i+2); m.Content != want {
+				t.Errorf("reply %d = %q, want %q", i/2, m.Content, want)
+			}
+		}
+		if m.Role != wantRole {
+			t.Errorf("turn %d has role %s, want %s", i+2, m.Role, wantRole)
+		}
+	}
+}
+
+func TestContinueConversationKeepsFailedTurnsOut(t *testing.T) {
+	a := newTestHistoryApp(t)
+	a.historyManager.AddConversation(Conversation{ID: "c", Messages: []ConversationMessage{{Role: provider.RoleUser, Content: "context"}}})
+
+	_, err := a.continueConversation("c", "follow-up", func(Conversation) (ConversationMessage, error) {
+		return ConversationMessage{}, fmt.Errorf("model unavailable")
+	})
+	if err == nil {
+		t.Fatal("failed turn reported success")
+	}
+	if conversation, _ := a.historyManager.GetConversation("c"); len(conversation.Messages) != 1 {
+		t.Errorf("conversation has %d turns after a failed follow-up, want 1", len(conversation.Messages))
+	}
+}
+
+func TestClearRemovesConversations(t *testing.T) {
+	a := newTestHistoryApp(t)
+	a.historyManager.AddItem("task", "prompt", "reply", "")
+	a.historyManager.AddConversation(Conversation{ID: "c"})
+
+	if err := a.historyManager.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if items := a.historyManager.GetItems(); len(items) != 0 {
+		t.Errorf("items left after Clear: %v", items)
+	}
+	if summaries := a.historyManager.ConversationSummaries(); len(summaries) != 0 {
+		t.Errorf("conversations left after Clear: %v", summaries)
+	}
+
+	// The cleared history is what a restart loads.
+	reloaded := NewHistoryManager(a)
+	reloaded.historyPath = a.historyManager.historyPath
+	a.historyManager.saving.Wait()
+	if err := reloaded.LoadHistory(); err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.history.Items) != 0 || len(reloaded.history.Conversations) != 0 {
+		t.Errorf("reloaded history = %+v", reloaded.history)
+	}
+}
+
+func TestDeletingConversationsDropsTurnLocks(t *testing.T) {
+	hm := newTestHistoryApp(t).historyManager
+	for _, id := range []string{"idle", "busy", "cleared"} {
+		hm.AddConversation(Conversation{ID: id})
+	}
+	hasLock := func(id string) bool {
+		hm.mu.Lock()
+		defer hm.mu.Unlock()
+		_, ok := hm.turnLocks[id]
+		return ok
+	}
+	hm.lockConversation("idle")()
+	hm.lockConversation("cleared")()
+	unlockBusy := hm.lockConversation("busy")
+
+	if err := hm.DeleteConversation("idle"); err != nil {
+		t.Fatal(err)
+	}
+	if hasLock("idle") {
+		t.Error("the lock of a deleted idle conversation is kept")
+	}
+
+	// A turn in flight keeps its lock until it ends, so a follow-up queued behind
+	// it still waits.
+	if err := hm.DeleteConversation("busy"); err != nil {
+		t.Fatal(err)
+	}
+	if !hasLock("busy") {
+		t.Fatal("the lock of a conversation with a turn in flight was dropped")
+	}
+	queued := make(chan struct{})
+	go func() {
+		hm.lockConversation("busy")()
+		close(queued)
+	}()
+	for waiting := false; !waiting; time.Sleep(time.Millisecond) {
+		hm.mu.Lock()
+		waiting = hm.turnLocks["busy"].holders == 2
+		hm.mu.Unlock()
+	}
+	select {
+	case <-queued:
+		t.Fatal("a queued turn ran while the first held the lock")
+	default:
+	}
+	unlockBusy()
+	<-queued
+	if hasLock("busy") {
+		t.Error("the lock of a deleted conversation is kept after its last turn")
+	}
+
+	if err := hm.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if hasLock("cleared") {
+		t.Error("Clear keeps turn locks")
+	}
+}
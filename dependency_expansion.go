@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"shotgun_code/internal/depgraph"
+	"shotgun_code/internal/walker"
+)
+
+const maxDependencyDepth = 5
+
+// ExpandSelectionByDependencies returns the project files the selected files depend
+// on, found by parsing the sources rather than asking a model. depth is the number
+// of hops to follow (1 when not positive). Files the active ignore rules or include
+// mode keep out of the context are never added. Call it before
+// RequestShotgunContextGeneration and add the returned paths to the selection.
+func (a *App) ExpandSelectionByDependencies(rootDir string, selectedPaths []string, depth int) (depgraph.Expansion, error) {
+	rootDir = strings.TrimSpace(rootDir)
+	if rootDir == "" {
+		return depgraph.Expansion{}, errors.New("project root is required")
+	}
+	if len(selectedPaths) == 0 {
+		return depgraph.Expansion{}, errors.New("select at least one file to expand")
+	}
+	if depth > maxDependencyDepth {
+		depth = maxDependencyDepth
+	}
+
+	expansion, err := depgraph.Expand(a.ctx, rootDir, selectedPaths, depgraph.Options{
+		Depth: depth,
+		Skip:  a.dependencySkip(rootDir),
+	})
+	if err != nil {
+		return depgraph.Expansion{}, fmt.Errorf("failed to expand selection: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Dependency expansion added %d files to %d selected (%d unresolved references)", len(expansion.Added), len(selectedPaths), len(expansion.Unresolved))
+	return expansion, nil
+}
+
+// dependencySkip applies the walker filters context generation uses.
+func (a *App) dependencySkip(rootDir string) func(relPath string) bool {
+	return walkSkips(a.ctx, rootDir, a.ruleWalkOptions(rootDir))
+}
+
+// walkSkips returns a depgraph Skip function that leaves out every file a walk with
+// opts would not list with content: files a filter excludes and files below a folder
+// that is excluded, pruned or not followed. Directory listings are cached.
+func walkSkips(ctx context.Context, rootDir string, opts walker.Options) func(relPath string) bool {
+	listings := make(map[string]map[string]*walker.Entry)
+	var lookup func(rel string) *walker.Entry
+	lookup = func(rel string) *walker.Entry {
+		dir := path.Dir(rel)
+		if dir != "." {
+			parent := lookup(dir)
+			if parent == nil || !parent.IsDir || parent.Pruned() || (parent.IsSymlink && !parent.Followed) {
+				return nil
+			}
+		}
+		listing, ok := listings[dir]
+		if !ok {
+			entries, _ := walker.ReadDir(ctx, rootDir, filepath.FromSlash(dir), opts)
+			listing = make(map[string]*walker.Entry, len(entries))
+			for _, entry := range entries {
+				listing[entry.Name] = entry
+			}
+			listings[dir] = listing
+		}
+		return listing[path.Base(rel)]
+	}
+	return func(relPath string) bool {
+		entry := lookup(relPath)
+		return entry == nil || !entry.HasContent()
+	}
+}
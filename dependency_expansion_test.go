@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"shotgun_code/internal/ignore"
+)
+
+func TestDependencySkipAppliesWalkFilters(t *testing.T) {
+	root := writeProject(t, map[string]string{
+		".gitignore":         "gen/\n*_mock.go\n",
+		"main.go":            "package main\n",
+		"util.go":            "package main\n",
+		"api_mock.go":        "package main\n",
+		"gen/types.go":       "package gen\n",
+		"vendor/lib/lib.go":  "package lib\n",
+		"internal/x/x.go":    "package x\n",
+		"internal/x/x.proto": "syntax = \"proto3\";\n",
+	})
+
+	tests := []struct {
+		name        string
+		noGitignore bool
+		noCustom    bool
+		include     string
+		skipped     []string
+		kept        []string
+	}{
+		{
+			name:    "defaults",
+			skipped: []string{"api_mock.go", "gen/types.go", "vendor/lib/lib.go", "missing.go"},
+			kept:    []string{"main.go", "util.go", "internal/x/x.go"},
+		},
+		{
+			name:        "ignore rules off",
+			noGitignore: true,
+			noCustom:    true,
+			kept:        []string{"api_mock.go", "gen/types.go", "vendor/lib/lib.go"},
+		},
+		{
+			name:    "include mode",
+			include: "internal/\n",
+			skipped: []string{"main.go", "util.go"},
+			kept:    []string{"internal/x/x.go", "internal/x/x.proto"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &App{ctx: context.Background(), useGitignore: !tt.noGitignore, useCustomIgnore: !tt.noCustom}
+			a.currentCustomIgnorePatterns = ignore.ParseRules(customIgnoreRulesSource, "", "vendor/\n")
+			if tt.include != "" {
+				matcher, err := compileIncludePatterns(tt.include)
+				if err != nil {
+					t.Fatal(err)
+				}
+				a.useIncludePatterns, a.currentIncludePatterns = true, matcher
+			}
+			skip := a.dependencySkip(root)
+			for _, rel := range tt.skipped {
+				if !skip(rel) {
+					t.Errorf("%s was not skipped", rel)
+				}
+			}
+			for _, rel := range tt.kept {
+				if skip(rel) {
+					t.Errorf("%s was skipped", rel)
+				}
+			}
+		})
+	}
+}
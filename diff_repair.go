@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"shotgun_code/internal/llm/provider"
+)
+
+const (
+	defaultDiffRepairs = 3
+	maxDiffRepairs     = 5
+
+	// diffRepairFileChars caps how much of a failing file goes into a repair prompt.
+	diffRepairFileChars = 100_000
+)
+
+// DiffRepairAttempt is one try at applying the diff. Attempt 1 is the diff as given;
+// later attempts use the model's repaired version.
+type DiffRepairAttempt struct {
+	Attempt      int               `json:"attempt"`
+	AppliedHunks int               `json:"appliedHunks"`
+	Failures     []DiffHunkFailure `json:"failures"`
+	Error        string            `json:"error,omitempty"` // The repair call failed
+}
+
+// DiffRepairReport is the outcome of RepairShotgunDiff.
+type DiffRepairReport struct {
+	Succeeded        bool                `json:"succeeded"`
+	SucceededAttempt int                 `json:"succeededAttempt"` // 0 when no attempt applied cleanly
+	Attempts         []DiffRepairAttempt `json:"attempts"`
+	Diff             string              `json:"diff"` // Regenerated from the files when an attempt applied, else the last diff tried
+}
+
+// diffRepairCall is one repair exchange, passed to the caller for logging.
+type diffRepairCall struct {
+	Attempt  int
+	Prompt   string
+	Response string
+	APICall  string
+	Err      error
+}
+
+// RepairShotgunDiff checks that gitDiffText applies to the project at rootDir. When
+// hunks fail, the model is shown the failures and the current file contents and asked
+// for a corrected diff of those files, up to maxRepairs times (0 means the default).
+// Nothing is written to disk; the report carries the diff to apply.
+func (a *App) RepairShotgunDiff(rootDir, gitDiffText string, maxRepairs int) (DiffRepairReport, error) {
+	rootDir = strings.TrimSpace(rootDir)
+	if rootDir == "" {
+		return DiffRepairReport{}, errors.New("project root is required")
+	}
+	if strings.TrimSpace(gitDiffText) == "" {
+		return DiffRepairReport{}, errors.New("diff is required")
+	}
+	if maxRepairs <= 0 {
+		maxRepairs = defaultDiffRepairs
+	}
+	maxRepairs = min(maxRepairs, maxDiffRepairs)
+
+	var llm provider.LLMProvider
+	if a.HasActiveLlmKey() {
+		cfg := buildProviderConfig(a.settings.LLMSettings)
+		providerInstance, err := a.getOrCreateProvider(cfg)
+		if err != nil {
+			return DiffRepairReport{}, fmt.Errorf("failed to create provider: %w", err)
+		}
+		llm = providerInstance
+	}
+
+	report, err := repairDiff(a.ctx, llm, rootDir, gitDiffText, maxRepairs, func(call diffRepairCall) {
+		if a.historyManager == nil {
+			return
+		}
+		response := call.Response
+		if call.Err != nil {
+			response = fmt.Sprintf("ERROR during diff repair: %v", call.Err)
+		}
+		a.historyManager.AddItem(fmt.Sprintf("DIFF REPAIR (attempt %d)", call.Attempt), call.Prompt, response, call.APICall)
+	})
+	if err != nil {
+		return DiffRepairReport{}, err
+	}
+	last := report.Attempts[len(report.Attempts)-1]
+	if report.Succeeded {
+		wailsRuntime.LogInfof(a.ctx, "RepairShotgunDiff: diff applies cleanly on attempt %d", report.SucceededAttempt)
+	} else {
+		wailsRuntime.LogWarningf(a.ctx, "RepairShotgunDiff: %d hunk(s) still fail after %d attempt(s)", len(last.Failures), len(report.Attempts))
+	}
+	return report, nil
+}
+
+// repairDiff runs the apply-and-repair loop. Without llm only the first attempt is
+// made. A failed repair call ends the loop and is noted on its attempt.
+func repairDiff(ctx context.Context, llm provider.LLMProvider, rootDir, diffText string, maxRepairs int, record func(diffRepairCall)) (DiffRepairReport, error) {
+	files := parseGitDiff(diffText)
+	if len(files) == 0 {
+		return DiffRepairReport{}, errors.New("no file diffs found")
+	}
+
+	var report DiffRepairReport
+	for attempt := 1; ; attempt++ {
+		result := applyGitDiffInMemory(rootDir, files)
+		report.Attempts = append(report.Attempts, DiffRepairAttempt{
+			Attempt:      attempt,
+			AppliedHunks: result.Applied,
+			Failures:     result.Failures,
+		})
+		report.Diff = joinFileDiffs(files)
+		if len(result.Failures) == 0 {
+			// The tolerant matching may have applied hunks git apply rejects; the
+			// regenerated diff has the files' exact lines.
+			report.Diff = result.Diff
+			report.Succeeded, report.SucceededAttempt = true, attempt
+			return report, nil
+		}
+		if llm == nil || attempt > maxRepairs {
+			return report, nil
+		}
+
+		prompt := buildDiffRepairPrompt(rootDir, files, result.Failures)
+		response, apiCall, err := llm.Generate(ctx, prompt)
+		if record != nil {
+			record(diffRepairCall{Attempt: attempt + 1, Prompt: prompt, Response: response, APICall: apiCall, Err: err})
+		}
+		if err != nil {
+			report.Attempts = append(report.Attempts, DiffRepairAttempt{Attempt: attempt + 1, Error: err.Error()})
+			return report, nil
+		}
+		repaired := parseGitDiff(stripDiffFences(response))
+		if len(repaired) == 0 {
+			report.Attempts = append(report.Attempts, DiffRepairAttempt{Attempt: attempt + 1, Error: "the reply contains no diff"})
+			return report, nil
+		}
+		files = replaceFileDiffs(files, repaired)
+	}
+}
+
+// buildDiffRepairPrompt asks for corrected diffs of the files with failing hunks,
+// showing what failed and what those files contain now.
+func buildDiffRepairPrompt(rootDir string, files []gitFileDiff, failures []DiffHunkFailure) string {
+	failing := make(map[string]bool)
+	var paths []string
+	for _, f := range failures {
+		if !failing[f.File] {
+			failing[f.File] = true
+			paths = append(paths, f.File)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("The git diff below does not apply to the project. Write a corrected git diff for the failing files only.\n")
+	b.WriteString("Base it on the current file contents shown below, keep the intended changes, and use exact context lines.\n")
+	b.WriteString("Reply with the complete corrected diff in `diff --git` format and nothing else.\n\n")
+
+	b.WriteString("## Failures\n\n")
+	for _, f := range failures {
+		if f.Hunk > 0 {
+			fmt.Fprintf(&b, "- %s, hunk %d (%s): %s\n", f.File, f.Hunk, f.Header, f.Reason)
+		} else {
+			fmt.Fprintf(&b, "- %s: %s\n", f.File, f.Reason)
+		}
+		if f.Excerpt != "" {
+			fmt.Fprintf(&b, "  Lines around where the hunk was expected:\n```\n%s\n```\n", f.Excerpt)
+		}
+	}
+
+	b.WriteString("\n## Diff of the failing files\n\n```diff\n")
+	for _, d := range files {
+		if failing[d.Path()] {
+			b.WriteString(d.Block + "\n")
+		}
+	}
+	b.WriteString("```\n\n## Current file contents\n\n")
+	for _, path := range paths {
+		if _, ok := cleanDiffPath(path); !ok {
+			continue // Outside the project; its failure says so
+		}
+		data, err := os.ReadFile(filepath.Join(rootDir, filepath.FromSlash(path)))
+		if err != nil {
+			fmt.Fprintf(&b, "<file path=\"%s\">\n(the file does not exist)\n</file>\n\n", path)
+			continue
+		}
+		content := string(data)
+		if len(content) > diffRepairFileChars {
+			content = content[:diffRepairFileChars] + "\n... (truncated)"
+		}
+		fmt.Fprintf(&b, "<file path=\"%s\">\n%s\n</file>\n\n", path, strings.TrimSuffix(content, "\n"))
+	}
+	return b.String()
+}
+
+// replaceFileDiffs swaps in the repaired diffs, matched by path, keeping the order of
+// files. Repaired files the diff did not have are added at the end.
+func replaceFileDiffs(files, repaired []gitFileDiff) []gitFileDiff {
+	byPath := make(map[string]gitFileDiff, len(repaired))
+	for _, d := range repaired {
+		byPath[d.Path()] = d
+	}
+	out := make([]gitFileDiff, 0, len(files)+len(repaired))
+	for _, d := range files {
+		if r, ok := byPath[d.Path()]; ok {
+			d = r
+			delete(byPath, d.Path())
+		}
+		out = append(out, d)
+	}
+	for _, d := range repaired {
+		if _, ok := byPath[d.Path()]; ok {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+func joinFileDiffs(files []gitFileDiff) string {
+	blocks := make([]string, len(files))
+	for i, d := range files {
+		blocks[i] = d.Block
+	}
+	return strings.Join(blocks, "\n") + "\n"
+}
+
+// stripDiffFences removes a markdown code fence around a model's diff.
+func stripDiffFences(text string) string {
+	cleaned := strings.TrimSpace(text)
+	if !strings.HasPrefix(cleaned, "```") {
+		return cleaned
+	}
+	if nl := strings.Index(cleaned, "\n"); nl >= 0 {
+		cleaned = cleaned[nl+1:]
+	} else {
+		return ""
+	}
+	if idx := strings.LastIndex(cleaned, "```"); idx >= 0 {
+		cleaned = cleaned[:idx]
+	}
+	return strings.TrimSpace(cleaned)
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+
+	"shotgun_code/internal/ignore"
+	"shotgun_code/internal/walker"
+)
+
+const (
+	defaultDirectoryPageSize = 500
+	maxDirectoryPageSize     = 5000
+	defaultPathSearchLimit   = 200
+)
+
+// DirectoryPage is one page of a directory's children, as returned by ListDirectory.
+// Child directories are not expanded, so their totals stay zero; HasChildren tells the
+// tree whether to show a toggle.
+type DirectoryPage struct {
+	RelDir     string      `json:"relDir"`
+	Entries    []*FileNode `json:"entries"`
+	Total      int         `json:"total"`                // Children in the directory after filtering
+	NextCursor string      `json:"nextCursor,omitempty"` // Empty on the last page
+}
+
+// ListDirectory lists the children of relDir ("" or "." for the project root) one page
+// at a time, with the same ignore flags as ListFiles. Pass the previous page's
+// NextCursor to continue; limit <= 0 uses the default page size.
+func (a *App) ListDirectory(rootDir, relDir, cursor string, limit int) (DirectoryPage, error) {
+	rootDir = strings.TrimSpace(rootDir)
+	if rootDir == "" {
+		return DirectoryPage{}, errors.New("project root is required")
+	}
+	rel := normalizeRelativePath(relDir)
+	if rel == "" {
+		rel = "."
+	}
+	if limit <= 0 {
+		limit = defaultDirectoryPageSize
+	}
+	if limit > maxDirectoryPageSize {
+		limit = maxDirectoryPageSize
+	}
+	offset := 0
+	if cursor != "" {
+		n, err := strconv.Atoi(cursor)
+		if err != nil || n < 0 {
+			return DirectoryPage{}, fmt.Errorf("invalid cursor %q", cursor)
+		}
+		offset = n
+	}
+
+	opts := a.listingWalkOptions(a.projectIgnoreStack(rootDir))
+	entries, err := walker.ReadDir(a.ctx, rootDir, filepath.FromSlash(rel), opts)
+	if err != nil {
+		return DirectoryPage{}, fmt.Errorf("failed to list %s: %w", rel, err)
+	}
+
+	page := DirectoryPage{RelDir: rel, Total: len(entries), Entries: []*FileNode{}}
+	if offset >= len(entries) {
+		return page, nil
+	}
+	end := offset + limit
+	if end < len(entries) {
+		page.NextCursor = strconv.Itoa(end)
+	} else {
+		end = len(entries)
+	}
+	for _, entry := range entries[offset:end] {
+		node := fileNodesFromEntries([]*walker.Entry{entry}, a.treeTotals())[0]
+		node.HasChildren = walker.HasChildren(a.ctx, rootDir, entry, opts)
+		page.Entries = append(page.Entries, node)
+	}
+	return page, nil
+}
+
+// projectIgnoreStack returns the git ignore rules for rootDir, reusing the stack of
+// the open project so nested .gitignore files are only read once.
+func (a *App) projectIgnoreStack(rootDir string) *ignore.Stack {
+	a.ignoreMu.Lock()
+	defer a.ignoreMu.Unlock()
+	if a.projectGitignore != nil && a.projectGitignore.Root() == rootDir {
+		return a.projectGitignore
+	}
+	a.projectGitignore = ignore.NewStack(rootDir)
+	return a.projectGitignore
+}
+
+// newProjectIgnoreStack re-reads the git ignore rules for rootDir and makes them the
+// project's stack, e.g. when the tree is listed afresh.
+func (a *App) newProjectIgnoreStack(rootDir string) *ignore.Stack {
+	stack := ignore.NewStack(rootDir)
+	a.ignoreMu.Lock()
+	a.projectGitignore = stack
+	a.ignoreMu.Unlock()
+	return stack
+}
+
+// PathSearchResult holds the project paths matching a FindPaths query.
+type PathSearchResult struct {
+	Paths     []string `json:"paths"`     // Slash-separated, relative to the project root
+	Truncated bool     `json:"truncated"` // More paths matched than the limit allowed
+}
+
+// FindPaths returns the paths of files and folders matching query without sending
+// the tree to the frontend, sorted by path. A query containing glob characters is
+// matched as a doublestar pattern against the whole relative path; otherwise every
+// space-separated word must appear in the path, ignoring case. It searches the
+// SearchFiles index, so the listing's symlink policy and include mode apply and
+// folders not expanded by active custom rules are not searched.
+func (a *App) FindPaths(rootDir, query string, limit int) (PathSearchResult, error) {
+	rootDir = strings.TrimSpace(rootDir)
+	if rootDir == "" {
+		return PathSearchResult{}, errors.New("project root is required")
+	}
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return PathSearchResult{Paths: []string{}}, nil
+	}
+	if limit <= 0 {
+		limit = defaultPathSearchLimit
+	}
+
+	match, err := pathQueryMatcher(query)
+	if err != nil {
+		return PathSearchResult{}, err
+	}
+	index, err := a.fileSearchIndex(rootDir)
+	if err != nil {
+		return PathSearchResult{}, err
+	}
+
+	result := PathSearchResult{Paths: []string{}}
+	index.mu.RLock()
+	for rel := range index.paths {
+		if rel != "" && match(rel) {
+			result.Paths = append(result.Paths, rel)
+		}
+	}
+	index.mu.RUnlock()
+	sort.Strings(result.Paths)
+	if len(result.Paths) > limit {
+		result.Paths = result.Paths[:limit]
+		result.Truncated = true
+	}
+	return result, nil
+}
+
+func pathQueryMatcher(query string) (func(rel string) bool, error) {
+	if strings.ContainsAny(query, "*?[{") {
+		pattern := strings.TrimPrefix(filepath.ToSlash(query), "/")
+		if !doublestar.ValidatePattern(pattern) {
+			return nil, fmt.Errorf("invalid path pattern %q", query)
+		}
+		return func(rel string) bool { return doublestar.MatchUnvalidated(pattern, rel) }, nil
+	}
+	words := strings.Fields(strings.ToLower(query))
+	return func(rel string) bool {
+		lower := strings.ToLower(rel)
+		for _, word := range words {
+			if !strings.Contains(lower, word) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"shotgun_code/internal/ignore"
+)
+
+func TestFindPathsUsesSearchIndex(t *testing.T) {
+	root := writeProject(t, map[string]string{
+		"src/app/main.go":   "package main\n",
+		"src/app/main_test": "x\n",
+		"src/lib/util.go":   "package lib\n",
+		"vendor/dep/dep.go": "package dep\n",
+	})
+	a := &App{ctx: context.Background(), useGitignore: true, useCustomIgnore: true}
+	a.currentCustomIgnorePatterns = ignore.ParseRules(customIgnoreRulesSource, "", "vendor/\n")
+
+	tests := []struct {
+		query         string
+		limit         int
+		want          []string
+		wantTruncated bool
+	}{
+		{"**/*.go", 0, []string{"src/app/main.go", "src/lib/util.go"}, false},
+		{"APP main", 0, []string{"src/app/main.go", "src/app/main_test"}, false},
+		{"src", 2, []string{"src", "src/app"}, true},
+		{"vendor", 0, []string{"vendor"}, false}, // Not expanded while custom rules are on
+	}
+	for _, tt := range tests {
+		got, err := a.FindPaths(root, tt.query, tt.limit)
+		if err != nil {
+			t.Fatalf("FindPaths(%q): %v", tt.query, err)
+		}
+		if !slices.Equal(got.Paths, tt.want) || got.Truncated != tt.wantTruncated {
+			t.Errorf("FindPaths(%q) = %v (truncated %v), want %v (truncated %v)", tt.query, got.Paths, got.Truncated, tt.want, tt.wantTruncated)
+		}
+	}
+	if a.searchIndex == nil {
+		t.Fatal("FindPaths did not build the search index")
+	}
+
+	// Turning custom rules off changes the listing, so the index is rebuilt.
+	a.useCustomIgnore = false
+	got, err := a.FindPaths(root, "dep.go", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got.Paths, []string{"vendor/dep/dep.go"}) {
+		t.Errorf("FindPaths(dep.go) with custom rules off = %v", got.Paths)
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+
+	"shotgun_code/internal/fuzzy"
+	"shotgun_code/internal/walker"
+)
+
+const (
+	defaultFileSearchLimit = 50
+	maxFileSearchLimit     = 1000
+)
+
+// FileSearchResult is one ranked SearchFiles match.
+type FileSearchResult struct {
+	RelPath   string `json:"relPath"` // Slash-separated, relative to the project root
+	Name      string `json:"name"`
+	IsDir     bool   `json:"isDir"`
+	Score     int    `json:"score"`
+	Positions []int  `json:"positions"` // Rune indices into RelPath that matched the query
+}
+
+// indexedPath is a path in the search index with the ignore flags the tree shows for it.
+type indexedPath struct {
+	isDir         bool
+	hasContent    bool // A regular file, or a followed link to one
+	gitignored    bool
+	customIgnored bool
+}
+
+// fileSearchIndex lists every path the file tree would show for one project. It is
+// built on the first search and kept current from Watchman events; a change to the
+// options that shape the listing makes the next search rebuild it.
+type fileSearchIndex struct {
+	mu      sync.RWMutex
+	rootDir string
+	key     string // Listing options the index was built with
+	dirty   bool
+	paths   map[string]indexedPath
+}
+
+// SearchFiles fuzzy-matches query against the relative paths of the project at rootDir,
+// fzf style, and returns up to limit results, best first. Paths hidden by the active
+// ignore toggles and by include mode are not searched.
+func (a *App) SearchFiles(rootDir, query string, limit int) ([]FileSearchResult, error) {
+	rootDir = strings.TrimSpace(rootDir)
+	if rootDir == "" {
+		return nil, errors.New("project root is required")
+	}
+	if strings.TrimSpace(query) == "" {
+		return []FileSearchResult{}, nil
+	}
+	if limit <= 0 {
+		limit = defaultFileSearchLimit
+	}
+	if limit > maxFileSearchLimit {
+		limit = maxFileSearchLimit
+	}
+
+	index, err := a.fileSearchIndex(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []FileSearchResult{}
+	index.mu.RLock()
+	for rel, p := range index.paths {
+		if (p.gitignored && a.useGitignore) || (p.customIgnored && a.useCustomIgnore) {
+			continue
+		}
+		match, ok := fuzzy.Match(query, rel)
+		if !ok {
+			continue
+		}
+		results = append(results, FileSearchResult{
+			RelPath:   rel,
+			Name:      path.Base(rel),
+			IsDir:     p.isDir,
+			Score:     match.Score,
+			Positions: match.Positions,
+		})
+	}
+	index.mu.RUnlock()
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if len(results[i].RelPath) != len(results[j].RelPath) {
+			return len(results[i].RelPath) < len(results[j].RelPath)
+		}
+		return results[i].RelPath < results[j].RelPath
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// fileSearchIndex returns the index for rootDir, building it when missing or stale.
+func (a *App) fileSearchIndex(rootDir string) (*fileSearchIndex, error) {
+	key := a.searchIndexKey()
+	a.searchMu.Lock()
+	defer a.searchMu.Unlock()
+
+	index := a.searchIndex
+	if index != nil {
+		index.mu.RLock()
+		fresh := index.rootDir == rootDir && index.key == key && !index.dirty
+		index.mu.RUnlock()
+		if fresh {
+			return index, nil
+		}
+	}
+
+	tree, err := walker.Walk(a.ctx, rootDir, a.listingWalkOptions(a.projectIgnoreStack(rootDir)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to index %s: %w", rootDir, err)
+	}
+	index = &fileSearchIndex{rootDir: rootDir, key: key, paths: make(map[string]indexedPath)}
+	for _, child := range tree.Children {
+		index.addEntries(child)
+	}
+	a.searchIndex = index
+	return index, nil
+}
+
+// searchIndexKey captures the settings that change which paths the listing contains.
+func (a *App) searchIndexKey() string {
+	return strings.Join([]string{
+		a.settings.CustomIgnoreRules,
+		fmt.Sprint(a.useCustomIgnore), // Decides whether custom-ignored folders are expanded
+		a.settings.IncludePatterns,
+		fmt.Sprint(a.useIncludePatterns),
+		a.settings.SymlinkPolicy,
+	}, "\x00")
+}
+
+// addEntries must be called with idx.mu held for writing, or before idx is shared.
+func (idx *fileSearchIndex) addEntries(root *walker.Entry) {
+	root.Visit(func(entry *walker.Entry) error {
+		idx.paths[entry.SlashPath()] = indexedPathOf(entry)
+		return nil
+	})
+}
+
+// markSearchIndexDirty makes the next search rebuild the index, e.g. after git
+// exclude files changed.
+func (a *App) markSearchIndexDirty() {
+	a.searchMu.Lock()
+	index := a.searchIndex
+	a.searchMu.Unlock()
+	if index == nil {
+		return
+	}
+	index.mu.Lock()
+	index.dirty = true
+	index.mu.Unlock()
+}
+
+// updateSearchIndex applies a Watchman event to the search index. Removed paths
+// drop out with everything below them; created paths are read with the listing
+// options. A changed .gitignore can flip flags anywhere, so it forces a rebuild.
+func (a *App) updateSearchIndex(rootDir string, event fsnotify.Event) {
+	a.searchMu.Lock()
+	index := a.searchIndex
+	a.searchMu.Unlock()
+	if index == nil || index.rootDir != rootDir {
+		return
+	}
+	rel, err := filepath.Rel(rootDir, event.Name)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return
+	}
+	rel = filepath.ToSlash(rel)
+
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	if path.Base(rel) == ".gitignore" {
+		index.dirty = true
+	}
+	if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+		delete(index.paths, rel)
+		prefix := rel + "/"
+		for p := range index.paths {
+			if strings.HasPrefix(p, prefix) {
+				delete(index.paths, p)
+			}
+		}
+	}
+	if event.Op&(fsnotify.Create|fsnotify.Write) == 0 {
+		return
+	}
+	if _, known := index.paths[rel]; known && event.Op&fsnotify.Create == 0 {
+		return
+	}
+	if _, err := os.Lstat(event.Name); err != nil {
+		return
+	}
+	// Read the parent with the listing options so the new entry gets the same
+	// filters and flags as in the tree, then index it and anything below it.
+	opts := a.listingWalkOptions(a.projectIgnoreStack(rootDir))
+	parent := filepath.Dir(filepath.FromSlash(rel))
+	siblings, err := walker.ReadDir(a.ctx, rootDir, parent, opts)
+	if err != nil {
+		return
+	}
+	for _, entry := range siblings {
+		if entry.SlashPath() == rel {
+			index.addListed(a.ctx, rootDir, entry, opts)
+		}
+	}
+}
+
+// addListed indexes entry and, for directories, everything listed below it. Each
+// level is read from the project root so ignore rules see project-relative paths.
+// It must be called with idx.mu held for writing.
+func (idx *fileSearchIndex) addListed(ctx context.Context, rootDir string, entry *walker.Entry, opts walker.Options) {
+	idx.paths[entry.SlashPath()] = indexedPathOf(entry)
+	if !entry.IsDir || !walker.HasChildren(ctx, rootDir, entry, opts) {
+		return
+	}
+	children, err := walker.ReadDir(ctx, rootDir, entry.RelPath, opts)
+	if err != nil {
+		return
+	}
+	for _, child := range children {
+		idx.addListed(ctx, rootDir, child, opts)
+	}
+}
+
+func indexedPathOf(entry *walker.Entry) indexedPath {
+	return indexedPath{
+		isDir:         entry.IsDir,
+		hasContent:    entry.HasContent(),
+		gitignored:    entry.Marked(walker.FilterGitignore),
+		customIgnored: entry.Marked(walker.FilterCustomIgnore),
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestUpdateSearchIndexTracksIgnoredPaths(t *testing.T) {
+	root := writeProject(t, map[string]string{
+		".gitignore": "*.log\n",
+		"main.go":    "package main\n",
+	})
+	a := &App{ctx: context.Background(), useGitignore: true}
+	index, err := a.fileSearchIndex(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Gitignored files are listed, flagged, so the watcher must pass their events on.
+	logPath := filepath.Join(root, "debug.log")
+	if err := os.WriteFile(logPath, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	a.updateSearchIndex(root, fsnotify.Event{Name: logPath, Op: fsnotify.Create})
+	if p, ok := index.paths["debug.log"]; !ok || !p.gitignored {
+		t.Fatalf("created gitignored file indexed as %+v, %v", p, ok)
+	}
+	if results, _ := a.SearchFiles(root, "debug", 0); len(results) != 0 {
+		t.Errorf("SearchFiles returned gitignored file while gitignore is on: %+v", results)
+	}
+
+	if err := os.Remove(logPath); err != nil {
+		t.Fatal(err)
+	}
+	a.updateSearchIndex(root, fsnotify.Event{Name: logPath, Op: fsnotify.Remove})
+	if _, ok := index.paths["debug.log"]; ok {
+		t.Error("removed gitignored file is still indexed")
+	}
+
+	a.markSearchIndexDirty()
+	if !index.dirty {
+		t.Error("markSearchIndexDirty did not mark the index")
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"shotgun_code/internal/llm/provider"
+	"shotgun_code/internal/tokens"
+)
+
+// Default token budgets of the final prompt sections. The context gets what is left
+// of the model's context window after the other sections and the reply reserve.
+const (
+	defaultTaskTokenBudget     = 4_000
+	defaultRulesTokenBudget    = 2_000
+	defaultTemplateTokenBudget = 8_000
+	finalPromptReplyReserve    = 16_000 // Tokens kept free for the model's answer
+)
+
+// Names of the final prompt sections.
+const (
+	promptSectionTask     = "task"
+	promptSectionRules    = "rules"
+	promptSectionTemplate = "template"
+	promptSectionContext  = "context"
+)
+
+// FinalPromptRequest names what goes into a final prompt.
+type FinalPromptRequest struct {
+	TemplateID string            `json:"templateId"`
+	Task       string            `json:"task"`
+	Rules      string            `json:"rules"`   // Empty uses the saved custom prompt rules
+	Context    string            `json:"context"` // The generated shotgun context
+	Values     map[string]string `json:"values,omitempty"`
+	Budgets    PromptBudgets     `json:"budgets"` // Zero fields use the defaults
+}
+
+// PromptBudgets are token budgets per section; 0 means no limit.
+type PromptBudgets struct {
+	Task     int64 `json:"task"`
+	Rules    int64 `json:"rules"`
+	Template int64 `json:"template"`
+	Context  int64 `json:"context"`
+}
+
+// FinalPromptSection is the size of one section of the final prompt.
+type FinalPromptSection struct {
+	Name       string `json:"name"` // "task", "rules", "template" or "context"
+	Chars      int    `json:"chars"`
+	Tokens     int64  `json:"tokens"`
+	Budget     int64  `json:"budget"` // 0 when unlimited
+	OverBudget bool   `json:"overBudget"`
+	Included   bool   `json:"included"` // False when the template has no placeholder for it
+}
+
+// FinalPrompt is an assembled prompt with its size report. Token counts are
+// estimates.
+type FinalPrompt struct {
+	Prompt               string               `json:"prompt"`
+	Sections             []FinalPromptSection `json:"sections"`
+	TotalTokens          int64                `json:"totalTokens"`
+	Provider             string               `json:"provider"`
+	Model                string               `json:"model"`
+	ContextWindow        int64                `json:"contextWindow"` // 0 when unknown
+	ExceedsContextWindow bool                 `json:"exceedsContextWindow"`
+	Warnings             []string             `json:"warnings"`
+}
+
+// BuildFinalPrompt renders the template with the task, the custom prompt rules and
+// the context, and reports the size of each section against its budget and of the
+// whole prompt against the active model's context window. Nothing is sent.
+func (a *App) BuildFinalPrompt(req FinalPromptRequest) (FinalPrompt, error) {
+	if a.promptTemplates == nil {
+		return FinalPrompt{}, errors.New("prompt templates are not initialized")
+	}
+	template, ok := a.promptTemplates.Get(strings.TrimSpace(req.TemplateID))
+	if !ok {
+		return FinalPrompt{}, fmt.Errorf("prompt template %s not found", req.TemplateID)
+	}
+	if strings.TrimSpace(req.Rules) == "" {
+		req.Rules = a.settings.CustomPromptRules
+	}
+	settings := a.settings.LLMSettings
+	model := ""
+	if settings.ActiveProvider != "" {
+		model = fallbackModel(settings)
+	}
+	return assembleFinalPrompt(template, req, settings.ActiveProvider, model, provider.ModelContextWindow(settings.ActiveProvider, model), time.Now())
+}
+
+// assembleFinalPrompt does the work of BuildFinalPrompt for a given model.
+func assembleFinalPrompt(template PromptTemplate, req FinalPromptRequest, providerName, model string, window int64, now time.Time) (FinalPrompt, error) {
+	values := make(map[string]string, len(req.Values)+3)
+	for name, value := range req.Values {
+		values[name] = value
+	}
+	values[templateVarTask] = req.Task
+	values[templateVarRules] = req.Rules
+	values[templateVarFileStructure] = req.Context
+
+	prompt, err := renderPromptTemplate(template, values, now)
+	if err != nil {
+		return FinalPrompt{}, err
+	}
+	result := FinalPrompt{
+		Prompt:        prompt,
+		TotalTokens:   tokens.EstimateText(prompt),
+		Provider:      providerName,
+		Model:         model,
+		ContextWindow: window,
+		Warnings:      []string{},
+	}
+
+	budgets := req.Budgets
+	if budgets.Task == 0 {
+		budgets.Task = defaultTaskTokenBudget
+	}
+	if budgets.Rules == 0 {
+		budgets.Rules = defaultRulesTokenBudget
+	}
+	if budgets.Template == 0 {
+		budgets.Template = defaultTemplateTokenBudget
+	}
+	if budgets.Context == 0 && window > 0 {
+		budgets.Context = max(window-finalPromptReplyReserve-budgets.Task-budgets.Rules-budgets.Template, 0)
+	}
+
+	defaults := make(map[string]string, len(template.Variables))
+	for _, v := range template.Variables {
+		defaults[v.Name] = v.Default
+	}
+	inserted := []struct {
+		name, variable, text string
+		budget               int64
+	}{
+		{promptSectionTask, templateVarTask, req.Task, budgets.Task},
+		{promptSectionRules, templateVarRules, req.Rules, budgets.Rules},
+		{promptSectionContext, templateVarFileStructure, req.Context, budgets.Context},
+	}
+	var insertedChars int
+	var insertedTokens int64
+	for _, s := range inserted {
+		// A placeholder used several times inserts its value each time; an empty
+		// value is replaced by the variable's default.
+		uses := strings.Count(template.Content, "{"+s.variable+"}")
+		section := FinalPromptSection{Name: s.name, Budget: s.budget, Included: uses > 0}
+		if section.Included {
+			text := s.text
+			if text == "" {
+				text = defaults[s.variable]
+			}
+			section.Chars = uses * len(text)
+			section.Tokens = int64(uses) * tokens.EstimateText(text)
+			insertedChars += section.Chars
+			insertedTokens += section.Tokens
+		} else if strings.TrimSpace(s.text) != "" {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Template %s has no {%s} placeholder, so the %s is not included.", template.ID, s.variable, s.name))
+		}
+		result.Sections = append(result.Sections, section)
+	}
+	// The template section is everything around the inserted values.
+	result.Sections = append(result.Sections, FinalPromptSection{
+		Name:     promptSectionTemplate,
+		Chars:    max(len(prompt)-insertedChars, 0),
+		Tokens:   max(result.TotalTokens-insertedTokens, 0),
+		Budget:   budgets.Template,
+		Included: true,
+	})
+	for i := range result.Sections {
+		s := &result.Sections[i]
+		if s.Budget > 0 && s.Tokens > s.Budget {
+			s.OverBudget = true
+			result.Warnings = append(result.Warnings, fmt.Sprintf("The %s is about %d tokens, over its budget of %d.", s.Name, s.Tokens, s.Budget))
+		}
+	}
+
+	switch {
+	case model == "":
+		result.Warnings = append(result.Warnings, "No LLM model is selected, so the prompt size was not checked against a context window.")
+	case window == 0:
+		result.Warnings = append(result.Warnings, fmt.Sprintf("The context window of %s is unknown, so the prompt size was not checked against it.", model))
+	case result.TotalTokens > window:
+		result.ExceedsContextWindow = true
+		result.Warnings = append(result.Warnings, fmt.Sprintf("The prompt is about %d tokens, more than the %d-token context window of %s.", result.TotalTokens, window, model))
+	case result.TotalTokens+finalPromptReplyReserve > window:
+		result.Warnings = append(result.Warnings, fmt.Sprintf("The prompt is about %d tokens and leaves less than %d tokens of the %d-token context window of %s for the reply.", result.TotalTokens, finalPromptReplyReserve, window, model))
+	}
+	return result, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func finalPromptSection(t *testing.T, p FinalPrompt, name string) FinalPromptSection {
+	t.Helper()
+	for _, s := range p.Sections {
+		if s.Name == name {
+			return s
+		}
+	}
+	t.Fatalf("no %s section in %+v", name, p.Sections)
+	return FinalPromptSection{}
+}
+
+func TestAssembleFinalPromptCountsRepeatedPlaceholders(t *testing.T) {
+	template := PromptTemplate{
+		ID:      "repeat",
+		Content: "Task: {TASK}\n{FILE_STRUCTURE}\nAgain, the task: {TASK}\nAnd the files: {FILE_STRUCTURE}\n",
+		Variables: []TemplateVariable{
+			builtinTemplateVariables[templateVarTask],
+			builtinTemplateVariables[templateVarFileStructure],
+		},
+	}
+	context := strings.Repeat("package main\n", 300)
+	req := FinalPromptRequest{Task: "rename the flag", Context: context, Budgets: PromptBudgets{Context: 1500}}
+
+	p, err := assembleFinalPrompt(template, req, "openai", "gpt-test", 100_000, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	task := finalPromptSection(t, p, promptSectionTask)
+	files := finalPromptSection(t, p, promptSectionContext)
+	tmpl := finalPromptSection(t, p, promptSectionTemplate)
+	if task.Chars != 2*len(req.Task) || files.Chars != 2*len(context) {
+		t.Errorf("task %d chars, context %d chars; want both counted twice", task.Chars, files.Chars)
+	}
+	if want := len("Task: \n\nAgain, the task: \nAnd the files: \n"); tmpl.Chars != want {
+		t.Errorf("template section is %d chars, want %d", tmpl.Chars, want)
+	}
+	if !files.OverBudget {
+		t.Errorf("context of %d tokens is within its budget of %d", files.Tokens, files.Budget)
+	}
+	if rules := finalPromptSection(t, p, promptSectionRules); rules.Included || rules.Chars != 0 {
+		t.Errorf("rules section = %+v for a template without {RULES}", rules)
+	}
+}
+
+func TestAssembleFinalPromptCountsDefaults(t *testing.T) {
+	template := PromptTemplate{
+		ID:        "task-only",
+		Content:   "Do this: {TASK}",
+		Variables: []TemplateVariable{builtinTemplateVariables[templateVarTask]},
+	}
+	p, err := assembleFinalPrompt(template, FinalPromptRequest{}, "", "", 0, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := builtinTemplateVariables[templateVarTask].Default
+	if p.Prompt != "Do this: "+want {
+		t.Fatalf("prompt = %q", p.Prompt)
+	}
+	if task := finalPromptSection(t, p, promptSectionTask); task.Chars != len(want) {
+		t.Errorf("task section is %d chars, want the %d of the default", task.Chars, len(want))
+	}
+	if tmpl := finalPromptSection(t, p, promptSectionTemplate); tmpl.Chars != len("Do this: ") {
+		t.Errorf("template section is %d chars", tmpl.Chars)
+	}
+}
@@ -1,43 +1,107 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 import {main} from '../models';
+import {depgraph} from '../models';
 import {provider} from '../models';
+import {retrieval} from '../models';
 import {context} from '../models';
 
+export function BuildFinalPrompt(arg1:main.FinalPromptRequest):Promise<main.FinalPrompt>;
+
+export function BuildRetrievalIndex(arg1:string):Promise<main.RetrievalIndexStatus>;
+
 export function ClearPromptHistory():Promise<void>;
 
+export function ContinueConversation(arg1:string,arg2:string):Promise<main.Conversation>;
+
+export function DeleteConversation(arg1:string):Promise<void>;
+
+export function DeletePromptTemplate(arg1:string):Promise<void>;
+
 export function ExecuteLLMPrompt(arg1:string,arg2:string):Promise<main.PromptHistoryItem>;
 
+export function ExpandSelectionByDependencies(arg1:string,arg2:Array<string>,arg3:number):Promise<depgraph.Expansion>;
+
+export function ExplainIgnore(arg1:string,arg2:string,arg3:Array<string>):Promise<main.IgnoreExplanation>;
+
+export function FindPaths(arg1:string,arg2:string,arg3:number):Promise<main.PathSearchResult>;
+
 export function GetAutoContextButtonTexture():Promise<string>;
 
+export function GetAutoContextMode():Promise<string>;
+
+export function GetConversation(arg1:string):Promise<main.Conversation>;
+
 export function GetCustomIgnoreRules():Promise<string>;
 
 export function GetCustomPromptRules():Promise<string>;
 
+export function GetIncludePatterns():Promise<string>;
+
+export function GetIncludeSymbolMap():Promise<boolean>;
+
+export function GetLargeFileSettings():Promise<main.LargeFileSettings>;
+
 export function GetLlmSettings():Promise<main.LLMSettings>;
 
 export function GetPromptHistory():Promise<Array<main.PromptHistoryItem>>;
 
+export function GetPromptTemplate(arg1:string):Promise<main.PromptTemplate>;
+
+export function GetRetrievalSettings():Promise<main.RetrievalSettings>;
+
+export function GetSymlinkPolicy():Promise<string>;
+
+export function GrepProject(arg1:string,arg2:string,arg3:main.GrepOptions):Promise<main.GrepResult>;
+
 export function HasActiveLlmKey():Promise<boolean>;
 
+export function ListConversations():Promise<Array<main.ConversationSummary>>;
+
+export function ListDirectory(arg1:string,arg2:string,arg3:string,arg4:number):Promise<main.DirectoryPage>;
+
 export function ListFiles(arg1:string):Promise<Array<main.FileNode>>;
 
 export function ListLlmModels(arg1:string):Promise<Array<provider.ModelInfo>>;
 
+export function ListPromptTemplates():Promise<Array<main.PromptTemplate>>;
+
 export function LoadRepoScan(arg1:string):Promise<string>;
 
-export function RequestAutoContextSelection(arg1:string,arg2:Array<string>,arg3:string):Promise<Array<string>>;
+export function PreviewCustomIgnoreRules(arg1:string,arg2:string):Promise<main.IgnoreRulesPreview>;
+
+export function RenderPromptTemplate(arg1:string,arg2:Record<string, string>):Promise<string>;
+
+export function RepairShotgunDiff(arg1:string,arg2:string,arg3:number):Promise<main.DiffRepairReport>;
+
+export function RequestAutoContextSelection(arg1:string,arg2:Array<string>,arg3:string):Promise<main.AutoContextSelection>;
 
 export function RequestShotgunContextGeneration(arg1:string,arg2:Array<string>):Promise<void>;
 
+export function RestorePromptTemplateVersion(arg1:string,arg2:number):Promise<main.PromptTemplate>;
+
+export function RetrieveFiles(arg1:string,arg2:string,arg3:number):Promise<Array<retrieval.Hit>>;
+
+export function SavePromptTemplate(arg1:main.PromptTemplate):Promise<main.PromptTemplate>;
+
 export function SaveRepoScan(arg1:string,arg2:string):Promise<void>;
 
+export function SearchFiles(arg1:string,arg2:string,arg3:number):Promise<Array<main.FileSearchResult>>;
+
 export function SelectDirectory():Promise<string>;
 
+export function SetAutoContextMode(arg1:string):Promise<void>;
+
 export function SetCustomIgnoreRules(arg1:string):Promise<void>;
 
 export function SetCustomPromptRules(arg1:string):Promise<void>;
 
+export function SetIncludePatterns(arg1:string):Promise<void>;
+
+export function SetIncludeSymbolMap(arg1:boolean):Promise<void>;
+
+export function SetLargeFileSettings(arg1:main.LargeFileSettings):Promise<void>;
+
 export function SetLlmApiKey(arg1:string,arg2:string):Promise<void>;
 
 export function SetLlmBaseURL(arg1:string):Promise<void>;
@@ -46,12 +110,20 @@ export function SetLlmModel(arg1:string,arg2:string):Promise<void>;
 
 export function SetLlmProvider(arg1:string):Promise<void>;
 
+export function SetRetrievalSettings(arg1:main.RetrievalSettings):Promise<void>;
+
+export function SetSymlinkPolicy(arg1:string):Promise<void>;
+
 export function SetUseCustomIgnore(arg1:boolean):Promise<void>;
 
 export function SetUseGitignore(arg1:boolean):Promise<void>;
 
+export function SetUseIncludePatterns(arg1:boolean):Promise<void>;
+
 export function SplitShotgunDiff(arg1:string,arg2:number):Promise<Array<string>>;
 
+export function StartConversation(arg1:string,arg2:string):Promise<main.Conversation>;
+
 export function StartFileWatcher(arg1:string):Promise<void>;
 
 export function StartupTest(arg1:context.Context):Promise<void>;
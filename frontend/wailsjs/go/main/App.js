@@ -2,18 +2,58 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function BuildFinalPrompt(arg1) {
+  return window['go']['main']['App']['BuildFinalPrompt'](arg1);
+}
+
+export function BuildRetrievalIndex(arg1) {
+  return window['go']['main']['App']['BuildRetrievalIndex'](arg1);
+}
+
 export function ClearPromptHistory() {
   return window['go']['main']['App']['ClearPromptHistory']();
 }
 
+export function ContinueConversation(arg1, arg2) {
+  return window['go']['main']['App']['ContinueConversation'](arg1, arg2);
+}
+
+export function DeleteConversation(arg1) {
+  return window['go']['main']['App']['DeleteConversation'](arg1);
+}
+
+export function DeletePromptTemplate(arg1) {
+  return window['go']['main']['App']['DeletePromptTemplate'](arg1);
+}
+
 export function ExecuteLLMPrompt(arg1, arg2) {
   return window['go']['main']['App']['ExecuteLLMPrompt'](arg1, arg2);
 }
 
+export function ExpandSelectionByDependencies(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExpandSelectionByDependencies'](arg1, arg2, arg3);
+}
+
+export function ExplainIgnore(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExplainIgnore'](arg1, arg2, arg3);
+}
+
+export function FindPaths(arg1, arg2, arg3) {
+  return window['go']['main']['App']['FindPaths'](arg1, arg2, arg3);
+}
+
 export function GetAutoContextButtonTexture() {
   return window['go']['main']['App']['GetAutoContextButtonTexture']();
 }
 
+export function GetAutoContextMode() {
+  return window['go']['main']['App']['GetAutoContextMode']();
+}
+
+export function GetConversation(arg1) {
+  return window['go']['main']['App']['GetConversation'](arg1);
+}
+
 export function GetCustomIgnoreRules() {
   return window['go']['main']['App']['GetCustomIgnoreRules']();
 }
@@ -22,6 +62,18 @@ export function GetCustomPromptRules() {
   return window['go']['main']['App']['GetCustomPromptRules']();
 }
 
+export function GetIncludePatterns() {
+  return window['go']['main']['App']['GetIncludePatterns']();
+}
+
+export function GetIncludeSymbolMap() {
+  return window['go']['main']['App']['GetIncludeSymbolMap']();
+}
+
+export function GetLargeFileSettings() {
+  return window['go']['main']['App']['GetLargeFileSettings']();
+}
+
 export function GetLlmSettings() {
   return window['go']['main']['App']['GetLlmSettings']();
 }
@@ -30,10 +82,34 @@ export function GetPromptHistory() {
   return window['go']['main']['App']['GetPromptHistory']();
 }
 
+export function GetPromptTemplate(arg1) {
+  return window['go']['main']['App']['GetPromptTemplate'](arg1);
+}
+
+export function GetRetrievalSettings() {
+  return window['go']['main']['App']['GetRetrievalSettings']();
+}
+
+export function GetSymlinkPolicy() {
+  return window['go']['main']['App']['GetSymlinkPolicy']();
+}
+
+export function GrepProject(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GrepProject'](arg1, arg2, arg3);
+}
+
 export function HasActiveLlmKey() {
   return window['go']['main']['App']['HasActiveLlmKey']();
 }
 
+export function ListConversations() {
+  return window['go']['main']['App']['ListConversations']();
+}
+
+export function ListDirectory(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['ListDirectory'](arg1, arg2, arg3, arg4);
+}
+
 export function ListFiles(arg1) {
   return window['go']['main']['App']['ListFiles'](arg1);
 }
@@ -42,10 +118,26 @@ export function ListLlmModels(arg1) {
   return window['go']['main']['App']['ListLlmModels'](arg1);
 }
 
+export function ListPromptTemplates() {
+  return window['go']['main']['App']['ListPromptTemplates']();
+}
+
 export function LoadRepoScan(arg1) {
   return window['go']['main']['App']['LoadRepoScan'](arg1);
 }
 
+export function PreviewCustomIgnoreRules(arg1, arg2) {
+  return window['go']['main']['App']['PreviewCustomIgnoreRules'](arg1, arg2);
+}
+
+export function RenderPromptTemplate(arg1, arg2) {
+  return window['go']['main']['App']['RenderPromptTemplate'](arg1, arg2);
+}
+
+export function RepairShotgunDiff(arg1, arg2, arg3) {
+  return window['go']['main']['App']['RepairShotgunDiff'](arg1, arg2, arg3);
+}
+
 export function RequestAutoContextSelection(arg1, arg2, arg3) {
   return window['go']['main']['App']['RequestAutoContextSelection'](arg1, arg2, arg3);
 }
@@ -54,14 +146,34 @@ export function RequestShotgunContextGeneration(arg1, arg2) {
   return window['go']['main']['App']['RequestShotgunContextGeneration'](arg1, arg2);
 }
 
+export function RestorePromptTemplateVersion(arg1, arg2) {
+  return window['go']['main']['App']['RestorePromptTemplateVersion'](arg1, arg2);
+}
+
+export function RetrieveFiles(arg1, arg2, arg3) {
+  return window['go']['main']['App']['RetrieveFiles'](arg1, arg2, arg3);
+}
+
+export function SavePromptTemplate(arg1) {
+  return window['go']['main']['App']['SavePromptTemplate'](arg1);
+}
+
 export function SaveRepoScan(arg1, arg2) {
   return window['go']['main']['App']['SaveRepoScan'](arg1, arg2);
 }
 
+export function SearchFiles(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SearchFiles'](arg1, arg2, arg3);
+}
+
 export function SelectDirectory() {
   return window['go']['main']['App']['SelectDirectory']();
 }
 
+export function SetAutoContextMode(arg1) {
+  return window['go']['main']['App']['SetAutoContextMode'](arg1);
+}
+
 export function SetCustomIgnoreRules(arg1) {
   return window['go']['main']['App']['SetCustomIgnoreRules'](arg1);
 }
@@ -70,6 +182,18 @@ export function SetCustomPromptRules(arg1) {
   return window['go']['main']['App']['SetCustomPromptRules'](arg1);
 }
 
+export function SetIncludePatterns(arg1) {
+  return window['go']['main']['App']['SetIncludePatterns'](arg1);
+}
+
+export function SetIncludeSymbolMap(arg1) {
+  return window['go']['main']['App']['SetIncludeSymbolMap'](arg1);
+}
+
+export function SetLargeFileSettings(arg1) {
+  return window['go']['main']['App']['SetLargeFileSettings'](arg1);
+}
+
 export function SetLlmApiKey(arg1, arg2) {
   return window['go']['main']['App']['SetLlmApiKey'](arg1, arg2);
 }
@@ -86,6 +210,14 @@ export function SetLlmProvider(arg1) {
   return window['go']['main']['App']['SetLlmProvider'](arg1);
 }
 
+export function SetRetrievalSettings(arg1) {
+  return window['go']['main']['App']['SetRetrievalSettings'](arg1);
+}
+
+export function SetSymlinkPolicy(arg1) {
+  return window['go']['main']['App']['SetSymlinkPolicy'](arg1);
+}
+
 export function SetUseCustomIgnore(arg1) {
   return window['go']['main']['App']['SetUseCustomIgnore'](arg1);
 }
@@ -94,10 +226,18 @@ export function SetUseGitignore(arg1) {
   return window['go']['main']['App']['SetUseGitignore'](arg1);
 }
 
+export function SetUseIncludePatterns(arg1) {
+  return window['go']['main']['App']['SetUseIncludePatterns'](arg1);
+}
+
 export function SplitShotgunDiff(arg1, arg2) {
   return window['go']['main']['App']['SplitShotgunDiff'](arg1, arg2);
 }
 
+export function StartConversation(arg1, arg2) {
+  return window['go']['main']['App']['StartConversation'](arg1, arg2);
+}
+
 export function StartFileWatcher(arg1) {
   return window['go']['main']['App']['StartFileWatcher'](arg1);
 }
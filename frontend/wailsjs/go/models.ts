@@ -1,27 +1,792 @@
+export namespace depgraph {
+	
+	export class Dependency {
+	    relPath: string;
+	    depth: number;
+	    from: string;
+	    reason: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Dependency(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.relPath = source["relPath"];
+	        this.depth = source["depth"];
+	        this.from = source["from"];
+	        this.reason = source["reason"];
+	    }
+	}
+	export class Expansion {
+	    added: Dependency[];
+	    unresolved?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Expansion(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.added = this.convertValues(source["added"], Dependency);
+	        this.unresolved = source["unresolved"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
 export namespace main {
 	
-	export class FileNode {
-	    name: string;
-	    path: string;
+	export class AutoContextFile {
+	    path: string;
+	    score?: number;
+	    reason?: string;
+	    role?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AutoContextFile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.score = source["score"];
+	        this.reason = source["reason"];
+	        this.role = source["role"];
+	    }
+	}
+	export class AutoContextSelection {
+	    files: AutoContextFile[];
+	    reasoning?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AutoContextSelection(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.files = this.convertValues(source["files"], AutoContextFile);
+	        this.reasoning = source["reasoning"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ConversationMessage {
+	    role: string;
+	    content: string;
+	    // Go type: time
+	    timestamp: any;
+	    provider?: string;
+	    model?: string;
+	    apiCall?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConversationMessage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.role = source["role"];
+	        this.content = source["content"];
+	        this.timestamp = this.convertValues(source["timestamp"], null);
+	        this.provider = source["provider"];
+	        this.model = source["model"];
+	        this.apiCall = source["apiCall"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Conversation {
+	    id: string;
+	    title: string;
+	    // Go type: time
+	    createdAt: any;
+	    // Go type: time
+	    updatedAt: any;
+	    messages: ConversationMessage[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Conversation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	        this.updatedAt = this.convertValues(source["updatedAt"], null);
+	        this.messages = this.convertValues(source["messages"], ConversationMessage);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class ConversationSummary {
+	    id: string;
+	    title: string;
+	    // Go type: time
+	    updatedAt: any;
+	    turns: number;
+	    lastReply: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConversationSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.updatedAt = this.convertValues(source["updatedAt"], null);
+	        this.turns = source["turns"];
+	        this.lastReply = source["lastReply"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DiffHunkFailure {
+	    file: string;
+	    hunk: number;
+	    header?: string;
+	    reason: string;
+	    excerpt?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DiffHunkFailure(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.file = source["file"];
+	        this.hunk = source["hunk"];
+	        this.header = source["header"];
+	        this.reason = source["reason"];
+	        this.excerpt = source["excerpt"];
+	    }
+	}
+	export class DiffRepairAttempt {
+	    attempt: number;
+	    appliedHunks: number;
+	    failures: DiffHunkFailure[];
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DiffRepairAttempt(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.attempt = source["attempt"];
+	        this.appliedHunks = source["appliedHunks"];
+	        this.failures = this.convertValues(source["failures"], DiffHunkFailure);
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DiffRepairReport {
+	    succeeded: boolean;
+	    succeededAttempt: number;
+	    attempts: DiffRepairAttempt[];
+	    diff: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DiffRepairReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.succeeded = source["succeeded"];
+	        this.succeededAttempt = source["succeededAttempt"];
+	        this.attempts = this.convertValues(source["attempts"], DiffRepairAttempt);
+	        this.diff = source["diff"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FileNode {
+	    name: string;
+	    path: string;
+	    relPath: string;
+	    isDir: boolean;
+	    isSymlink?: boolean;
+	    size: number;
+	    modTime: number;
+	    language?: string;
+	    tokens: number;
+	    fileCount?: number;
+	    hasChildren?: boolean;
+	    isOversized?: boolean;
+	    children?: FileNode[];
+	    isGitignored: boolean;
+	    isCustomIgnored: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new FileNode(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.path = source["path"];
+	        this.relPath = source["relPath"];
+	        this.isDir = source["isDir"];
+	        this.isSymlink = source["isSymlink"];
+	        this.size = source["size"];
+	        this.modTime = source["modTime"];
+	        this.language = source["language"];
+	        this.tokens = source["tokens"];
+	        this.fileCount = source["fileCount"];
+	        this.hasChildren = source["hasChildren"];
+	        this.isOversized = source["isOversized"];
+	        this.children = this.convertValues(source["children"], FileNode);
+	        this.isGitignored = source["isGitignored"];
+	        this.isCustomIgnored = source["isCustomIgnored"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DirectoryPage {
+	    relDir: string;
+	    entries: FileNode[];
+	    total: number;
+	    nextCursor?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DirectoryPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.relDir = source["relDir"];
+	        this.entries = this.convertValues(source["entries"], FileNode);
+	        this.total = source["total"];
+	        this.nextCursor = source["nextCursor"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class FileSearchResult {
+	    relPath: string;
+	    name: string;
+	    isDir: boolean;
+	    score: number;
+	    positions: number[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FileSearchResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.relPath = source["relPath"];
+	        this.name = source["name"];
+	        this.isDir = source["isDir"];
+	        this.score = source["score"];
+	        this.positions = source["positions"];
+	    }
+	}
+	export class FinalPromptSection {
+	    name: string;
+	    chars: number;
+	    tokens: number;
+	    budget: number;
+	    overBudget: boolean;
+	    included: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new FinalPromptSection(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.chars = source["chars"];
+	        this.tokens = source["tokens"];
+	        this.budget = source["budget"];
+	        this.overBudget = source["overBudget"];
+	        this.included = source["included"];
+	    }
+	}
+	export class FinalPrompt {
+	    prompt: string;
+	    sections: FinalPromptSection[];
+	    totalTokens: number;
+	    provider: string;
+	    model: string;
+	    contextWindow: number;
+	    exceedsContextWindow: boolean;
+	    warnings: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FinalPrompt(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.prompt = source["prompt"];
+	        this.sections = this.convertValues(source["sections"], FinalPromptSection);
+	        this.totalTokens = source["totalTokens"];
+	        this.provider = source["provider"];
+	        this.model = source["model"];
+	        this.contextWindow = source["contextWindow"];
+	        this.exceedsContextWindow = source["exceedsContextWindow"];
+	        this.warnings = source["warnings"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PromptBudgets {
+	    task: number;
+	    rules: number;
+	    template: number;
+	    context: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PromptBudgets(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.task = source["task"];
+	        this.rules = source["rules"];
+	        this.template = source["template"];
+	        this.context = source["context"];
+	    }
+	}
+	export class FinalPromptRequest {
+	    templateId: string;
+	    task: string;
+	    rules: string;
+	    context: string;
+	    values?: Record<string, string>;
+	    budgets: PromptBudgets;
+	
+	    static createFrom(source: any = {}) {
+	        return new FinalPromptRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.templateId = source["templateId"];
+	        this.task = source["task"];
+	        this.rules = source["rules"];
+	        this.context = source["context"];
+	        this.values = source["values"];
+	        this.budgets = this.convertValues(source["budgets"], PromptBudgets);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class GrepLine {
+	    number: number;
+	    text: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GrepLine(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.number = source["number"];
+	        this.text = source["text"];
+	    }
+	}
+	export class GrepMatch {
+	    relPath: string;
+	    line: number;
+	    column: number;
+	    text: string;
+	    before?: GrepLine[];
+	    after?: GrepLine[];
+	
+	    static createFrom(source: any = {}) {
+	        return new GrepMatch(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.relPath = source["relPath"];
+	        this.line = source["line"];
+	        this.column = source["column"];
+	        this.text = source["text"];
+	        this.before = this.convertValues(source["before"], GrepLine);
+	        this.after = this.convertValues(source["after"], GrepLine);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class GrepOptions {
+	    literal: boolean;
+	    caseInsensitive: boolean;
+	    contextLines: number;
+	    maxMatches: number;
+	    pathPattern: string;
+	    addToSelection: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new GrepOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.literal = source["literal"];
+	        this.caseInsensitive = source["caseInsensitive"];
+	        this.contextLines = source["contextLines"];
+	        this.maxMatches = source["maxMatches"];
+	        this.pathPattern = source["pathPattern"];
+	        this.addToSelection = source["addToSelection"];
+	    }
+	}
+	export class GrepResult {
+	    matches: GrepMatch[];
+	    files: string[];
+	    filesSearched: number;
+	    truncated: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new GrepResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.matches = this.convertValues(source["matches"], GrepMatch);
+	        this.files = source["files"];
+	        this.filesSearched = source["filesSearched"];
+	        this.truncated = source["truncated"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class IgnoreRuleEvaluation {
+	    kind: string;
+	    source: string;
+	    file?: string;
+	    active: boolean;
+	    matched: boolean;
+	    pattern?: string;
+	    line?: number;
+	    negated?: boolean;
+	    decisive: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new IgnoreRuleEvaluation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.source = source["source"];
+	        this.file = source["file"];
+	        this.active = source["active"];
+	        this.matched = source["matched"];
+	        this.pattern = source["pattern"];
+	        this.line = source["line"];
+	        this.negated = source["negated"];
+	        this.decisive = source["decisive"];
+	    }
+	}
+	export class IgnoreExplanation {
 	    relPath: string;
 	    isDir: boolean;
-	    children?: FileNode[];
-	    isGitignored: boolean;
-	    isCustomIgnored: boolean;
+	    exists: boolean;
+	    evaluations: IgnoreRuleEvaluation[];
+	    gitignored: boolean;
+	    gitParent?: string;
+	    customIgnored: boolean;
+	    customParent?: string;
+	    included: boolean;
+	    excludedBy?: string;
+	    verdict: string;
+	    summary: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new FileNode(source);
+	        return new IgnoreExplanation(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.name = source["name"];
-	        this.path = source["path"];
 	        this.relPath = source["relPath"];
 	        this.isDir = source["isDir"];
-	        this.children = this.convertValues(source["children"], FileNode);
-	        this.isGitignored = source["isGitignored"];
-	        this.isCustomIgnored = source["isCustomIgnored"];
+	        this.exists = source["exists"];
+	        this.evaluations = this.convertValues(source["evaluations"], IgnoreRuleEvaluation);
+	        this.gitignored = source["gitignored"];
+	        this.gitParent = source["gitParent"];
+	        this.customIgnored = source["customIgnored"];
+	        this.customParent = source["customParent"];
+	        this.included = source["included"];
+	        this.excludedBy = source["excludedBy"];
+	        this.verdict = source["verdict"];
+	        this.summary = source["summary"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class IgnoreRuleChange {
+	    relPath: string;
+	    isDir: boolean;
+	    fileCount: number;
+	    pattern?: string;
+	    line?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new IgnoreRuleChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.relPath = source["relPath"];
+	        this.isDir = source["isDir"];
+	        this.fileCount = source["fileCount"];
+	        this.pattern = source["pattern"];
+	        this.line = source["line"];
+	    }
+	}
+	
+	export class IgnoreRulesPreview {
+	    hidden: IgnoreRuleChange[];
+	    revealed: IgnoreRuleChange[];
+	    hiddenFiles: number;
+	    revealedFiles: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new IgnoreRulesPreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hidden = this.convertValues(source["hidden"], IgnoreRuleChange);
+	        this.revealed = this.convertValues(source["revealed"], IgnoreRuleChange);
+	        this.hiddenFiles = source["hiddenFiles"];
+	        this.revealedFiles = source["revealedFiles"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -64,6 +829,35 @@ export namespace main {
 	        this.baseURL = source["baseURL"];
 	    }
 	}
+	export class LargeFileSettings {
+	    maxFileSizeBytes: number;
+	    policy: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LargeFileSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.maxFileSizeBytes = source["maxFileSizeBytes"];
+	        this.policy = source["policy"];
+	    }
+	}
+	export class PathSearchResult {
+	    paths: string[];
+	    truncated: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new PathSearchResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.paths = source["paths"];
+	        this.truncated = source["truncated"];
+	    }
+	}
+	
 	export class PromptHistoryItem {
 	    id: string;
 	    // Go type: time
@@ -72,6 +866,7 @@ export namespace main {
 	    constructedPrompt: string;
 	    response: string;
 	    apiCall?: string;
+	    conversationId?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new PromptHistoryItem(source);
@@ -85,6 +880,154 @@ export namespace main {
 	        this.constructedPrompt = source["constructedPrompt"];
 	        this.response = source["response"];
 	        this.apiCall = source["apiCall"];
+	        this.conversationId = source["conversationId"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PromptTemplateVersion {
+	    version: number;
+	    name: string;
+	    content: string;
+	    variables: TemplateVariable[];
+	    // Go type: time
+	    savedAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new PromptTemplateVersion(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.version = source["version"];
+	        this.name = source["name"];
+	        this.content = source["content"];
+	        this.variables = this.convertValues(source["variables"], TemplateVariable);
+	        this.savedAt = this.convertValues(source["savedAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TemplateVariable {
+	    name: string;
+	    type: string;
+	    description?: string;
+	    required?: boolean;
+	    default?: string;
+	    options?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TemplateVariable(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.description = source["description"];
+	        this.required = source["required"];
+	        this.default = source["default"];
+	        this.options = source["options"];
+	    }
+	}
+	export class PromptTemplate {
+	    id: string;
+	    name: string;
+	    description?: string;
+	    content: string;
+	    variables: TemplateVariable[];
+	    builtin: boolean;
+	    overrides: boolean;
+	    version: number;
+	    // Go type: time
+	    updatedAt: any;
+	    history?: PromptTemplateVersion[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PromptTemplate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.content = source["content"];
+	        this.variables = this.convertValues(source["variables"], TemplateVariable);
+	        this.builtin = source["builtin"];
+	        this.overrides = source["overrides"];
+	        this.version = source["version"];
+	        this.updatedAt = this.convertValues(source["updatedAt"], null);
+	        this.history = this.convertValues(source["history"], PromptTemplateVersion);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class RetrievalIndexStatus {
+	    model: string;
+	    files: number;
+	    chunks: number;
+	    updated: retrieval.UpdateStats;
+	
+	    static createFrom(source: any = {}) {
+	        return new RetrievalIndexStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.model = source["model"];
+	        this.files = source["files"];
+	        this.chunks = source["chunks"];
+	        this.updated = this.convertValues(source["updated"], retrieval.UpdateStats);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -105,6 +1048,26 @@ export namespace main {
 		    return a;
 		}
 	}
+	export class RetrievalSettings {
+	    enabled: boolean;
+	    baseURL: string;
+	    model: string;
+	    apiKey: string;
+	    prefilterTopK: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RetrievalSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.baseURL = source["baseURL"];
+	        this.model = source["model"];
+	        this.apiKey = source["apiKey"];
+	        this.prefilterTopK = source["prefilterTopK"];
+	    }
+	}
 
 }
 
@@ -113,6 +1076,7 @@ export namespace provider {
 	export class ModelInfo {
 	    name: string;
 	    description?: string;
+	    contextWindow?: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new ModelInfo(source);
@@ -122,6 +1086,48 @@ export namespace provider {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.name = source["name"];
 	        this.description = source["description"];
+	        this.contextWindow = source["contextWindow"];
+	    }
+	}
+
+}
+
+export namespace retrieval {
+	
+	export class Hit {
+	    relPath: string;
+	    score: number;
+	    startLine: number;
+	    endLine: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Hit(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.relPath = source["relPath"];
+	        this.score = source["score"];
+	        this.startLine = source["startLine"];
+	        this.endLine = source["endLine"];
+	    }
+	}
+	export class UpdateStats {
+	    embedded: number;
+	    removed: number;
+	    unchanged: number;
+	    chunks: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new UpdateStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.embedded = source["embedded"];
+	        this.removed = source["removed"];
+	        this.unchanged = source["unchanged"];
+	        this.chunks = source["chunks"];
 	    }
 	}
 
@@ -0,0 +1,564 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// diffApplyFuzz is how many context lines at each end of a hunk may be ignored when
+// the hunk does not match otherwise, like patch's fuzz factor.
+const diffApplyFuzz = 2
+
+var (
+	fileDiffStartRegex = regexp.MustCompile(`(?m)^diff --git `)
+	hunkHeaderRegex    = regexp.MustCompile(`^@@ .* @@`)
+	hunkRangeRegex     = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+)
+
+// gitFileDiff is the diff of one file.
+type gitFileDiff struct {
+	Block   string   // The trimmed diff text of the file
+	Header  []string // Lines before the first hunk
+	OldPath string   // Slash path without the "a/" prefix; empty for a created file
+	NewPath string   // Slash path without the "b/" prefix; empty for a deleted file
+	Hunks   []gitDiffHunk
+}
+
+// gitDiffHunk is one "@@" section of a file diff.
+type gitDiffHunk struct {
+	Header   string
+	Lines    []string // Lines after the header, up to the next hunk
+	OldStart int      // 1-based; 0 when the header has no line numbers
+}
+
+// Path returns the path the diff applies to.
+func (d gitFileDiff) Path() string {
+	if d.NewPath != "" {
+		return d.NewPath
+	}
+	return d.OldPath
+}
+
+// splitGitDiffBlocks cuts a diff into per-file blocks at "diff --git" lines. Without
+// any, the whole text is one block and found is false.
+func splitGitDiffBlocks(text string) (blocks []string, found bool) {
+	// Go's regex engine (RE2) does not support lookarounds like (?=), so the block
+	// start indices are found first and the text is split manually.
+	startIndices := fileDiffStartRegex.FindAllStringIndex(text, -1)
+	if len(startIndices) == 0 {
+		if strings.TrimSpace(text) != "" {
+			blocks = append(blocks, text)
+		}
+		return blocks, false
+	}
+	for i := 0; i < len(startIndices); i++ {
+		start := startIndices[i][0]
+		end := len(text)
+		if i+1 < len(startIndices) {
+			end = startIndices[i+1][0]
+		}
+		if block := strings.TrimSpace(text[start:end]); block != "" {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks, true
+}
+
+// parseGitDiff parses every file diff in text. Plain unified diffs without
+// "diff --git" lines are split at their "---"/"+++" header pairs.
+func parseGitDiff(text string) []gitFileDiff {
+	blocks, found := splitGitDiffBlocks(text)
+	if !found && len(blocks) == 1 {
+		blocks = splitUnifiedDiffBlocks(blocks[0])
+	}
+	files := make([]gitFileDiff, 0, len(blocks))
+	for _, block := range blocks {
+		files = append(files, parseGitFileDiff(block))
+	}
+	return files
+}
+
+func splitUnifiedDiffBlocks(text string) []string {
+	lines := strings.Split(text, "\n")
+	var blocks []string
+	start := -1
+	for i := 0; i+1 < len(lines); i++ {
+		if strings.HasPrefix(lines[i], "--- ") && strings.HasPrefix(lines[i+1], "+++ ") {
+			if start >= 0 {
+				blocks = append(blocks, strings.TrimSpace(strings.Join(lines[start:i], "\n")))
+			}
+			start = i
+		}
+	}
+	if start < 0 {
+		return []string{strings.TrimSpace(text)}
+	}
+	return append(blocks, strings.TrimSpace(strings.Join(lines[start:], "\n")))
+}
+
+// parseGitFileDiff splits one file block into its header and hunks.
+func parseGitFileDiff(block string) gitFileDiff {
+	d := gitFileDiff{Block: block}
+	lines := strings.Split(block, "\n")
+	firstHunk := len(lines)
+	for i, line := range lines {
+		if hunkHeaderRegex.MatchString(line) {
+			firstHunk = i
+			break
+		}
+	}
+	d.Header = lines[:firstHunk]
+
+	for _, line := range d.Header {
+		switch {
+		case strings.HasPrefix(line, "--- "):
+			d.OldPath = diffHeaderPath(strings.TrimPrefix(line, "--- "), "a/")
+		case strings.HasPrefix(line, "+++ "):
+			d.NewPath = diffHeaderPath(strings.TrimPrefix(line, "+++ "), "b/")
+		case strings.HasPrefix(line, "rename from "):
+			d.OldPath = normalizeRelativePath(strings.TrimPrefix(line, "rename from "))
+		case strings.HasPrefix(line, "rename to "):
+			d.NewPath = normalizeRelativePath(strings.TrimPrefix(line, "rename to "))
+		}
+	}
+	if d.OldPath == "" && d.NewPath == "" && len(lines) > 0 {
+		// No ---/+++ lines, e.g. a mode change: fall back to "diff --git a/x b/y".
+		d.OldPath = strings.TrimPrefix(getPathFromDiffHeader(lines[0]), "a/")
+		d.NewPath = d.OldPath
+	}
+
+	for start := firstHunk; start < len(lines); {
+		end := start + 1
+		for end < len(lines) && !hunkHeaderRegex.MatchString(lines[end]) {
+			end++
+		}
+		hunk := gitDiffHunk{Header: lines[start], Lines: lines[start+1 : end]}
+		if m := hunkRangeRegex.FindStringSubmatch(lines[start]); m != nil {
+			hunk.OldStart, _ = strconv.Atoi(m[1])
+		}
+		d.Hunks = append(d.Hunks, hunk)
+		start = end
+	}
+	return d
+}
+
+// diffHeaderPath reads the path of a "---" or "+++" line, dropping the a/ or b/
+// prefix and a trailing timestamp. /dev/null yields "".
+func diffHeaderPath(value, prefix string) string {
+	value, _, _ = strings.Cut(value, "\t")
+	value = strings.TrimSpace(value)
+	if value == "/dev/null" {
+		return ""
+	}
+	return normalizeRelativePath(strings.TrimPrefix(value, prefix))
+}
+
+// DiffHunkFailure describes a hunk that could not be applied.
+type DiffHunkFailure struct {
+	File    string `json:"file"`
+	Hunk    int    `json:"hunk"` // 1-based within the file; 0 when the whole file failed
+	Header  string `json:"header,omitempty"`
+	Reason  string `json:"reason"`
+	Excerpt string `json:"excerpt,omitempty"` // Numbered file lines where the hunk was expected
+}
+
+// diffApplyResult is the outcome of applying a diff in memory.
+type diffApplyResult struct {
+	Contents map[string]*string // New content per path; nil for deleted files
+	Applied  int                // Hunks applied
+	Failures []DiffHunkFailure
+	Diff     string // The applied changes as an exact git diff of the files
+}
+
+// cleanDiffPath cleans a slash path from a diff header. ok is false when the path
+// leaves the project root; "" (/dev/null) is kept.
+func cleanDiffPath(p string) (cleaned string, ok bool) {
+	if p == "" {
+		return "", true
+	}
+	cleaned = path.Clean(p)
+	return cleaned, filepath.IsLocal(filepath.FromSlash(cleaned))
+}
+
+// applyGitDiffInMemory applies files to the project at rootDir without writing
+// anything. Hunks match with growing tolerance: exactly, ignoring whitespace, and
+// finally ignoring up to diffApplyFuzz context lines at each end.
+func applyGitDiffInMemory(rootDir string, files []gitFileDiff) diffApplyResult {
+	result := diffApplyResult{Contents: make(map[string]*string)}
+	var diffs []string
+	for _, d := range files {
+		path := d.Path()
+		fail := func(reason string) {
+			result.Failures = append(result.Failures, DiffHunkFailure{File: path, Reason: reason})
+		}
+		oldPath, oldOK := cleanDiffPath(d.OldPath)
+		newPath, newOK := cleanDiffPath(d.NewPath)
+		if path == "" || !oldOK || !newOK {
+			fail("the diff does not name a file inside the project")
+			continue
+		}
+		d.OldPath, d.NewPath = oldPath, newPath
+		path = d.Path()
+
+		var original string
+		exists := false
+		if d.OldPath == "" {
+			if _, err := os.Stat(filepath.Join(rootDir, filepath.FromSlash(path))); err == nil {
+				fail("the diff creates a file that already exists")
+				continue
+			}
+		} else {
+			data, err := os.ReadFile(filepath.Join(rootDir, filepath.FromSlash(d.OldPath)))
+			switch {
+			case err == nil:
+				original, exists = string(data), true
+			case errors.Is(err, os.ErrNotExist):
+				fail("the file does not exist")
+				continue
+			default:
+				fail(fmt.Sprintf("the file cannot be read: %v", err))
+				continue
+			}
+		}
+
+		trailingNewline := !exists || original == "" || strings.HasSuffix(original, "\n")
+		var oldLines []string
+		if exists && original != "" {
+			oldLines = strings.Split(strings.TrimSuffix(original, "\n"), "\n")
+		}
+		if d.NewPath == "" {
+			result.Contents[path] = nil
+			result.Applied += len(d.Hunks)
+			diffs = append(diffs, formatGitFileDiff(d, oldLines, nil, nil, !trailingNewline))
+			continue
+		}
+
+		// origin maps each line to its index in oldLines, or -1 for an added line.
+		lines, origin := oldLines, make([]int, len(oldLines))
+		for i := range origin {
+			origin[i] = i
+		}
+		offset, floor := 0, 0
+		for i, hunk := range d.Hunks {
+			expected := floor
+			if hunk.OldStart > 0 {
+				expected = max(hunk.OldStart-1+offset, floor)
+			}
+			updated, updatedOrigin, end, ok := applyHunk(lines, origin, hunk, expected, floor)
+			if !ok {
+				result.Failures = append(result.Failures, DiffHunkFailure{
+					File:    path,
+					Hunk:    i + 1,
+					Header:  hunk.Header,
+					Reason:  "the hunk's context and removed lines were not found in the file",
+					Excerpt: numberedExcerpt(lines, expected, 6),
+				})
+				continue
+			}
+			offset += len(updated) - len(lines)
+			lines, origin, floor = updated, updatedOrigin, end
+			result.Applied++
+		}
+		content := strings.Join(lines, "\n")
+		if trailingNewline && len(lines) > 0 {
+			content += "\n"
+		}
+		result.Contents[path] = &content
+		if d.OldPath != "" && d.OldPath != path {
+			result.Contents[d.OldPath] = nil // Renamed
+		}
+		diffs = append(diffs, formatGitFileDiff(d, oldLines, lines, origin, !trailingNewline))
+	}
+	result.Diff = strings.Join(diffs, "")
+	return result
+}
+
+// diffContextLines is how many unchanged lines surround a regenerated hunk.
+const diffContextLines = 3
+
+// diffEdit is one line of a regenerated diff. oldPos and newPos count the lines of
+// each side before it.
+type diffEdit struct {
+	hunkLine
+	oldPos, newPos int
+}
+
+// formatGitFileDiff writes the change of d from old to updated as a git diff, with
+// the file's exact lines. origin maps each updated line to its index in old, or -1
+// for an added line; updated is nil for a deleted file. noEOL marks files without
+// a trailing newline.
+func formatGitFileDiff(d gitFileDiff, old, updated []string, origin []int, noEOL bool) string {
+	kept := make([]bool, len(old))
+	for _, o := range origin {
+		if o >= 0 {
+			kept[o] = true
+		}
+	}
+	var edits []diffEdit
+	add := func(kind byte, text string, i, j int) {
+		edits = append(edits, diffEdit{hunkLine{kind, text}, i, j})
+	}
+	for i, j := 0, 0; i < len(old) || j < len(updated); {
+		switch {
+		case i < len(old) && !kept[i]:
+			add('-', old[i], i, j)
+			i++
+		case origin[j] < 0:
+			add('+', updated[j], i, j)
+			j++
+		case noEOL && (i == len(old)-1) != (j == len(updated)-1):
+			// Only one side's copy of the line ends the file without a newline.
+			add('-', old[i], i, j)
+			add('+', updated[j], i+1, j)
+			i, j = i+1, j+1
+		default:
+			add(' ', updated[j], i, j)
+			i, j = i+1, j+1
+		}
+	}
+
+	var b strings.Builder
+	oldName, newName := d.OldPath, d.NewPath
+	if oldName == "" {
+		oldName = newName
+	}
+	if newName == "" {
+		newName = oldName
+	}
+	fmt.Fprintf(&b, "diff --git a/%s b/%s\n", oldName, newName)
+	modeLine := false
+	for _, line := range d.Header {
+		for _, prefix := range []string{"old mode ", "new mode ", "new file mode ", "deleted file mode "} {
+			if strings.HasPrefix(line, prefix) {
+				b.WriteString(line + "\n")
+				modeLine = modeLine || prefix == "new file mode " || prefix == "deleted file mode "
+			}
+		}
+	}
+	switch {
+	case d.OldPath == "" && !modeLine:
+		b.WriteString("new file mode 100644\n")
+	case d.NewPath == "" && !modeLine:
+		b.WriteString("deleted file mode 100644\n")
+	case d.OldPath != "" && d.NewPath != "" && d.OldPath != d.NewPath:
+		fmt.Fprintf(&b, "rename from %s\nrename to %s\n", d.OldPath, d.NewPath)
+	}
+	if !slices.ContainsFunc(edits, func(e diffEdit) bool { return e.kind != ' ' }) {
+		return b.String()
+	}
+	oldHeader, newHeader := "a/"+d.OldPath, "b/"+d.NewPath
+	if d.OldPath == "" {
+		oldHeader = "/dev/null"
+	}
+	if d.NewPath == "" {
+		newHeader = "/dev/null"
+	}
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldHeader, newHeader)
+
+	for start := 0; start < len(edits); {
+		first := start
+		for first < len(edits) && edits[first].kind == ' ' {
+			first++
+		}
+		if first == len(edits) {
+			break
+		}
+		last := first
+		for k := first + 1; k < len(edits); k++ {
+			if edits[k].kind == ' ' {
+				continue
+			}
+			if k-last-1 > 2*diffContextLines {
+				break
+			}
+			last = k
+		}
+		from, to := max(first-diffContextLines, start), min(last+diffContextLines+1, len(edits))
+		oldCount, newCount := 0, 0
+		for _, e := range edits[from:to] {
+			if e.kind != '+' {
+				oldCount++
+			}
+			if e.kind != '-' {
+				newCount++
+			}
+		}
+		oldStart, newStart := edits[from].oldPos, edits[from].newPos
+		if oldCount > 0 {
+			oldStart++
+		}
+		if newCount > 0 {
+			newStart++
+		}
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+		for _, e := range edits[from:to] {
+			b.WriteString(string(e.kind) + e.text + "\n")
+			lastOld := e.kind != '+' && e.oldPos == len(old)-1
+			lastNew := e.kind != '-' && e.newPos == len(updated)-1
+			if noEOL && (lastOld || lastNew) {
+				b.WriteString("\\ No newline at end of file\n")
+			}
+		}
+		start = to
+	}
+	return b.String()
+}
+
+type hunkLine struct {
+	kind byte // ' ', '-' or '+'
+	text string
+}
+
+// hunkBody reads the hunk's lines. A line without a marker is taken as context, as
+// models often drop the leading space of blank or unchanged lines.
+func hunkBody(hunk gitDiffHunk) []hunkLine {
+	lines := hunk.Lines
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1] // Blank lines between hunks or files
+	}
+	body := make([]hunkLine, 0, len(lines))
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, `\`):
+			// "\ No newline at end of file"
+		case line == "":
+			body = append(body, hunkLine{' ', ""})
+		case line[0] == ' ' || line[0] == '-' || line[0] == '+':
+			body = append(body, hunkLine{line[0], line[1:]})
+		default:
+			body = append(body, hunkLine{' ', line})
+		}
+	}
+	return body
+}
+
+// applyHunk applies hunk to lines at the match nearest to expected, not before
+// floor. It returns the new lines with their origin, carried over from origin and
+// -1 for added lines, and where the hunk's result ends in them.
+func applyHunk(lines []string, origin []int, hunk gitDiffHunk, expected, floor int) ([]string, []int, int, bool) {
+	body := hunkBody(hunk)
+	matchers := []func(a, b string) bool{
+		func(a, b string) bool { return a == b },
+		func(a, b string) bool { return strings.TrimRight(a, " \t\r") == strings.TrimRight(b, " \t\r") },
+		func(a, b string) bool {
+			return strings.Join(strings.Fields(a), " ") == strings.Join(strings.Fields(b), " ")
+		},
+	}
+	for fuzz := 0; fuzz <= diffApplyFuzz; fuzz++ {
+		trimmed, ok := trimHunkContext(body, fuzz)
+		if !ok {
+			break
+		}
+		var old []string
+		for _, l := range trimmed {
+			if l.kind != '+' {
+				old = append(old, l.text)
+			}
+		}
+		for _, match := range matchers {
+			pos, found := findLines(lines, old, expected, floor, match)
+			if !found {
+				continue
+			}
+			// Context keeps the file's own text; only removed and added lines change.
+			updated := append([]string{}, lines[:pos]...)
+			updatedOrigin := append([]int{}, origin[:pos]...)
+			i := pos
+			for _, l := range trimmed {
+				switch l.kind {
+				case ' ':
+					updated = append(updated, lines[i])
+					updatedOrigin = append(updatedOrigin, origin[i])
+					i++
+				case '-':
+					i++
+				case '+':
+					updated = append(updated, l.text)
+					updatedOrigin = append(updatedOrigin, -1)
+				}
+			}
+			end := len(updated)
+			updated = append(updated, lines[i:]...)
+			updatedOrigin = append(updatedOrigin, origin[i:]...)
+			return updated, updatedOrigin, end, true
+		}
+		if fuzz == 0 && len(old) == 0 {
+			break // Pure insertion without context: nothing to loosen
+		}
+	}
+	return nil, nil, 0, false
+}
+
+// trimHunkContext drops up to fuzz context lines from each end of body. ok is false
+// when fuzz removes nothing beyond what fuzz-1 did.
+func trimHunkContext(body []hunkLine, fuzz int) ([]hunkLine, bool) {
+	if fuzz == 0 {
+		return body, true
+	}
+	lead, trail := 0, 0
+	for lead < fuzz && lead < len(body) && body[lead].kind == ' ' {
+		lead++
+	}
+	for trail < fuzz && trail < len(body)-lead && body[len(body)-1-trail].kind == ' ' {
+		trail++
+	}
+	if lead < fuzz && trail < fuzz {
+		return nil, false // Already trimmed as far as possible at a lower fuzz
+	}
+	trimmed := body[lead : len(body)-trail]
+	for _, l := range trimmed {
+		if l.kind != '+' {
+			return trimmed, true
+		}
+	}
+	return nil, false // Nothing left to anchor the hunk
+}
+
+// findLines returns the start of the occurrence of want in lines nearest to
+// expected, at or after floor. An empty want matches at expected.
+func findLines(lines, want []string, expected, floor int, match func(a, b string) bool) (int, bool) {
+	if len(want) == 0 {
+		return min(max(expected, floor), len(lines)), true
+	}
+	best := -1
+	for pos := floor; pos+len(want) <= len(lines); pos++ {
+		ok := true
+		for i, w := range want {
+			if !match(lines[pos+i], w) {
+				ok = false
+				break
+			}
+		}
+		if ok && (best < 0 || abs(pos-expected) < abs(best-expected)) {
+			best = pos
+		}
+	}
+	return best, best >= 0
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// numberedExcerpt shows the lines within radius of center with 1-based numbers.
+func numberedExcerpt(lines []string, center, radius int) string {
+	if len(lines) == 0 {
+		return "(empty file)"
+	}
+	center = min(max(center, 0), len(lines)-1)
+	var b strings.Builder
+	for i := max(center-radius, 0); i <= min(center+radius, len(lines)-1); i++ {
+		fmt.Fprintf(&b, "%5d | %s\n", i+1, lines[i])
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gitApply applies diff to root with git apply, checking that the applier's diff
+// is one git accepts as is.
+func gitApply(t *testing.T, root, diff string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	cmd := exec.Command("git", "apply", "-")
+	cmd.Dir = root
+	cmd.Stdin = strings.NewReader(diff)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git apply: %v\n%s\ndiff:\n%s", err, out, diff)
+	}
+}
+
+func TestApplyGitDiffRejectsPathsOutsideRoot(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "project")
+	if err := os.MkdirAll(filepath.Join(root, "src"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(parent, "secret.txt"), []byte("secret\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, diff := range []string{
+		"--- a/src/../../secret.txt\n+++ b/src/../../secret.txt\n@@ -1 +1 @@\n-secret\n+leaked\n",
+		"--- /dev/null\n+++ b/../escape.txt\n@@ -0,0 +1 @@\n+created\n",
+		"diff --git a/src/a.go b/src/../../moved.go\nrename from src/a.go\nrename to src/../../moved.go\n",
+	} {
+		result := applyGitDiffInMemory(root, parseGitDiff(diff))
+		if len(result.Failures) != 1 || !strings.Contains(result.Failures[0].Reason, "inside the project") {
+			t.Errorf("failures for %q = %+v", diff, result.Failures)
+		}
+		if len(result.Contents) != 0 || result.Diff != "" {
+			t.Errorf("diff %q produced %v %q", diff, result.Contents, result.Diff)
+		}
+	}
+
+	// Paths that stay inside once cleaned are fine.
+	result := applyGitDiffInMemory(root, parseGitDiff("--- /dev/null\n+++ b/src/../docs/./new.md\n@@ -0,0 +1 @@\n+hello\n"))
+	if len(result.Failures) != 0 || result.Contents["docs/new.md"] == nil {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestApplyGitDiffRegeneratesExactDiff(t *testing.T) {
+	files := map[string]string{
+		"main.go":    "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hello\")  \n}\n\nfunc helper() int {\n\treturn 1\n}\n",
+		"old.txt":    "one\ntwo\n",
+		"gone.txt":   "bye\n",
+		"noeol.txt":  "first\nlast",
+		"spaced.txt": "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n",
+	}
+	root := writeProject(t, files)
+
+	// Drifted line numbers, missing trailing spaces and unmarked blank context
+	// lines all apply here, but not with git apply.
+	diff := strings.Join([]string{
+		"diff --git a/main.go b/main.go",
+		"--- a/main.go",
+		"+++ b/main.go",
+		"@@ -9,3 +9,3 @@",
+		" func main() {",
+		"-\tfmt.Println(\"hello\")",
+		"+\tfmt.Println(\"hello, world\")",
+		" }",
+		"",
+		"@@ -20,3 +20,3 @@",
+		" func helper() int {",
+		"-\treturn 1",
+		"+\treturn 2",
+		" }",
+		"diff --git a/old.txt b/new.txt",
+		"rename from old.txt",
+		"rename to new.txt",
+		"--- a/old.txt",
+		"+++ b/new.txt",
+		"@@ -1,2 +1,3 @@",
+		" one",
+		" two",
+		"+three",
+		"diff --git a/gone.txt b/gone.txt",
+		"deleted file mode 100644",
+		"--- a/gone.txt",
+		"+++ /dev/null",
+		"@@ -1 +0,0 @@",
+		"-bye",
+		"diff --git a/created.txt b/created.txt",
+		"new file mode 100644",
+		"--- /dev/null",
+		"+++ b/created.txt",
+		"@@ -0,0 +1,2 @@",
+		"+new",
+		"+file",
+		"diff --git a/noeol.txt b/noeol.txt",
+		"--- a/noeol.txt",
+		"+++ b/noeol.txt",
+		"@@ -1,2 +1,3 @@",
+		" first",
+		" last",
+		"+appended",
+		"diff --git a/spaced.txt b/spaced.txt",
+		"--- a/spaced.txt",
+		"+++ b/spaced.txt",
+		"@@ -1,3 +1,3 @@",
+		"-a",
+		"+A",
+		" b",
+		" c",
+		"@@ -11,3 +11,3 @@",
+		" k",
+		" l",
+		"-m",
+		"+M",
+	}, "\n")
+
+	result := applyGitDiffInMemory(root, parseGitDiff(diff))
+	if len(result.Failures) != 0 {
+		t.Fatalf("failures: %+v", result.Failures)
+	}
+	gitApply(t, root, result.Diff)
+
+	for path, content := range result.Contents {
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(path)))
+		switch {
+		case content == nil && !os.IsNotExist(err):
+			t.Errorf("%s still exists after git apply", path)
+		case content != nil && string(data) != *content:
+			t.Errorf("%s after git apply = %q, applier has %q", path, data, *content)
+		}
+	}
+	if got := *result.Contents["noeol.txt"]; got != "first\nlast\nappended" {
+		t.Errorf("noeol.txt = %q", got)
+	}
+	if strings.Count(result.Diff, "@@ -") != 7 {
+		t.Errorf("diff has %d hunks, want 7:\n%s", strings.Count(result.Diff, "@@ -"), result.Diff)
+	}
+}
+
+func TestRepairDiffReportsRegeneratedDiff(t *testing.T) {
+	root := writeProject(t, map[string]string{"a.txt": "x  \ny\n"})
+	report, err := repairDiff(t.Context(), nil, root, "--- a/a.txt\n+++ b/a.txt\n@@ -5,2 +5,2 @@\n x\n-y\n+z\n", 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Succeeded {
+		t.Fatalf("report = %+v", report)
+	}
+	want := "diff --git a/a.txt b/a.txt\n--- a/a.txt\n+++ b/a.txt\n@@ -1,2 +1,2 @@\n x  \n-y\n+z\n"
+	if report.Diff != want {
+		t.Errorf("diff = %q, want %q", report.Diff, want)
+	}
+	gitApply(t, root, report.Diff)
+}
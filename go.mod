@@ -9,6 +9,7 @@ require (
 
 require (
 	github.com/adrg/xdg v0.5.0
+	github.com/bmatcuk/doublestar/v4 v4.10.2
 	github.com/fsnotify/fsnotify v1.9.0
 	github.com/sabhiram/go-gitignore v0.0.0-20210923224102-525f6e181f06
 )
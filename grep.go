@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"shotgun_code/internal/walker"
+)
+
+const (
+	defaultGrepMaxMatches = 1000
+	maxGrepContextLines   = 20
+	maxGrepLineLength     = 500 // Longer lines are cut in results, e.g. minified bundles
+)
+
+// GrepOptions tunes GrepProject.
+type GrepOptions struct {
+	Literal         bool   `json:"literal"` // Match the pattern as plain text instead of a regular expression
+	CaseInsensitive bool   `json:"caseInsensitive"`
+	ContextLines    int    `json:"contextLines"`   // Lines shown before and after each match
+	MaxMatches      int    `json:"maxMatches"`     // 0 uses the default
+	PathPattern     string `json:"pathPattern"`    // Optional doublestar glob limiting which files are searched
+	AddToSelection  bool   `json:"addToSelection"` // Add every matching file to the current selection
+}
+
+// GrepLine is a numbered line of file content.
+type GrepLine struct {
+	Number int    `json:"number"` // 1-based
+	Text   string `json:"text"`
+}
+
+// GrepMatch is one line matching the pattern, with its surrounding lines.
+type GrepMatch struct {
+	RelPath string     `json:"relPath"` // Slash-separated, relative to the project root
+	Line    int        `json:"line"`    // 1-based
+	Column  int        `json:"column"`  // 1-based byte offset of the first match in the line
+	Text    string     `json:"text"`
+	Before  []GrepLine `json:"before,omitempty"`
+	After   []GrepLine `json:"after,omitempty"`
+}
+
+// GrepResult lists the matches of a GrepProject call.
+type GrepResult struct {
+	Matches       []GrepMatch `json:"matches"`
+	Files         []string    `json:"files"` // Files with at least one match, in tree order
+	FilesSearched int         `json:"filesSearched"`
+	Truncated     bool        `json:"truncated"` // MaxMatches was reached before the search finished
+}
+
+// GrepProject searches file contents under rootDir for pattern, an RE2 regular
+// expression unless opts.Literal is set. It skips what the tree hides or deselects through the active ignore
+// toggles and include mode, binary files and files above the per-file size ceiling.
+// With AddToSelection set, the matching files are sent to the frontend through the
+// "selectionAddPaths" event.
+func (a *App) GrepProject(rootDir, pattern string, opts GrepOptions) (GrepResult, error) {
+	result, selection, err := a.grepProject(rootDir, pattern, opts)
+	if len(selection) > 0 {
+		runtime.EventsEmit(a.ctx, "selectionAddPaths", selection)
+	}
+	return result, err
+}
+
+// grepProject runs GrepProject's search. selection lists the paths to add to the
+// frontend's selection, empty unless opts.AddToSelection is set.
+func (a *App) grepProject(rootDir, pattern string, opts GrepOptions) (result GrepResult, selection []string, err error) {
+	rootDir = strings.TrimSpace(rootDir)
+	if rootDir == "" {
+		return GrepResult{}, nil, errors.New("project root is required")
+	}
+	if pattern == "" {
+		return GrepResult{}, nil, errors.New("search pattern is required")
+	}
+	if opts.Literal {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if opts.CaseInsensitive {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return GrepResult{}, nil, fmt.Errorf("invalid regular expression: %w", err)
+	}
+	pathPattern := strings.TrimPrefix(filepath.ToSlash(strings.TrimSpace(opts.PathPattern)), "/")
+	if pathPattern != "" && !doublestar.ValidatePattern(pathPattern) {
+		return GrepResult{}, nil, fmt.Errorf("invalid path pattern %q", opts.PathPattern)
+	}
+	if opts.MaxMatches <= 0 {
+		opts.MaxMatches = defaultGrepMaxMatches
+	}
+	opts.ContextLines = min(max(opts.ContextLines, 0), maxGrepContextLines)
+
+	tree, err := walker.Walk(a.ctx, rootDir, a.listingWalkOptions(a.projectIgnoreStack(rootDir)))
+	if err != nil {
+		return GrepResult{}, nil, fmt.Errorf("failed to walk %s: %w", rootDir, err)
+	}
+
+	result = GrepResult{Matches: []GrepMatch{}, Files: []string{}}
+	sizeLimit := a.largeFileSettings().MaxFileSizeBytes
+	tree.Visit(func(entry *walker.Entry) error {
+		if !entry.HasContent() || entry.Marked(walker.FilterSize) {
+			return nil
+		}
+		if (a.useGitignore && entry.Marked(walker.FilterGitignore)) || (a.useCustomIgnore && entry.Marked(walker.FilterCustomIgnore)) {
+			return nil
+		}
+		rel := entry.SlashPath()
+		if pathPattern != "" && !doublestar.MatchUnvalidated(pathPattern, rel) {
+			return nil
+		}
+		content, _, err := readFileLimited(entry.Path, sizeLimit)
+		if err != nil || walker.IsBinary(content) {
+			return nil
+		}
+		result.FilesSearched++
+
+		matches, truncated := grepContent(rel, content, re, opts.ContextLines, opts.MaxMatches-len(result.Matches))
+		if len(matches) > 0 {
+			result.Matches = append(result.Matches, matches...)
+			result.Files = append(result.Files, rel)
+		}
+		if truncated {
+			result.Truncated = true
+			return errGrepLimitReached
+		}
+		return nil
+	})
+
+	if opts.AddToSelection {
+		selection = result.Files
+	}
+	return result, selection, nil
+}
+
+var errGrepLimitReached = errors.New("grep match limit reached")
+
+// grepContent returns up to limit matching lines of content. truncated is true when
+// more matches were left unreported.
+func grepContent(rel string, content []byte, re *regexp.Regexp, contextLines, limit int) (matches []GrepMatch, truncated bool) {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(content)+1)
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimSuffix(scanner.Text(), "\r"))
+	}
+
+	for i, line := range lines {
+		loc := re.FindStringIndex(line)
+		if loc == nil {
+			continue
+		}
+		if len(matches) == limit {
+			return matches, true
+		}
+		match := GrepMatch{RelPath: rel, Line: i + 1, Column: loc[0] + 1, Text: clipGrepLine(line)}
+		for j := max(i-contextLines, 0); j < i; j++ {
+			match.Before = append(match.Before, GrepLine{Number: j + 1, Text: clipGrepLine(lines[j])})
+		}
+		for j := i + 1; j <= i+contextLines && j < len(lines); j++ {
+			match.After = append(match.After, GrepLine{Number: j + 1, Text: clipGrepLine(lines[j])})
+		}
+		matches = append(matches, match)
+	}
+	return matches, false
+}
+
+// clipGrepLine cuts line to maxGrepLineLength bytes, moving the cut back to the
+// start of a rune it would split.
+func clipGrepLine(line string) string {
+	if len(line) <= maxGrepLineLength {
+		return line
+	}
+	cut := maxGrepLineLength
+	start := cut
+	for start > cut-utf8.UTFMax+1 && !utf8.RuneStart(line[start]) {
+		start--
+	}
+	// Invalid bytes at the cut are not a split rune and stay as they are.
+	if r, size := utf8.DecodeRuneInString(line[start:]); (r != utf8.RuneError || size > 1) && start+size > cut {
+		cut = start
+	}
+	return line[:cut] + "…"
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// grepFixture is a project with a match in an ignored, a binary and an oversized file.
+func grepFixture(t *testing.T) (*App, string) {
+	t.Helper()
+	root := writeProject(t, map[string]string{
+		"src/app.go":      "package app\n\nfunc fooBar() {}\n\nvar handler = fooBar\n",
+		"docs/notes.md":   "call foo.Bar()\nor fooXBar\n",
+		".gitignore":      "build/\n",
+		"build/gen.go":    "var gen = fooBar\n",
+		"assets/blob.bin": "fooBar\x00\x00binary",
+		"data/huge.txt":   strings.Repeat("fooBar padding line\n", 100),
+	})
+	a := &App{ctx: context.Background(), useGitignore: true}
+	a.settings.MaxFileSizeBytes = 1_000
+	return a, root
+}
+
+// grepLocations lists the matches as "relPath:line".
+func grepLocations(result GrepResult) []string {
+	var out []string
+	for _, m := range result.Matches {
+		out = append(out, fmt.Sprintf("%s:%d", m.RelPath, m.Line))
+	}
+	return out
+}
+
+func TestGrepProjectMatches(t *testing.T) {
+	a, root := grepFixture(t)
+	tests := []struct {
+		name    string
+		pattern string
+		opts    GrepOptions
+		want    []string
+	}{
+		{name: "regexp", pattern: "foo.Bar", want: []string{"docs/notes.md:1", "docs/notes.md:2"}},
+		{name: "literal", pattern: "foo.Bar", opts: GrepOptions{Literal: true}, want: []string{"docs/notes.md:1"}},
+		{name: "regexp class", pattern: `func \w+\(`, want: []string{"src/app.go:3"}},
+		{name: "case-insensitive", pattern: "FOOBAR", opts: GrepOptions{CaseInsensitive: true}, want: []string{"src/app.go:3", "src/app.go:5"}},
+		{name: "literal case-insensitive", pattern: "FOO.BAR(", opts: GrepOptions{Literal: true, CaseInsensitive: true}, want: []string{"docs/notes.md:1"}},
+		{name: "path pattern", pattern: "foo", opts: GrepOptions{PathPattern: "src/**"}, want: []string{"src/app.go:3", "src/app.go:5"}},
+		{name: "no match", pattern: "absent", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := a.grepProject(root, tt.pattern, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := grepLocations(result); !slices.Equal(got, tt.want) {
+				t.Errorf("matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrepProjectSkipsFiles(t *testing.T) {
+	a, root := grepFixture(t)
+	result, _, err := a.grepProject(root, "fooBar", GrepOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The binary, the oversized and the gitignored file are left out.
+	if want := []string{"src/app.go"}; !slices.Equal(result.Files, want) {
+		t.Errorf("files = %v, want %v", result.Files, want)
+	}
+	if result.FilesSearched != 3 {
+		t.Errorf("searched %d files, want .gitignore, docs/notes.md and src/app.go", result.FilesSearched)
+	}
+	if m := result.Matches[0]; m.Column != 6 || m.Text != "func fooBar() {}" {
+		t.Errorf("first match = column %d %q, want column 6 of the func line", m.Column, m.Text)
+	}
+
+	a.useGitignore = false
+	result, _, err = a.grepProject(root, "fooBar", GrepOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"build/gen.go", "src/app.go"}; !slices.Equal(result.Files, want) {
+		t.Errorf("files without gitignore = %v, want %v", result.Files, want)
+	}
+}
+
+func TestGrepProjectContextLines(t *testing.T) {
+	root := writeProject(t, map[string]string{"list.txt": "one\ntwo\nthree\nfour\nfive\nsix\n"})
+	a := &App{ctx: context.Background()}
+	result, _, err := a.grepProject(root, "two|six", GrepOptions{ContextLines: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Matches) != 2 {
+		t.Fatalf("got %d matches, want 2", len(result.Matches))
+	}
+	lines := func(ls []GrepLine) []string {
+		var out []string
+		for _, l := range ls {
+			out = append(out, fmt.Sprintf("%d:%s", l.Number, l.Text))
+		}
+		return out
+	}
+	checks := []struct {
+		name      string
+		got, want []string
+	}{
+		{"before two", lines(result.Matches[0].Before), []string{"1:one"}},
+		{"after two", lines(result.Matches[0].After), []string{"3:three", "4:four"}},
+		{"before six", lines(result.Matches[1].Before), []string{"4:four", "5:five"}},
+		{"after six", lines(result.Matches[1].After), nil},
+	}
+	for _, c := range checks {
+		if !slices.Equal(c.got, c.want) {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestGrepProjectMaxMatches(t *testing.T) {
+	a, root := grepFixture(t)
+	tests := []struct {
+		max       int
+		want      []string
+		truncated bool
+	}{
+		{max: 1, want: []string{"src/app.go:3"}, truncated: true},
+		{max: 2, want: []string{"src/app.go:3", "src/app.go:5"}, truncated: false},
+	}
+	for _, tt := range tests {
+		result, _, err := a.grepProject(root, "fooBar", GrepOptions{MaxMatches: tt.max})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := grepLocations(result); !slices.Equal(got, tt.want) || result.Truncated != tt.truncated {
+			t.Errorf("max %d: matches = %v truncated %v, want %v truncated %v", tt.max, got, result.Truncated, tt.want, tt.truncated)
+		}
+	}
+}
+
+func TestGrepProjectSelection(t *testing.T) {
+	a, root := grepFixture(t)
+	_, selection, err := a.grepProject(root, "foo", GrepOptions{AddToSelection: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"docs/notes.md", "src/app.go"}; !slices.Equal(selection, want) {
+		t.Errorf("selection = %v, want %v", selection, want)
+	}
+	if _, selection, _ = a.grepProject(root, "foo", GrepOptions{}); selection != nil {
+		t.Errorf("selection without AddToSelection = %v, want none", selection)
+	}
+}
+
+func TestClipGrepLine(t *testing.T) {
+	pad := strings.Repeat("a", maxGrepLineLength-1)
+	tests := []struct {
+		name, line, want string
+	}{
+		{"short", "short line", "short line"},
+		{"ascii", pad + "bc", pad + "b…"},
+		{"split rune", pad + "é tail", pad + "…"},
+		{"rune before cut", pad[1:] + "é tail", pad[1:] + "é…"},
+		{"invalid bytes kept", "\xff" + pad + "\x80tail", "\xff" + pad + "…"},
+		{"invalid byte at cut", pad + "\x80\x80tail", pad + "\x80…"},
+	}
+	for _, tt := range tests {
+		if got := clipGrepLine(tt.line); got != tt.want {
+			t.Errorf("%s: clipGrepLine = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
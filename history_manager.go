@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,6 +10,8 @@ import (
 	"sync"
 	"time"
 
+	"shotgun_code/internal/llm/provider"
+
 	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
@@ -19,10 +22,12 @@ type PromptHistoryItem struct {
 	ConstructedPrompt string    `json:"constructedPrompt"`
 	Response          string    `json:"response"`
 	APICall           string    `json:"apiCall,omitempty"`
+	ConversationID    string    `json:"conversationId,omitempty"` // Set for turns of a conversation
 }
 
 type PromptHistory struct {
-	Items []PromptHistoryItem `json:"items"`
+	Items         []PromptHistoryItem `json:"items"`
+	Conversations []Conversation      `json:"conversations,omitempty"` // Newest first
 }
 
 type HistoryManager struct {
@@ -30,6 +35,8 @@ type HistoryManager struct {
 	historyPath string
 	history     PromptHistory
 	mu          sync.Mutex
+	saving      sync.WaitGroup       // Background saves in flight
+	turnLocks   map[string]*turnLock // Per conversation, held while a turn is in flight
 }
 
 func NewHistoryManager(app *App) *HistoryManager {
@@ -97,29 +104,32 @@ func (hm *HistoryManager) SaveHistory() error {
 }
 
 func (hm *HistoryManager) AddItem(userTask, constructedPrompt, response, apiCall string) PromptHistoryItem {
+	return hm.addItem(PromptHistoryItem{UserTask: userTask, ConstructedPrompt: constructedPrompt, Response: response, APICall: apiCall})
+}
+
+func (hm *HistoryManager) addItem(item PromptHistoryItem) PromptHistoryItem {
 	hm.mu.Lock()
 	// Generate simple ID based on timestamp
 	now := time.Now()
-	item := PromptHistoryItem{
-		ID:                fmt.Sprintf("%d", now.UnixNano()),
-		Timestamp:         now,
-		UserTask:          userTask,
-		ConstructedPrompt: constructedPrompt,
-		Response:          response,
-		APICall:           apiCall,
-	}
+	item.ID = fmt.Sprintf("%d", now.UnixNano())
+	item.Timestamp = now
 	// Prepend to keep newest first
 	hm.history.Items = append([]PromptHistoryItem{item}, hm.history.Items...)
 	hm.mu.Unlock()
 
-	// Save asynchronously to avoid blocking UI too much
+	hm.saveInBackground()
+	return item
+}
+
+// saveInBackground saves the history asynchronously to avoid blocking the UI.
+func (hm *HistoryManager) saveInBackground() {
+	hm.saving.Add(1)
 	go func() {
+		defer hm.saving.Done()
 		if err := hm.SaveHistory(); err != nil {
 			wailsRuntime.LogError(hm.app.ctx, "Failed to save history: "+err.Error())
 		}
 	}()
-
-	return item
 }
 
 func (hm *HistoryManager) GetItems() []PromptHistoryItem {
@@ -131,9 +141,14 @@ func (hm *HistoryManager) GetItems() []PromptHistoryItem {
 	return items
 }
 
+// Clear removes the prompt history items and the conversations.
 func (hm *HistoryManager) Clear() error {
 	hm.mu.Lock()
 	hm.history.Items = []PromptHistoryItem{}
+	hm.history.Conversations = nil
+	for id := range hm.turnLocks {
+		hm.dropTurnLock(id)
+	}
 	hm.mu.Unlock()
 	return hm.SaveHistory()
 }
@@ -153,8 +168,14 @@ func (a *App) ExecuteLLMPrompt(userTask, finalPrompt string) (PromptHistoryItem,
 
 	wailsRuntime.LogInfof(a.ctx, "Executing LLM prompt via %s (%s)...", cfg.Provider, cfg.Model)
 
+	return a.executePrompt(a.ctx, providerInstance, userTask, finalPrompt)
+}
+
+// executePrompt sends finalPrompt to llm and records the exchange in the history,
+// failed calls included.
+func (a *App) executePrompt(ctx context.Context, llm provider.LLMProvider, userTask, finalPrompt string) (PromptHistoryItem, error) {
 	// Use provider.Generate. Note: we don't have streaming here yet, so it waits for full response.
-	response, apiCall, err := providerInstance.Generate(a.ctx, finalPrompt)
+	response, apiCall, err := llm.Generate(ctx, finalPrompt)
 
 	var historyItem PromptHistoryItem
 	if a.historyManager != nil {
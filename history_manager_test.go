@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"shotgun_code/internal/llm/provider"
+)
+
+// echoProvider answers a prompt by quoting it, standing in for a vendor while
+// cassettes are recorded.
+type echoProvider struct{}
+
+func (echoProvider) ListModels(context.Context) ([]provider.ModelInfo, error) { return nil, nil }
+
+func (echoProvider) Generate(_ context.Context, prompt string) (string, string, error) {
+	return "answer to " + prompt, "echo call", nil
+}
+
+func (echoProvider) Chat(_ context.Context, messages []provider.Message) (string, string, error) {
+	return "answer to " + messages[len(messages)-1].Content, "echo call", nil
+}
+
+// newReplayFor records prompts through echoProvider and returns a replay provider
+// serving them.
+func newReplayFor(t *testing.T, prompts ...string) provider.LLMProvider {
+	t.Helper()
+	dir := t.TempDir()
+	rec, err := provider.NewRecorder(echoProvider{}, provider.Config{Provider: "echo", CassetteDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, prompt := range prompts {
+		if _, _, err := rec.Generate(context.Background(), prompt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	llm, err := provider.Factory(provider.Config{Provider: "replay", CassetteDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return llm
+}
+
+func newTestHistoryApp(t *testing.T) *App {
+	t.Helper()
+	a := &App{ctx: context.Background()}
+	a.historyManager = NewHistoryManager(a)
+	a.historyManager.historyPath = filepath.Join(t.TempDir(), "prompt_history.json")
+	t.Cleanup(a.historyManager.saving.Wait)
+	return a
+}
+
+func TestExecutePromptReplaysCassette(t *testing.T) {
+	a := newTestHistoryApp(t)
+	llm := newReplayFor(t, "final prompt")
+
+	item, err := a.executePrompt(context.Background(), llm, "task", "final prompt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Response != "answer to final prompt" || item.APICall != "echo call" || item.UserTask != "task" {
+		t.Errorf("history item = %+v", item)
+	}
+	if items := a.historyManager.GetItems(); len(items) != 1 || items[0].ID != item.ID {
+		t.Errorf("history = %+v", items)
+	}
+}
+
+func TestExecutePromptRecordsFailures(t *testing.T) {
+	a := newTestHistoryApp(t)
+	llm := newReplayFor(t, "final prompt")
+
+	if _, err := a.executePrompt(context.Background(), llm, "task", "unrecorded prompt"); err == nil {
+		t.Fatal("an unrecorded prompt was answered")
+	}
+	items := a.historyManager.GetItems()
+	if len(items) != 1 || items[0].ConstructedPrompt != "unrecorded prompt" || items[0].Response == "" {
+		t.Errorf("history = %+v", items)
+	}
+}
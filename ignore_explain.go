@@ -0,0 +1,282 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"shotgun_code/internal/ignore"
+	"shotgun_code/internal/walker"
+)
+
+const customIgnoreRulesSource = "custom ignore rules (ignore.glob)"
+
+// Verdicts reported by ExplainIgnore, from the strongest to the weakest.
+const (
+	ignoreVerdictNotIncluded   = "not-included"   // Hidden by include mode: not listed at all
+	ignoreVerdictCustomParent  = "custom-parent"  // Inside a folder matched by custom rules: not listed
+	ignoreVerdictCustomIgnored = "custom-ignored" // Listed, deselected by custom rules
+	ignoreVerdictGitParent     = "git-parent"     // Listed, deselected because a parent folder is git-ignored
+	ignoreVerdictGitignored    = "gitignored"     // Listed, deselected by git ignore rules
+	ignoreVerdictExcluded      = "excluded"       // Listed, deselected by hand in the file tree
+	ignoreVerdictVisible       = "visible"
+)
+
+// IgnoreRuleEvaluation is one rule source consulted for a path.
+type IgnoreRuleEvaluation struct {
+	Kind     string `json:"kind"`           // "gitignore", "custom", "include" or "excluded"
+	Source   string `json:"source"`         // e.g. "frontend/.gitignore", ".git/info/exclude"
+	File     string `json:"file,omitempty"` // File the rules came from, if any
+	Active   bool   `json:"active"`         // Whether the matching toggle is switched on
+	Matched  bool   `json:"matched"`
+	Pattern  string `json:"pattern,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Negated  bool   `json:"negated,omitempty"`
+	Decisive bool   `json:"decisive"` // This source decided the outcome for its kind
+}
+
+// IgnoreExplanation describes why a path is or is not shown and selected.
+type IgnoreExplanation struct {
+	RelPath       string                 `json:"relPath"`
+	IsDir         bool                   `json:"isDir"`
+	Exists        bool                   `json:"exists"`
+	Evaluations   []IgnoreRuleEvaluation `json:"evaluations"`
+	Gitignored    bool                   `json:"gitignored"`
+	GitParent     string                 `json:"gitParent,omitempty"`    // Ignored ancestor folder, per git rules
+	CustomIgnored bool                   `json:"customIgnored"`          // Matched by custom rules itself
+	CustomParent  string                 `json:"customParent,omitempty"` // Ancestor folder matched by custom rules
+	Included      bool                   `json:"included"`               // False when include mode hides the path
+	ExcludedBy    string                 `json:"excludedBy,omitempty"`   // Entry of excludedPaths covering the path
+	Verdict       string                 `json:"verdict"`
+	Summary       string                 `json:"summary"`
+}
+
+// ExplainIgnore reports every ignore rule source evaluated for relPath, the matching
+// pattern and line number in each, and the final verdict used by the file tree.
+// excludedPaths are the paths deselected in the tree, as sent for context generation.
+func (a *App) ExplainIgnore(rootDir, relPath string, excludedPaths []string) (IgnoreExplanation, error) {
+	rootDir = strings.TrimSpace(rootDir)
+	if rootDir == "" {
+		return IgnoreExplanation{}, errors.New("project root is required")
+	}
+	rel := normalizeRelativePath(relPath)
+	if rel == "" {
+		return IgnoreExplanation{}, errors.New("a path inside the project is required")
+	}
+
+	result := IgnoreExplanation{RelPath: rel, IsDir: strings.HasSuffix(filepath.ToSlash(relPath), "/"), Included: true}
+	if info, err := os.Stat(filepath.Join(rootDir, filepath.FromSlash(rel))); err == nil {
+		result.Exists = true
+		result.IsDir = info.IsDir()
+	}
+
+	// Git rules: every .gitignore from the repository root down, then info/exclude and core.excludesFile.
+	gitExplanation := a.projectIgnoreStack(rootDir).Explain(rel, result.IsDir)
+	for _, eval := range gitExplanation.Evaluations {
+		result.Evaluations = append(result.Evaluations, ruleEvaluation("gitignore", a.useGitignore, eval))
+	}
+	result.Gitignored = gitExplanation.Ignored
+	result.GitParent = gitExplanation.IgnoredParent
+
+	// Custom rules: the tree does not expand folders they match, so an ancestor match hides the path.
+	if custom := a.currentCustomIgnorePatterns; custom != nil {
+		for dir := path.Dir(rel); dir != "."; dir = path.Dir(dir) {
+			if custom.MatchesPath(dir + "/") {
+				result.CustomParent = dir
+			}
+		}
+		candidate := rel
+		if result.IsDir {
+			candidate += "/"
+		}
+		eval := custom.Explain(candidate)
+		result.Evaluations = append(result.Evaluations, ruleEvaluation("custom", a.useCustomIgnore, eval))
+		result.CustomIgnored = eval.Rule != nil && !eval.Rule.Negate
+	}
+
+	// Include mode: files must match a pattern, folders must contain a matching file
+	// that the tree would list.
+	if include := a.currentIncludePatterns; include != nil {
+		matched := include.MatchesFile(rel)
+		if result.IsDir {
+			matched = a.dirHasIncludedFiles(rootDir, rel, include)
+		}
+		result.Evaluations = append(result.Evaluations, IgnoreRuleEvaluation{
+			Kind:     "include",
+			Source:   "include patterns",
+			Active:   a.useIncludePatterns,
+			Matched:  matched,
+			Decisive: !matched,
+		})
+		result.Included = matched || !a.useIncludePatterns
+	}
+
+	// Selection: the path or a folder above it is deselected in the file tree.
+	if len(excludedPaths) > 0 {
+		for _, excluded := range excludedPaths {
+			excluded = normalizeRelativePath(excluded)
+			if excluded != "" && (rel == excluded || strings.HasPrefix(rel, excluded+"/")) {
+				result.ExcludedBy = excluded
+				break
+			}
+		}
+		result.Evaluations = append(result.Evaluations, IgnoreRuleEvaluation{
+			Kind:     "excluded",
+			Source:   "file tree selection",
+			Active:   true,
+			Matched:  result.ExcludedBy != "",
+			Pattern:  result.ExcludedBy,
+			Decisive: result.ExcludedBy != "",
+		})
+	}
+
+	result.Verdict, result.Summary = ignoreVerdict(result, a.useGitignore, a.useCustomIgnore)
+	return result, nil
+}
+
+func ruleEvaluation(kind string, active bool, eval ignore.Evaluation) IgnoreRuleEvaluation {
+	out := IgnoreRuleEvaluation{
+		Kind:     kind,
+		Source:   eval.Source,
+		File:     eval.File,
+		Active:   active,
+		Matched:  eval.Rule != nil,
+		Decisive: eval.Decisive,
+	}
+	if eval.Rule != nil {
+		out.Pattern = eval.Rule.Pattern
+		out.Line = eval.Rule.Line
+		out.Negated = eval.Rule.Negate
+	}
+	return out
+}
+
+func ignoreVerdict(e IgnoreExplanation, useGitignore, useCustomIgnore bool) (string, string) {
+	switch {
+	case !e.Included:
+		return ignoreVerdictNotIncluded, "Hidden: include mode is on and no include pattern selects this path."
+	case e.CustomParent != "" && useCustomIgnore:
+		return ignoreVerdictCustomParent, fmt.Sprintf("Hidden: parent folder %q matches a custom ignore rule and is not expanded.", e.CustomParent)
+	case e.CustomIgnored && useCustomIgnore:
+		return ignoreVerdictCustomIgnored, "Listed but deselected by a custom ignore rule."
+	case e.GitParent != "" && useGitignore:
+		return ignoreVerdictGitParent, fmt.Sprintf("Listed but deselected: parent folder %q is ignored by git rules.", e.GitParent)
+	case e.Gitignored && useGitignore:
+		return ignoreVerdictGitignored, "Listed but deselected by a git ignore rule."
+	case e.ExcludedBy == e.RelPath:
+		return ignoreVerdictExcluded, "Listed but deselected in the file tree."
+	case e.ExcludedBy != "":
+		return ignoreVerdictExcluded, fmt.Sprintf("Listed but deselected: parent folder %q is deselected in the file tree.", e.ExcludedBy)
+	default:
+		return ignoreVerdictVisible, "Not hidden by any active ignore rule."
+	}
+}
+
+// dirHasIncludedFiles reports whether the file tree would list relDir under include
+// mode, walking with the tree's own filters so ignored and pruned files do not count.
+func (a *App) dirHasIncludedFiles(rootDir, relDir string, include *includeMatcher) bool {
+	opts := a.listingWalkOptions(a.projectIgnoreStack(rootDir))
+	opts.Filters = append(opts.Filters, walker.MatchFiles(includeFilterName, include.MatchesFile))
+	opts.PruneEmptyDirs = true
+	entries, err := walker.ReadDir(a.ctx, rootDir, relDir, opts)
+	return err == nil && len(entries) > 0
+}
+
+// IgnoreRuleChange is a path whose custom-ignore verdict a proposed rule set would flip.
+// Paths below a changed folder are folded into it and counted in FileCount.
+type IgnoreRuleChange struct {
+	RelPath   string `json:"relPath"`
+	IsDir     bool   `json:"isDir"`
+	FileCount int    `json:"fileCount"`         // Files at or below RelPath that change
+	Pattern   string `json:"pattern,omitempty"` // Proposed rule deciding the new verdict, if any
+	Line      int    `json:"line,omitempty"`
+}
+
+// IgnoreRulesPreview lists what a proposed custom rule set would hide or reveal.
+type IgnoreRulesPreview struct {
+	Hidden        []IgnoreRuleChange `json:"hidden"`
+	Revealed      []IgnoreRuleChange `json:"revealed"`
+	HiddenFiles   int                `json:"hiddenFiles"`
+	RevealedFiles int                `json:"revealedFiles"`
+}
+
+// PreviewCustomIgnoreRules compares the current custom ignore rules with proposedRules
+// over the project at rootDir without saving anything. Call it before SetCustomIgnoreRules.
+func (a *App) PreviewCustomIgnoreRules(rootDir, proposedRules string) (IgnoreRulesPreview, error) {
+	rootDir = strings.TrimSpace(rootDir)
+	if rootDir == "" {
+		return IgnoreRulesPreview{}, errors.New("project root is required")
+	}
+	current := a.currentCustomIgnorePatterns
+	proposed := ignore.ParseRules(customIgnoreRulesSource, "", proposedRules)
+
+	// Walk everything: folders hidden by either rule set must be visited to count their files.
+	tree, err := walker.Walk(a.ctx, rootDir, walker.Options{})
+	if err != nil {
+		return IgnoreRulesPreview{}, fmt.Errorf("failed to walk %s: %w", rootDir, err)
+	}
+
+	var preview IgnoreRulesPreview
+	var hidden, revealed []*IgnoreRuleChange
+	var visit func(entry *walker.Entry, oldHidden, newHidden bool, hideGroup, revealGroup *IgnoreRuleChange)
+	visit = func(entry *walker.Entry, oldHidden, newHidden bool, hideGroup, revealGroup *IgnoreRuleChange) {
+		candidate := entry.SlashPath()
+		if entry.IsDir {
+			candidate += "/"
+		}
+		oldHidden = oldHidden || current.MatchesPath(candidate)
+		newRule := proposed.Match(candidate)
+		newHidden = newHidden || (newRule != nil && !newRule.Negate)
+
+		if oldHidden != newHidden {
+			group := &hideGroup
+			if oldHidden {
+				group = &revealGroup
+			}
+			if *group == nil {
+				change := &IgnoreRuleChange{RelPath: entry.SlashPath(), IsDir: entry.IsDir}
+				if newRule != nil {
+					change.Pattern = newRule.Pattern
+					change.Line = newRule.Line
+				}
+				if newHidden {
+					hidden = append(hidden, change)
+				} else {
+					revealed = append(revealed, change)
+				}
+				*group = change
+			}
+			if !entry.IsDir {
+				(*group).FileCount++
+				if newHidden {
+					preview.HiddenFiles++
+				} else {
+					preview.RevealedFiles++
+				}
+			}
+		}
+		for _, child := range entry.Children {
+			visit(child, oldHidden, newHidden, hideGroup, revealGroup)
+		}
+	}
+	for _, child := range tree.Children {
+		visit(child, false, false, nil, nil)
+	}
+
+	preview.Hidden = sortedRuleChanges(hidden)
+	preview.Revealed = sortedRuleChanges(revealed)
+	return preview, nil
+}
+
+func sortedRuleChanges(changes []*IgnoreRuleChange) []IgnoreRuleChange {
+	out := make([]IgnoreRuleChange, 0, len(changes))
+	for _, change := range changes {
+		out = append(out, *change)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].RelPath < out[j].RelPath })
+	return out
+}
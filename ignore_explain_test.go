@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"shotgun_code/internal/ignore"
+)
+
+func TestExplainIgnoreVerdicts(t *testing.T) {
+	root := writeProject(t, map[string]string{
+		".gitignore":       "*.log\n",
+		"src/main.go":      "package main\n",
+		"src/debug.log":    "log\n",
+		"logs/app.log":     "log\n",
+		"docs/readme.md":   "# docs\n",
+		"vendor/dep/a.go":  "package dep\n",
+		"generated/gen.go": "package generated\n",
+	})
+	a := &App{ctx: context.Background(), useGitignore: true, useCustomIgnore: true}
+	a.currentCustomIgnorePatterns = ignore.ParseRules(customIgnoreRulesSource, "", "vendor/\n")
+	matcher, err := compileIncludePatterns("**/*.go\n**/*.log\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.useIncludePatterns, a.currentIncludePatterns = true, matcher
+	excluded := []string{"generated", "src/debug.log"}
+
+	tests := []struct {
+		relPath, want string
+	}{
+		{"src/main.go", ignoreVerdictVisible},
+		{"src/debug.log", ignoreVerdictGitignored},
+		{"generated/gen.go", ignoreVerdictExcluded},
+		{"generated", ignoreVerdictExcluded},
+		{"docs/readme.md", ignoreVerdictNotIncluded},
+		{"docs", ignoreVerdictNotIncluded},
+		// Only gitignored files match the include patterns, which still lists the folder.
+		{"logs", ignoreVerdictVisible},
+		{"vendor/dep", ignoreVerdictCustomParent},
+	}
+	for _, tt := range tests {
+		got, err := a.ExplainIgnore(root, tt.relPath, excluded)
+		if err != nil {
+			t.Fatalf("ExplainIgnore(%s): %v", tt.relPath, err)
+		}
+		if got.Verdict != tt.want {
+			t.Errorf("ExplainIgnore(%s) verdict = %s (%s), want %s", tt.relPath, got.Verdict, got.Summary, tt.want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// includeMatcher holds the compiled include globs. When include mode is active,
+// only files matching at least one pattern take part in the tree and the context.
+// Patterns use doublestar semantics ("internal/**/*.go", "api/*.proto") and are
+// matched against slash-separated paths relative to the project root.
+type includeMatcher struct {
+	patterns []string
+}
+
+// compileIncludePatterns parses one pattern per line. Empty lines and lines starting
+// with '#' are skipped. A trailing '/' selects everything below that directory.
+func compileIncludePatterns(text string) (*includeMatcher, error) {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	var patterns []string
+	var invalid []string
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pattern := strings.TrimPrefix(filepath.ToSlash(line), "./")
+		pattern = strings.TrimPrefix(pattern, "/")
+		if strings.HasSuffix(pattern, "/") {
+			pattern += "**"
+		}
+		if !doublestar.ValidatePattern(pattern) {
+			invalid = append(invalid, line)
+			continue
+		}
+		patterns = append(patterns, pattern)
+	}
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("invalid include patterns: %s", strings.Join(invalid, ", "))
+	}
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	return &includeMatcher{patterns: patterns}, nil
+}
+
+// MatchesFile reports whether the file at relPath is selected by any include pattern.
+func (m *includeMatcher) MatchesFile(relPath string) bool {
+	if m == nil {
+		return true
+	}
+	relPath = normalizeRelativePath(relPath)
+	for _, pattern := range m.patterns {
+		if doublestar.MatchUnvalidated(pattern, relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *App) compileIncludePatterns() error {
+	matcher, err := compileIncludePatterns(a.settings.IncludePatterns)
+	if err != nil {
+		return err
+	}
+	a.currentIncludePatterns = matcher
+	return nil
+}
+
+// activeIncludeMatcher returns the include matcher when include mode is enabled
+// and at least one pattern is configured, nil otherwise.
+func (a *App) activeIncludeMatcher() *includeMatcher {
+	if !a.useIncludePatterns {
+		return nil
+	}
+	return a.currentIncludePatterns
+}
+
+// GetIncludePatterns returns the include globs, one per line.
+func (a *App) GetIncludePatterns() string {
+	return a.settings.IncludePatterns
+}
+
+// SetIncludePatterns validates, saves and applies new include globs.
+// Invalid patterns are rejected and the previous set stays active.
+func (a *App) SetIncludePatterns(patterns string) error {
+	matcher, err := compileIncludePatterns(patterns)
+	if err != nil {
+		return err
+	}
+	a.settings.IncludePatterns = patterns
+	a.currentIncludePatterns = matcher
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save include patterns: %w", err)
+	}
+	runtime.LogInfo(a.ctx, "Include patterns saved successfully.")
+	a.notifySettingsChanged()
+	return nil
+}
+
+// SetUseIncludePatterns toggles include mode for the tree, context generation and auto-context.
+func (a *App) SetUseIncludePatterns(enabled bool) error {
+	a.useIncludePatterns = enabled
+	runtime.LogInfof(a.ctx, "App setting useIncludePatterns changed to: %v", enabled)
+	a.notifySettingsChanged()
+	return nil
+}
@@ -0,0 +1,158 @@
+// Package depgraph finds the project files a set of source files depends on, so a
+// selection can be expanded without asking a model. Each language has a resolver
+// that maps one file to the files defining what it uses.
+package depgraph
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultDepth is used when Options.Depth is not positive.
+const DefaultDepth = 1
+
+// Options tunes Expand.
+type Options struct {
+	// Depth is how many dependency hops to follow from the selected files.
+	Depth int
+	// Skip reports whether a project-relative, slash-separated path must not be
+	// added, e.g. because the tree hides it.
+	Skip func(relPath string) bool
+}
+
+// Dependency is a file added by Expand.
+type Dependency struct {
+	RelPath string `json:"relPath"` // Slash-separated, relative to the project root
+	Depth   int    `json:"depth"`   // 1 for direct dependencies of the selection
+	From    string `json:"from"`    // File that needs it
+	Reason  string `json:"reason"`  // e.g. "defines App" or "imported as ./util"
+}
+
+// Expansion is the result of Expand.
+type Expansion struct {
+	Added      []Dependency `json:"added"`
+	Unresolved []string     `json:"unresolved,omitempty"` // References that could not be mapped to a project file
+}
+
+// edge is one file-to-file dependency found by a resolver.
+type edge struct {
+	to     string // Slash-separated, relative to the project root
+	reason string
+}
+
+// resolver finds the dependencies of a single file.
+type resolver interface {
+	// handles reports whether the resolver understands the file.
+	handles(relPath string) bool
+	// resolve returns the files relPath depends on and references it could not resolve.
+	resolve(relPath string) (edges []edge, unresolved []string)
+}
+
+// Expand follows the dependencies of selected (project-relative paths) up to
+// opts.Depth hops and returns the files that are not selected yet.
+func Expand(ctx context.Context, root string, selected []string, opts Options) (Expansion, error) {
+	if opts.Depth <= 0 {
+		opts.Depth = DefaultDepth
+	}
+	p := newProject(root)
+	resolvers := []resolver{newGoResolver(p), newScriptResolver(p), newPythonResolver(p)}
+
+	known := make(map[string]bool)
+	frontier := make([]string, 0, len(selected))
+	for _, rel := range selected {
+		rel = cleanRel(rel)
+		if rel == "" || known[rel] {
+			continue
+		}
+		known[rel] = true
+		frontier = append(frontier, rel)
+	}
+
+	result := Expansion{Added: []Dependency{}}
+	unresolved := make(map[string]bool)
+	for depth := 1; depth <= opts.Depth && len(frontier) > 0; depth++ {
+		var next []string
+		for _, rel := range frontier {
+			if err := ctx.Err(); err != nil {
+				return Expansion{}, err
+			}
+			for _, r := range resolvers {
+				if !r.handles(rel) {
+					continue
+				}
+				edges, missing := r.resolve(rel)
+				for _, m := range missing {
+					unresolved[rel+": "+m] = true
+				}
+				for _, e := range edges {
+					if known[e.to] || (opts.Skip != nil && opts.Skip(e.to)) {
+						continue
+					}
+					known[e.to] = true
+					next = append(next, e.to)
+					result.Added = append(result.Added, Dependency{RelPath: e.to, Depth: depth, From: rel, Reason: e.reason})
+				}
+				break
+			}
+		}
+		frontier = next
+	}
+
+	for u := range unresolved {
+		result.Unresolved = append(result.Unresolved, u)
+	}
+	sort.Strings(result.Unresolved)
+	return result, nil
+}
+
+// project gives resolvers access to the files under root.
+type project struct {
+	root string
+}
+
+func newProject(root string) *project {
+	return &project{root: root}
+}
+
+func (p *project) abs(rel string) string {
+	return filepath.Join(p.root, filepath.FromSlash(rel))
+}
+
+func (p *project) readFile(rel string) ([]byte, error) {
+	return os.ReadFile(p.abs(rel))
+}
+
+func (p *project) isFile(rel string) bool {
+	info, err := os.Stat(p.abs(rel))
+	return err == nil && info.Mode().IsRegular()
+}
+
+// listDir returns the names of the regular files in the project directory rel.
+func (p *project) listDir(rel string) []string {
+	entries, err := os.ReadDir(p.abs(rel))
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		if e.Type().IsRegular() {
+			names = append(names, e.Name())
+		}
+	}
+	return names
+}
+
+// cleanRel normalizes a project-relative path to slash form; it returns "" for
+// paths that leave the project.
+func cleanRel(rel string) string {
+	rel = path.Clean(strings.ReplaceAll(strings.TrimSpace(rel), "\\", "/"))
+	rel = strings.TrimPrefix(rel, "./")
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, "../") || strings.HasPrefix(rel, "/") {
+		return ""
+	}
+	return rel
+}
@@ -0,0 +1,398 @@
+package depgraph
+
+import (
+	"bufio"
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// goResolver follows imports of packages in the same module and references to
+// package-level declarations and methods, mapping each used symbol to the file
+// declaring it.
+type goResolver struct {
+	p        *project
+	fset     *token.FileSet
+	files    map[string]*ast.File  // Parsed files by project-relative path; nil when unparsable
+	packages map[string]*goPackage // By directory, plus "#test" for indexes including _test.go files
+	modules  map[string]*goModule  // Module enclosing each directory looked up so far; nil outside any module
+}
+
+type goPackage struct {
+	name    string
+	decls   map[string]string     // Top-level symbol -> file declaring it
+	methods map[string][]goMethod // Method name -> its declarations
+}
+
+type goMethod struct {
+	recv string // Receiver type name
+	file string
+}
+
+type goModule struct {
+	dir  string // Project-relative directory holding go.mod, "" for the root
+	path string // Module path from the module directive
+}
+
+func newGoResolver(p *project) *goResolver {
+	return &goResolver{
+		p:        p,
+		fset:     token.NewFileSet(),
+		files:    make(map[string]*ast.File),
+		packages: make(map[string]*goPackage),
+		modules:  make(map[string]*goModule),
+	}
+}
+
+func (r *goResolver) handles(relPath string) bool {
+	return strings.HasSuffix(relPath, ".go")
+}
+
+func (r *goResolver) resolve(relPath string) ([]edge, []string) {
+	file := r.parse(relPath)
+	if file == nil {
+		return nil, nil
+	}
+	dir := path.Dir(relPath)
+	isTest := strings.HasSuffix(relPath, "_test.go")
+	local := r.pkg(dir, isTest)
+
+	// Import name -> package directory, for imports inside the enclosing module.
+	imports := make(map[string]string)
+	var dotImports []string
+	if mod := r.module(dir); mod != nil {
+		for _, spec := range file.Imports {
+			importPath, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
+			pkgDir, ok := mod.dirFor(importPath)
+			if !ok {
+				continue
+			}
+			name := path.Base(importPath)
+			if target := r.pkg(pkgDir, false); target.name != "" {
+				name = target.name
+			}
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			switch name {
+			case "_":
+			case ".":
+				dotImports = append(dotImports, pkgDir)
+			default:
+				imports[name] = pkgDir
+			}
+		}
+	}
+
+	symbols := make(map[string][]string) // Target file -> symbols it defines that relPath uses
+	var unresolved []string
+	use := func(target, symbol string) {
+		for _, s := range symbols[target] {
+			if s == symbol {
+				return
+			}
+		}
+		symbols[target] = append(symbols[target], symbol)
+	}
+
+	// Without type information a method call x.M resolves only when a single
+	// method named M exists in this package and the packages it imports.
+	methodPkgs := []*goPackage{local}
+	for _, pkgDir := range imports {
+		methodPkgs = append(methodPkgs, r.pkg(pkgDir, false))
+	}
+	for _, pkgDir := range dotImports {
+		methodPkgs = append(methodPkgs, r.pkg(pkgDir, false))
+	}
+	method := func(name string) (goMethod, bool) {
+		var found []goMethod
+		for _, pkg := range methodPkgs {
+			found = append(found, pkg.methods[name]...)
+		}
+		if len(found) != 1 {
+			return goMethod{}, false
+		}
+		return found[0], true
+	}
+
+	declared := declaredIdents(file)
+	selectorNames := make(map[*ast.Ident]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ImportSpec:
+			return false
+		case *ast.SelectorExpr:
+			selectorNames[n.Sel] = true
+			if x, ok := n.X.(*ast.Ident); ok && x.Obj == nil {
+				if pkgDir, ok := imports[x.Name]; ok {
+					selectorNames[x] = true
+					if target, ok := r.pkg(pkgDir, false).decls[n.Sel.Name]; ok {
+						use(target, x.Name+"."+n.Sel.Name)
+					} else {
+						unresolved = append(unresolved, x.Name+"."+n.Sel.Name)
+					}
+					return true
+				}
+			}
+			if m, ok := method(n.Sel.Name); ok && m.file != relPath {
+				use(m.file, m.recv+"."+n.Sel.Name)
+			}
+		case *ast.Ident:
+			// Obj is set for identifiers declared in this file, including locals
+			// shadowing a package-level name declared elsewhere.
+			if selectorNames[n] || declared[n] || n.Obj != nil {
+				return true
+			}
+			if target, ok := local.decls[n.Name]; ok && target != relPath {
+				use(target, n.Name)
+				return true
+			}
+			for _, pkgDir := range dotImports {
+				if target, ok := r.pkg(pkgDir, false).decls[n.Name]; ok {
+					use(target, n.Name)
+					break
+				}
+			}
+		}
+		return true
+	})
+
+	edges := make([]edge, 0, len(symbols))
+	for target, used := range symbols {
+		edges = append(edges, edge{to: target, reason: "defines " + summarizeSymbols(used)})
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i].to < edges[j].to })
+	return edges, unresolved
+}
+
+// declaredIdents collects identifiers that name something rather than refer to it:
+// declarations, struct fields, parameters and composite literal keys.
+func declaredIdents(file *ast.File) map[*ast.Ident]bool {
+	declared := map[*ast.Ident]bool{file.Name: true}
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			declared[n.Name] = true
+		case *ast.TypeSpec:
+			declared[n.Name] = true
+		case *ast.ValueSpec:
+			for _, name := range n.Names {
+				declared[name] = true
+			}
+		case *ast.Field:
+			for _, name := range n.Names {
+				declared[name] = true
+			}
+		case *ast.KeyValueExpr:
+			if key, ok := n.Key.(*ast.Ident); ok {
+				declared[key] = true
+			}
+		case *ast.AssignStmt:
+			if n.Tok == token.DEFINE {
+				for _, lhs := range n.Lhs {
+					if ident, ok := lhs.(*ast.Ident); ok {
+						declared[ident] = true
+					}
+				}
+			}
+		case *ast.LabeledStmt:
+			declared[n.Label] = true
+		case *ast.BranchStmt:
+			if n.Label != nil {
+				declared[n.Label] = true
+			}
+		}
+		return true
+	})
+	return declared
+}
+
+func summarizeSymbols(symbols []string) string {
+	sort.Strings(symbols)
+	const shown = 3
+	if len(symbols) <= shown {
+		return strings.Join(symbols, ", ")
+	}
+	return strings.Join(symbols[:shown], ", ") + " and " + strconv.Itoa(len(symbols)-shown) + " more"
+}
+
+func (r *goResolver) parse(relPath string) *ast.File {
+	if file, ok := r.files[relPath]; ok {
+		return file
+	}
+	var file *ast.File
+	if src, err := r.p.readFile(relPath); err == nil {
+		file, _ = parser.ParseFile(r.fset, relPath, src, 0)
+	}
+	r.files[relPath] = file
+	return file
+}
+
+// pkg indexes the top-level declarations and methods of the package in dir. Test files are
+// included only for withTests, and files of an external _test package never are.
+func (r *goResolver) pkg(dir string, withTests bool) *goPackage {
+	key := dir
+	if withTests {
+		key += "#test"
+	}
+	if pkg, ok := r.packages[key]; ok {
+		return pkg
+	}
+	pkg := &goPackage{decls: make(map[string]string), methods: make(map[string][]goMethod)}
+	r.packages[key] = pkg
+
+	names := r.p.listDir(dir)
+	sort.Strings(names)
+	var files []string
+	for _, name := range names {
+		if !strings.HasSuffix(name, ".go") || (!withTests && strings.HasSuffix(name, "_test.go")) {
+			continue
+		}
+		rel := path.Join(dir, name)
+		file := r.parse(rel)
+		if file == nil {
+			continue
+		}
+		if pkg.name == "" && !strings.HasSuffix(file.Name.Name, "_test") {
+			pkg.name = file.Name.Name
+		}
+		files = append(files, rel)
+	}
+	for _, rel := range files {
+		file := r.files[rel]
+		if file.Name.Name != pkg.name {
+			continue
+		}
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv != nil {
+				if recv := receiverType(fn); recv != "" && fn.Name.Name != "_" {
+					pkg.methods[fn.Name.Name] = append(pkg.methods[fn.Name.Name], goMethod{recv: recv, file: rel})
+				}
+				continue
+			}
+			for _, name := range topLevelNames(decl) {
+				if name == "_" || name == "init" {
+					continue
+				}
+				if _, exists := pkg.decls[name]; !exists {
+					pkg.decls[name] = rel
+				}
+			}
+		}
+	}
+	return pkg
+}
+
+// receiverType returns the type name of a method's receiver, without pointer or
+// type parameters.
+func receiverType(fn *ast.FuncDecl) string {
+	if len(fn.Recv.List) == 0 {
+		return ""
+	}
+	expr := fn.Recv.List[0].Type
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+		case *ast.ParenExpr:
+			expr = t.X
+		case *ast.IndexExpr:
+			expr = t.X
+		case *ast.IndexListExpr:
+			expr = t.X
+		case *ast.Ident:
+			return t.Name
+		default:
+			return ""
+		}
+	}
+}
+
+func topLevelNames(decl ast.Decl) []string {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if d.Recv == nil {
+			return []string{d.Name.Name}
+		}
+	case *ast.GenDecl:
+		var names []string
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				names = append(names, s.Name.Name)
+			case *ast.ValueSpec:
+				for _, name := range s.Names {
+					names = append(names, name.Name)
+				}
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+// module finds the go.mod enclosing dir within the project.
+func (r *goResolver) module(dir string) *goModule {
+	if mod, ok := r.modules[dir]; ok {
+		return mod
+	}
+	var mod *goModule
+	if modulePath := readModulePath(r.p, path.Join(dir, "go.mod")); modulePath != "" {
+		mod = &goModule{dir: dir, path: modulePath}
+		if dir == "." {
+			mod.dir = ""
+		}
+	} else if dir != "." {
+		mod = r.module(path.Dir(dir))
+	}
+	r.modules[dir] = mod
+	return mod
+}
+
+// dirFor maps an import path inside the module to its project-relative directory.
+func (m *goModule) dirFor(importPath string) (string, bool) {
+	var sub string
+	switch {
+	case importPath == m.path:
+	case strings.HasPrefix(importPath, m.path+"/"):
+		sub = strings.TrimPrefix(importPath, m.path+"/")
+	default:
+		return "", false
+	}
+	dir := path.Join(m.dir, sub)
+	if dir == "" {
+		dir = "."
+	}
+	return dir, true
+}
+
+// readModulePath returns the module path declared in the go.mod file at rel, or "".
+func readModulePath(p *project, rel string) string {
+	data, err := p.readFile(rel)
+	if err != nil {
+		return ""
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if comment := strings.Index(line, "//"); comment >= 0 {
+			line = strings.TrimSpace(line[:comment])
+		}
+		if rest, ok := strings.CutPrefix(line, "module"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t' || rest[0] == '"') {
+			modulePath := strings.TrimSpace(rest)
+			if unquoted, err := strconv.Unquote(modulePath); err == nil {
+				modulePath = unquoted
+			}
+			return modulePath
+		}
+	}
+	return ""
+}
@@ -0,0 +1,99 @@
+package depgraph
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for rel, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func addedPaths(t *testing.T, root string, selected []string, opts Options) map[string]string {
+	t.Helper()
+	expansion, err := Expand(context.Background(), root, selected, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	added := make(map[string]string)
+	for _, dep := range expansion.Added {
+		added[dep.RelPath] = dep.Reason
+	}
+	return added
+}
+
+func TestGoMethodsResolveToDeclaringFile(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"go.mod":          "module example.com/m\n",
+		"main.go":         "package main\n\nimport \"example.com/m/store\"\n\nfunc main() {\n\ts := store.Open()\n\ts.Flush()\n\tvar c cache\n\tc.evict()\n}\n",
+		"cache.go":        "package main\n\ntype cache struct{}\n",
+		"cache_evict.go":  "package main\n\nfunc (c *cache) evict() {}\n",
+		"store/store.go":  "package store\n\ntype Store struct{}\n\nfunc Open() *Store { return &Store{} }\n",
+		"store/flush.go":  "package store\n\nfunc (s *Store) Flush() {}\n",
+		"store/unused.go": "package store\n\nfunc (s *Store) Close() {}\n",
+	})
+	added := addedPaths(t, root, []string{"main.go"}, Options{})
+	for rel, reason := range map[string]string{
+		"cache.go":       "defines cache",
+		"cache_evict.go": "defines cache.evict",
+		"store/store.go": "defines store.Open",
+		"store/flush.go": "defines Store.Flush",
+	} {
+		if added[rel] != reason {
+			t.Errorf("%s: reason %q, want %q", rel, added[rel], reason)
+		}
+	}
+	if _, ok := added["store/unused.go"]; ok {
+		t.Error("store/unused.go added although Close is never called")
+	}
+}
+
+func TestGoAmbiguousMethodsAreNotResolved(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"go.mod":  "module example.com/m\n",
+		"main.go": "package main\n\nfunc run(x interface{ String() string }) string { return x.String() }\n",
+		"a.go":    "package main\n\ntype a struct{}\n\nfunc (a) String() string { return \"a\" }\n",
+		"b.go":    "package main\n\ntype b struct{}\n\nfunc (b) String() string { return \"b\" }\n",
+	})
+	if added := addedPaths(t, root, []string{"main.go"}, Options{}); len(added) != 0 {
+		t.Errorf("added = %v, want nothing", added)
+	}
+}
+
+func TestGoShadowingLocalIsNotResolved(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"go.mod":    "module example.com/m\n",
+		"main.go":   "package main\n\nfunc main() {\n\tconfig := 1\n\t_ = config\n\tfor limit := 0; limit < 3; limit++ {\n\t}\n}\n",
+		"config.go": "package main\n\nvar config = 2\n",
+		"limit.go":  "package main\n\nconst limit = 3\n",
+	})
+	if added := addedPaths(t, root, []string{"main.go"}, Options{}); len(added) != 0 {
+		t.Errorf("added = %v, want nothing", added)
+	}
+}
+
+func TestSkipLeavesFilesOut(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"go.mod":    "module example.com/m\n",
+		"main.go":   "package main\n\nfunc main() { helper(); other() }\n",
+		"helper.go": "package main\n\nfunc helper() {}\n",
+		"other.go":  "package main\n\nfunc other() {}\n",
+	})
+	added := addedPaths(t, root, []string{"main.go"}, Options{Skip: func(rel string) bool { return rel == "other.go" }})
+	if _, ok := added["other.go"]; ok || added["helper.go"] == "" {
+		t.Errorf("added = %v, want only helper.go", added)
+	}
+}
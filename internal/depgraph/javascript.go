@@ -0,0 +1,436 @@
+package depgraph
+
+import (
+	"encoding/json"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// scriptExtensions are the files the script resolver reads, in the order tried
+// when an import omits the extension.
+var scriptExtensions = []string{".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs", ".mts", ".cts", ".vue", ".json"}
+
+var (
+	scriptFromPattern    = regexp.MustCompile(`\bfrom\s*['"]([^'"\n]+)['"]`)              // import/export ... from 'x'
+	scriptBarePattern    = regexp.MustCompile(`(?m)^\s*import\s*['"]([^'"\n]+)['"]`)      // import 'x'
+	scriptDynamicPattern = regexp.MustCompile(`\bimport\s*\(\s*['"]([^'"\n]+)['"]\s*\)`)  // import('x')
+	scriptRequirePattern = regexp.MustCompile(`\brequire\s*\(\s*['"]([^'"\n]+)['"]\s*\)`) // require('x')
+	vueScriptPattern     = regexp.MustCompile(`(?is)<script\b[^>]*>(.*?)</script>`)
+)
+
+// maxConfigExtends bounds how many tsconfig files an extends chain may read.
+const maxConfigExtends = 8
+
+// scriptResolver follows ES module imports, dynamic imports and CommonJS requires
+// in JavaScript, TypeScript and Vue single-file components. Relative specifiers and
+// tsconfig/jsconfig path aliases are resolved; bare package names are external.
+type scriptResolver struct {
+	p       *project
+	configs map[string]*scriptConfig // Nearest tsconfig/jsconfig by directory; nil when none
+}
+
+// scriptConfig holds the module resolution settings of a tsconfig.json or jsconfig.json.
+type scriptConfig struct {
+	baseDir string              // Project-relative directory that paths are relative to
+	paths   map[string][]string // e.g. "@/*" -> ["src/*"]
+}
+
+func newScriptResolver(p *project) *scriptResolver {
+	return &scriptResolver{p: p, configs: make(map[string]*scriptConfig)}
+}
+
+func (r *scriptResolver) handles(relPath string) bool {
+	switch path.Ext(relPath) {
+	case ".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx", ".mts", ".cts", ".vue":
+		return true
+	}
+	return false
+}
+
+func (r *scriptResolver) resolve(relPath string) ([]edge, []string) {
+	data, err := r.p.readFile(relPath)
+	if err != nil {
+		return nil, nil
+	}
+	source := string(data)
+	if path.Ext(relPath) == ".vue" {
+		var scripts []string
+		for _, m := range vueScriptPattern.FindAllStringSubmatch(source, -1) {
+			scripts = append(scripts, m[1])
+		}
+		source = strings.Join(scripts, "\n")
+	}
+
+	var edges []edge
+	var unresolved []string
+	seen := make(map[string]bool)
+	for _, spec := range scriptImports(source) {
+		if seen[spec] {
+			continue
+		}
+		seen[spec] = true
+		target, local := r.resolveSpecifier(path.Dir(relPath), spec)
+		switch {
+		case target != "":
+			edges = append(edges, edge{to: target, reason: "imported as " + spec})
+		case local:
+			unresolved = append(unresolved, spec)
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i].to < edges[j].to })
+	return edges, unresolved
+}
+
+// scriptImports lists the module specifiers in source, in order of appearance.
+func scriptImports(source string) []string {
+	source = stripScriptComments(source)
+	type found struct {
+		at   int
+		spec string
+	}
+	var all []found
+	for _, re := range []*regexp.Regexp{scriptFromPattern, scriptBarePattern, scriptDynamicPattern, scriptRequirePattern} {
+		for _, m := range re.FindAllStringSubmatchIndex(source, -1) {
+			all = append(all, found{at: m[2], spec: source[m[2]:m[3]]})
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].at < all[j].at })
+	specs := make([]string, 0, len(all))
+	for _, f := range all {
+		specs = append(specs, f.spec)
+	}
+	return specs
+}
+
+// resolveSpecifier maps an import specifier to a project file. local is true when
+// the specifier should be a project file (relative or aliased) even if no file matched.
+func (r *scriptResolver) resolveSpecifier(dir, spec string) (target string, local bool) {
+	spec = strings.SplitN(spec, "?", 2)[0] // Vite-style queries like ?raw
+	if strings.HasPrefix(spec, "./") || strings.HasPrefix(spec, "../") || spec == "." || spec == ".." {
+		return r.resolveFile(path.Join(dir, spec)), true
+	}
+	if strings.HasPrefix(spec, "/") {
+		return "", false
+	}
+	cfg := r.config(dir)
+	if cfg == nil {
+		return "", false
+	}
+	pattern, rest, ok := matchPathPattern(cfg.paths, spec)
+	if !ok {
+		return "", false
+	}
+	for _, t := range cfg.paths[pattern] {
+		candidate := path.Join(cfg.baseDir, strings.Replace(t, "*", rest, 1))
+		if file := r.resolveFile(candidate); file != "" {
+			return file, true
+		}
+	}
+	return "", true
+}
+
+// matchPathPattern picks the paths entry TypeScript would use for spec: an exact
+// pattern if there is one, otherwise the wildcard pattern with the longest prefix.
+// rest is the part of spec matched by the wildcard.
+func matchPathPattern(paths map[string][]string, spec string) (pattern, rest string, ok bool) {
+	if _, exact := paths[spec]; exact {
+		return spec, "", true
+	}
+	bestPrefix, bestSuffix := -1, -1
+	for candidate := range paths {
+		star := strings.Index(candidate, "*")
+		if star < 0 {
+			continue
+		}
+		prefix, suffix := candidate[:star], candidate[star+1:]
+		if len(spec) < len(prefix)+len(suffix) || !strings.HasPrefix(spec, prefix) || !strings.HasSuffix(spec, suffix) {
+			continue
+		}
+		// Ties go to the lexically smaller pattern so the choice does not depend on map order.
+		if len(prefix) > bestPrefix || (len(prefix) == bestPrefix && (len(suffix) > bestSuffix || (len(suffix) == bestSuffix && candidate < pattern))) {
+			pattern, bestPrefix, bestSuffix = candidate, len(prefix), len(suffix)
+			rest = spec[len(prefix) : len(spec)-len(suffix)]
+		}
+	}
+	return pattern, rest, bestPrefix >= 0
+}
+
+// resolveFile applies Node/TypeScript lookup rules to a project-relative module path.
+func (r *scriptResolver) resolveFile(base string) string {
+	base = cleanRel(base)
+	if base == "" {
+		return ""
+	}
+	if path.Ext(base) != "" && r.p.isFile(base) {
+		return base
+	}
+	// TypeScript sources are often imported with the .js extension they compile to.
+	if stem, ok := strings.CutSuffix(base, ".js"); ok {
+		for _, ext := range []string{".ts", ".tsx"} {
+			if r.p.isFile(stem + ext) {
+				return stem + ext
+			}
+		}
+	}
+	for _, ext := range scriptExtensions {
+		if r.p.isFile(base + ext) {
+			return base + ext
+		}
+	}
+	for _, ext := range scriptExtensions {
+		if index := path.Join(base, "index"+ext); r.p.isFile(index) {
+			return index
+		}
+	}
+	return ""
+}
+
+// config finds the nearest tsconfig.json or jsconfig.json at or above dir.
+func (r *scriptResolver) config(dir string) *scriptConfig {
+	if cfg, ok := r.configs[dir]; ok {
+		return cfg
+	}
+	var cfg *scriptConfig
+	for _, name := range []string{"tsconfig.json", "jsconfig.json"} {
+		if cfg = readScriptConfig(r.p, dir, path.Join(dir, name)); cfg != nil {
+			break
+		}
+	}
+	if cfg == nil && dir != "." {
+		cfg = r.config(path.Dir(dir))
+	}
+	r.configs[dir] = cfg
+	return cfg
+}
+
+// readScriptConfig reads the config file at rel, following extends, and returns
+// nil when it cannot be read or sets no paths.
+func readScriptConfig(p *project, dir, rel string) *scriptConfig {
+	if !p.isFile(rel) {
+		return nil
+	}
+	opts, ok := readCompilerOptions(p, rel, maxConfigExtends)
+	if !ok || len(opts.paths) == 0 {
+		return nil
+	}
+	// Without a baseUrl, paths are relative to the config file that declares them.
+	baseDir := opts.pathsDir
+	if opts.baseURL != "" {
+		baseDir = opts.baseURL
+	}
+	return &scriptConfig{baseDir: baseDir, paths: opts.paths}
+}
+
+// compilerOptions are the resolution settings of one config after applying the
+// configs it extends. Directories are project-relative.
+type compilerOptions struct {
+	baseURL  string // "" when unset
+	paths    map[string][]string
+	pathsDir string // Directory of the config that declared paths
+}
+
+func readCompilerOptions(p *project, rel string, budget int) (compilerOptions, bool) {
+	var opts compilerOptions
+	data, err := p.readFile(rel)
+	if err != nil || budget <= 0 {
+		return opts, false
+	}
+	var parsed struct {
+		Extends         json.RawMessage `json:"extends"`
+		CompilerOptions struct {
+			BaseURL *string             `json:"baseUrl"`
+			Paths   map[string][]string `json:"paths"`
+		} `json:"compilerOptions"`
+	}
+	if err := json.Unmarshal(stripJSONComments(data), &parsed); err != nil {
+		return opts, false
+	}
+	dir := path.Dir(rel)
+
+	// extends is a string or, since TypeScript 5.0, a list applied in order.
+	var bases []string
+	var single string
+	if json.Unmarshal(parsed.Extends, &single) == nil {
+		bases = []string{single}
+	} else {
+		_ = json.Unmarshal(parsed.Extends, &bases)
+	}
+	for _, spec := range bases {
+		baseRel := resolveConfigExtends(p, dir, spec)
+		if baseRel == "" {
+			continue
+		}
+		base, ok := readCompilerOptions(p, baseRel, budget-1)
+		if !ok {
+			continue
+		}
+		if base.baseURL != "" {
+			opts.baseURL = base.baseURL
+		}
+		if base.paths != nil {
+			opts.paths, opts.pathsDir = base.paths, base.pathsDir
+		}
+	}
+
+	if parsed.CompilerOptions.BaseURL != nil {
+		opts.baseURL = path.Join(dir, *parsed.CompilerOptions.BaseURL)
+	}
+	if parsed.CompilerOptions.Paths != nil {
+		opts.paths, opts.pathsDir = parsed.CompilerOptions.Paths, dir
+	}
+	return opts, true
+}
+
+// resolveConfigExtends maps an extends value to a project-relative config file:
+// relative paths against the extending config, package names through node_modules.
+func resolveConfigExtends(p *project, dir, spec string) string {
+	var candidates []string
+	withJSON := func(base string) []string {
+		if path.Ext(base) == ".json" {
+			return []string{base}
+		}
+		return []string{base, base + ".json", path.Join(base, "tsconfig.json")}
+	}
+	if strings.HasPrefix(spec, "./") || strings.HasPrefix(spec, "../") || spec == "." || spec == ".." {
+		candidates = withJSON(path.Join(dir, spec))
+	} else if spec != "" && !strings.HasPrefix(spec, "/") {
+		for d := dir; ; d = path.Dir(d) {
+			candidates = append(candidates, withJSON(path.Join(d, "node_modules", spec))...)
+			if d == "." {
+				break
+			}
+		}
+	}
+	for _, candidate := range candidates {
+		if rel := cleanRel(candidate); rel != "" && p.isFile(rel) {
+			return rel
+		}
+	}
+	return ""
+}
+
+// stripScriptComments blanks out // and /* */ comments in JavaScript source,
+// leaving string, template and regular expression literals untouched. Newlines
+// inside block comments are kept so line-anchored patterns still work.
+func stripScriptComments(source string) string {
+	var out strings.Builder
+	out.Grow(len(source))
+	prev := byte(0) // Last significant byte written, to tell a regex from division
+	for i := 0; i < len(source); i++ {
+		c := source[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := skipScriptLiteral(source, i, c)
+			out.WriteString(source[i:end])
+			i = end - 1
+			prev = c
+		case c == '/' && i+1 < len(source) && source[i+1] == '/':
+			for i < len(source) && source[i] != '\n' {
+				i++
+			}
+			if i < len(source) {
+				out.WriteByte('\n')
+			}
+		case c == '/' && i+1 < len(source) && source[i+1] == '*':
+			end := strings.Index(source[i+2:], "*/")
+			comment := source[i:]
+			if end >= 0 {
+				comment = source[i : i+2+end+2]
+			}
+			out.WriteString(strings.Repeat("\n", strings.Count(comment, "\n")))
+			out.WriteByte(' ')
+			i += len(comment) - 1
+		case c == '/' && startsScriptRegex(prev):
+			end := skipScriptLiteral(source, i, '/')
+			out.WriteString(source[i:end])
+			i = end - 1
+			prev = c
+		default:
+			out.WriteByte(c)
+			if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+				prev = c
+			}
+		}
+	}
+	return out.String()
+}
+
+// skipScriptLiteral returns the index just past the literal opened by quote at
+// start. Strings and regexes end at a newline; regex character classes may hold '/'.
+func skipScriptLiteral(source string, start int, quote byte) int {
+	inClass := false
+	for i := start + 1; i < len(source); i++ {
+		switch c := source[i]; {
+		case c == '\\':
+			i++
+		case c == '\n' && quote != '`':
+			return i
+		case quote == '/' && c == '[':
+			inClass = true
+		case quote == '/' && c == ']':
+			inClass = false
+		case c == quote && !inClass:
+			return i + 1
+		}
+	}
+	return len(source)
+}
+
+// startsScriptRegex reports whether a '/' following prev opens a regular
+// expression literal rather than dividing.
+func startsScriptRegex(prev byte) bool {
+	return prev == 0 || strings.IndexByte("(,=:[!&|?{};+-*%<>~^", prev) >= 0
+}
+
+// stripJSONComments removes // and /* */ comments and trailing commas, which
+// tsconfig files allow, leaving string contents untouched.
+func stripJSONComments(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if inString {
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+		switch {
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			if i < len(data) {
+				out = append(out, '\n')
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			i += 2
+			for i+1 < len(data) && (data[i] != '*' || data[i+1] != '/') {
+				i++
+			}
+			i++
+		case c == ']' || c == '}':
+			// Drop a trailing comma before the closing bracket.
+			j := len(out) - 1
+			for j >= 0 && (out[j] == ' ' || out[j] == '\t' || out[j] == '\n' || out[j] == '\r') {
+				j--
+			}
+			if j >= 0 && out[j] == ',' {
+				out = append(out[:j], out[j+1:]...)
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
@@ -0,0 +1,64 @@
+package depgraph
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestScriptPathsUseLongestPrefix(t *testing.T) {
+	files := map[string]string{
+		"tsconfig.json":            `{"compilerOptions": {"baseUrl": ".", "paths": {"@/*": ["src/*"], "@/components/*": ["src/ui/*"], "@app": ["src/app.ts"]}}}`,
+		"src/main.ts":              "import Button from '@/components/Button'\nimport { util } from '@/util'\nimport app from '@app'\n",
+		"src/ui/Button.ts":         "export default 1\n",
+		"src/components/Button.ts": "export default 2\n",
+		"src/util.ts":              "export const util = 1\n",
+		"src/app.ts":               "export default 3\n",
+	}
+	// Map order would pick either pattern for @/components/*; repeat to catch that.
+	for range 20 {
+		root := writeFiles(t, files)
+		added := addedPaths(t, root, []string{"src/main.ts"}, Options{})
+		for _, want := range []string{"src/ui/Button.ts", "src/util.ts", "src/app.ts"} {
+			if _, ok := added[want]; !ok {
+				t.Fatalf("added = %v, missing %s", added, want)
+			}
+		}
+		if _, ok := added["src/components/Button.ts"]; ok {
+			t.Fatalf("added = %v, resolved through the shorter @/* pattern", added)
+		}
+	}
+}
+
+func TestScriptConfigExtends(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"tsconfig.base.json":                   `{"compilerOptions": {"baseUrl": "./packages", "paths": {"@shared/*": ["shared/src/*"]}}}`,
+		"packages/web/tsconfig.json":           `{"extends": "../../tsconfig.base", "compilerOptions": {"strict": true}}`,
+		"packages/web/src/index.ts":            "import { fmt } from '@shared/format'\n",
+		"packages/shared/src/format.ts":        "export const fmt = 1\n",
+		"packages/api/tsconfig.json":           `{"extends": ["@org/tsconfig/base.json"], "compilerOptions": {"paths": {"~/*": ["./src/*"]}}}`,
+		"packages/api/src/server.ts":           "import db from '~/db'\n",
+		"packages/api/src/db.ts":               "export default 1\n",
+		"node_modules/@org/tsconfig/base.json": `{"compilerOptions": {"strict": true}}`,
+	})
+	added := addedPaths(t, root, []string{"packages/web/src/index.ts", "packages/api/src/server.ts"}, Options{})
+	for _, want := range []string{"packages/shared/src/format.ts", "packages/api/src/db.ts"} {
+		if _, ok := added[want]; !ok {
+			t.Errorf("added = %v, missing %s", added, want)
+		}
+	}
+}
+
+func TestScriptImportsKeepCommentMarkersInLiterals(t *testing.T) {
+	source := "const glob = 'src/**/*.ts'\n" +
+		"import a from './a' // trailing comment\n" +
+		"const re = /\\/*/\n" +
+		"/* import b from './b' */\n" +
+		"const url = \"http://example.com\"; import c from './c'\n" +
+		"// import d from './d'\n" +
+		"const tpl = `/* ${x} */`\n" +
+		"import e from './e'\n"
+	want := []string{"./a", "./c", "./e"}
+	if got := scriptImports(source); !slices.Equal(got, want) {
+		t.Errorf("scriptImports = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,212 @@
+package depgraph
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	pythonImportPattern = regexp.MustCompile(`^import\s+(.+)$`)
+	pythonFromPattern   = regexp.MustCompile(`^from\s+(\.*)([\w.]*)\s+import\s+(.+)$`)
+)
+
+// pythonResolver follows import statements in Python sources. Relative imports are
+// resolved against the importing package; absolute imports against the project
+// root, a src/ directory and the directory above the file's top-level package.
+// Absolute imports that match nothing in the project are treated as external.
+type pythonResolver struct {
+	p *project
+}
+
+// pythonImport is one imported module, with the names taken from it by a from-import.
+type pythonImport struct {
+	level  int    // Leading dots of a relative import, 0 for absolute imports
+	module string // Dotted module path, may be empty for "from . import x"
+	names  []string
+}
+
+func newPythonResolver(p *project) *pythonResolver {
+	return &pythonResolver{p: p}
+}
+
+func (r *pythonResolver) handles(relPath string) bool {
+	ext := path.Ext(relPath)
+	return ext == ".py" || ext == ".pyi"
+}
+
+func (r *pythonResolver) resolve(relPath string) ([]edge, []string) {
+	data, err := r.p.readFile(relPath)
+	if err != nil {
+		return nil, nil
+	}
+	dir := path.Dir(relPath)
+
+	var edges []edge
+	var unresolved []string
+	seen := make(map[string]bool)
+	add := func(target, reason string) {
+		if target != "" && target != relPath && !seen[target] {
+			seen[target] = true
+			edges = append(edges, edge{to: target, reason: reason})
+		}
+	}
+
+	for _, imp := range pythonImports(string(data)) {
+		display := strings.Repeat(".", imp.level) + imp.module
+		var bases []string
+		if imp.level > 0 {
+			base, above := dir, false
+			for i := 1; i < imp.level; i++ {
+				if base == "." {
+					above = true // Climbs out of the project root
+					break
+				}
+				base = path.Dir(base)
+			}
+			if above {
+				unresolved = append(unresolved, display)
+				continue
+			}
+			bases = []string{base}
+		} else {
+			bases = r.searchRoots(dir)
+		}
+
+		found := false
+		for _, base := range bases {
+			modDir := path.Join(base, strings.ReplaceAll(imp.module, ".", "/"))
+			module := r.moduleFile(modDir)
+			if module != "" && imp.module != "" {
+				add(module, "imports "+display)
+				found = true
+			}
+			// from pkg import submodule: each name may be a module of its own.
+			for _, name := range imp.names {
+				if sub := r.moduleFile(path.Join(modDir, name)); sub != "" {
+					add(sub, "imports "+strings.TrimSuffix(display, ".")+"."+name)
+					found = true
+				} else if imp.module == "" && module != "" {
+					add(module, "imports "+display+" "+name)
+					found = true
+				}
+			}
+			if found {
+				break
+			}
+		}
+		if !found && imp.level > 0 {
+			unresolved = append(unresolved, display)
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i].to < edges[j].to })
+	return edges, unresolved
+}
+
+// moduleFile maps a module path without extension to module.py, module.pyi or
+// module/__init__.py.
+func (r *pythonResolver) moduleFile(modPath string) string {
+	modPath = cleanRel(modPath)
+	if modPath == "" {
+		return ""
+	}
+	for _, candidate := range []string{modPath + ".py", modPath + ".pyi", path.Join(modPath, "__init__.py")} {
+		if r.p.isFile(candidate) {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// searchRoots lists the directories absolute imports may be relative to.
+func (r *pythonResolver) searchRoots(dir string) []string {
+	roots := []string{"."}
+	if r.p.isFile("src/__init__.py") || len(r.p.listDir("src")) > 0 {
+		roots = append(roots, "src")
+	}
+	// The parent of the outermost package containing dir.
+	top := dir
+	for top != "." && r.p.isFile(path.Join(top, "__init__.py")) {
+		top = path.Dir(top)
+	}
+	if top != "." && top != "src" {
+		roots = append(roots, top)
+	}
+	return roots
+}
+
+// pythonImports extracts import statements, joining parenthesized and
+// backslash-continued lines and ignoring comments and docstrings.
+func pythonImports(source string) []pythonImport {
+	var imports []pythonImport
+	var pending strings.Builder
+	depth := 0
+	inDocstring := ""
+	for _, raw := range strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n") {
+		line := raw
+		if inDocstring != "" {
+			if strings.Contains(line, inDocstring) {
+				inDocstring = ""
+			}
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		for _, quote := range []string{`"""`, `'''`} {
+			if strings.HasPrefix(trimmed, quote) && strings.Count(trimmed, quote) == 1 {
+				inDocstring = quote
+			}
+		}
+		if inDocstring != "" {
+			continue
+		}
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		continued := strings.HasSuffix(line, "\\")
+		line = strings.TrimSuffix(line, "\\")
+		depth += strings.Count(line, "(") - strings.Count(line, ")")
+		pending.WriteString(line + " ")
+		if continued || depth > 0 {
+			continue
+		}
+		depth = 0
+		statement := strings.Join(strings.Fields(strings.NewReplacer("(", " ", ")", " ").Replace(pending.String())), " ")
+		pending.Reset()
+		imports = append(imports, parsePythonImport(statement)...)
+	}
+	return imports
+}
+
+func parsePythonImport(statement string) []pythonImport {
+	// Several statements may share a line: import os; import sys
+	if strings.Contains(statement, ";") {
+		var all []pythonImport
+		for _, part := range strings.Split(statement, ";") {
+			all = append(all, parsePythonImport(strings.TrimSpace(part))...)
+		}
+		return all
+	}
+	if m := pythonFromPattern.FindStringSubmatch(statement); m != nil {
+		imp := pythonImport{level: len(m[1]), module: m[2]}
+		for _, name := range strings.Split(m[3], ",") {
+			name = strings.TrimSpace(strings.SplitN(strings.TrimSpace(name), " as ", 2)[0])
+			if name != "" && name != "*" {
+				imp.names = append(imp.names, name)
+			}
+		}
+		return []pythonImport{imp}
+	}
+	if m := pythonImportPattern.FindStringSubmatch(statement); m != nil {
+		var imports []pythonImport
+		for _, module := range strings.Split(m[1], ",") {
+			module = strings.TrimSpace(strings.SplitN(strings.TrimSpace(module), " as ", 2)[0])
+			if module != "" {
+				imports = append(imports, pythonImport{module: module})
+			}
+		}
+		return imports
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package depgraph
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestPythonRelativeImportsStayInProject(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"app.py":              "from .. import secrets\nfrom . import helpers\n",
+		"helpers.py":          "",
+		"pkg/__init__.py":     "",
+		"pkg/mod.py":          "from ... import outside\nfrom .. import app\n",
+		"pkg/sub/__init__.py": "",
+		"pkg/sub/deep.py":     "from ..mod import x\n",
+	})
+	expansion, err := Expand(context.Background(), root, []string{"app.py", "pkg/mod.py", "pkg/sub/deep.py"}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var added []string
+	for _, dep := range expansion.Added {
+		added = append(added, dep.RelPath)
+	}
+	if want := []string{"helpers.py"}; !slices.Equal(added, want) {
+		t.Errorf("added = %v, want %v", added, want)
+	}
+	slices.Sort(expansion.Unresolved)
+	if want := []string{"app.py: ..", "pkg/mod.py: ..."}; !slices.Equal(expansion.Unresolved, want) {
+		t.Errorf("unresolved = %v, want %v", expansion.Unresolved, want)
+	}
+}
@@ -0,0 +1,206 @@
+// Package fuzzy scores file paths against a search query the way fzf does: the
+// query characters must appear in order, and matches at word boundaries, path
+// separators, camelCase humps and in consecutive runs score higher.
+package fuzzy
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Scoring constants follow fzf's v1 algorithm.
+const (
+	scoreMatch        = 16
+	scoreGapStart     = -3
+	scoreGapExtension = -1
+
+	bonusBoundary          = scoreMatch / 2
+	bonusBoundaryDelimiter = bonusBoundary + 1 // After '/': favours matches at the start of a path segment
+	bonusBoundaryWhite     = bonusBoundary + 2
+	bonusNonWord           = scoreMatch / 2
+	bonusCamel123          = bonusBoundary + scoreGapExtension
+	bonusConsecutive       = -(scoreGapStart + scoreGapExtension)
+
+	bonusFirstCharMultiplier = 2
+)
+
+type charClass int
+
+const (
+	charWhite charClass = iota
+	charNonWord
+	charDelimiter
+	charLower
+	charUpper
+	charNumber
+)
+
+// Result is a successful match.
+type Result struct {
+	Score     int
+	Positions []int // Rune indices into the matched text, ascending
+}
+
+// Match scores text against query. The query is split on whitespace and every
+// term must match. Matching ignores case unless a term contains an upper-case
+// letter (smart case). ok is false when some term does not match.
+func Match(query, text string) (Result, bool) {
+	terms := strings.Fields(query)
+	if len(terms) == 0 {
+		return Result{}, false
+	}
+	runes := []rune(text)
+	var result Result
+	seen := make(map[int]bool)
+	for _, term := range terms {
+		score, positions, ok := matchTerm([]rune(term), runes)
+		if !ok {
+			return Result{}, false
+		}
+		result.Score += score
+		for _, pos := range positions {
+			if !seen[pos] {
+				seen[pos] = true
+				result.Positions = append(result.Positions, pos)
+			}
+		}
+	}
+	sort.Ints(result.Positions)
+	return result, true
+}
+
+func matchTerm(pattern, text []rune) (int, []int, bool) {
+	caseSensitive := false
+	for _, r := range pattern {
+		if unicode.IsUpper(r) {
+			caseSensitive = true
+			break
+		}
+	}
+	fold := func(r rune) rune {
+		if caseSensitive {
+			return r
+		}
+		return unicode.ToLower(r)
+	}
+
+	// Forward pass: find where the first complete occurrence ends.
+	pidx, end := 0, -1
+	for idx, r := range text {
+		if fold(r) == pattern[pidx] {
+			pidx++
+			if pidx == len(pattern) {
+				end = idx + 1
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	// Backward pass: the latest start that still ends there gives the tightest window.
+	pidx, start := len(pattern)-1, 0
+	for idx := end - 1; idx >= 0; idx-- {
+		if fold(text[idx]) == pattern[pidx] {
+			pidx--
+			if pidx < 0 {
+				start = idx
+				break
+			}
+		}
+	}
+
+	score, positions := scoreWindow(pattern, text, start, end, fold)
+	return score, positions, true
+}
+
+func scoreWindow(pattern, text []rune, start, end int, fold func(rune) rune) (int, []int) {
+	positions := make([]int, 0, len(pattern))
+	score, pidx, consecutive, firstBonus := 0, 0, 0, 0
+	inGap := false
+	prevClass := charDelimiter
+	if start > 0 {
+		prevClass = classOf(text[start-1])
+	}
+	for idx := start; idx < end; idx++ {
+		r := text[idx]
+		class := classOf(r)
+		if pidx < len(pattern) && fold(r) == pattern[pidx] {
+			positions = append(positions, idx)
+			score += scoreMatch
+			bonus := bonusFor(prevClass, class)
+			if consecutive == 0 {
+				firstBonus = bonus
+			} else {
+				// Keep the bonus of the chunk's first character for the whole run.
+				if bonus >= bonusBoundary && bonus > firstBonus {
+					firstBonus = bonus
+				}
+				bonus = max(bonus, firstBonus, bonusConsecutive)
+			}
+			if pidx == 0 {
+				score += bonus * bonusFirstCharMultiplier
+			} else {
+				score += bonus
+			}
+			inGap = false
+			consecutive++
+			pidx++
+		} else {
+			if inGap {
+				score += scoreGapExtension
+			} else {
+				score += scoreGapStart
+			}
+			inGap = true
+			consecutive = 0
+			firstBonus = 0
+		}
+		prevClass = class
+	}
+	return score, positions
+}
+
+func classOf(r rune) charClass {
+	switch {
+	case r == '/' || r == '\\':
+		return charDelimiter
+	case unicode.IsLower(r):
+		return charLower
+	case unicode.IsUpper(r):
+		return charUpper
+	case unicode.IsDigit(r):
+		return charNumber
+	case unicode.IsLetter(r):
+		return charLower
+	case unicode.IsSpace(r):
+		return charWhite
+	default:
+		return charNonWord
+	}
+}
+
+func bonusFor(prev, class charClass) int {
+	if class > charDelimiter {
+		switch prev {
+		case charWhite:
+			return bonusBoundaryWhite
+		case charDelimiter:
+			return bonusBoundaryDelimiter
+		case charNonWord:
+			return bonusBoundary
+		}
+	}
+	if prev == charLower && class == charUpper || prev != charNumber && class == charNumber {
+		return bonusCamel123
+	}
+	switch class {
+	case charNonWord, charDelimiter:
+		return bonusNonWord
+	case charWhite:
+		return bonusBoundaryWhite
+	}
+	return 0
+}
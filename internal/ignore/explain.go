@@ -0,0 +1,82 @@
+package ignore
+
+import (
+	"path"
+	"strings"
+)
+
+// Evaluation records how one rule set judged a path.
+type Evaluation struct {
+	Source   string `json:"source"`         // Display name of the rule set
+	File     string `json:"file,omitempty"` // File the rules were read from
+	Rule     *Rule  `json:"rule,omitempty"` // Last matching rule, nil when nothing matched
+	Decisive bool   `json:"decisive"`       // True for the rule set whose match decided the verdict
+}
+
+// Explanation lists every rule set consulted for a path, in precedence order,
+// and the resulting verdict.
+type Explanation struct {
+	Evaluations []Evaluation `json:"evaluations"`
+	Ignored     bool         `json:"ignored"`
+	// IgnoredParent is the project-relative ancestor directory that is ignored, if any.
+	// Git never re-includes paths below an ignored directory.
+	IgnoredParent string `json:"ignoredParent,omitempty"`
+}
+
+// Explain evaluates the project-relative relPath against every applicable rule set.
+func (s *Stack) Explain(relPath string, isDir bool) Explanation {
+	var result Explanation
+	if s == nil {
+		return result
+	}
+	relPath = strings.Trim(toSlash(relPath), "/")
+	if relPath == "" || relPath == "." {
+		return result
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	repoRel := s.repoPath(relPath)
+
+	// Find the outermost ignored ancestor inside the project.
+	var ancestors []string
+	for dir := path.Dir(repoRel); dir != "." && s.insideProject(dir); dir = path.Dir(dir) {
+		ancestors = append(ancestors, dir)
+	}
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		if s.dirIgnored(ancestors[i]) {
+			result.IgnoredParent = strings.TrimPrefix(strings.TrimPrefix(ancestors[i], s.prefix), "/")
+			break
+		}
+	}
+
+	decided := false
+	for _, rs := range s.applicableRuleSets(repoRel) {
+		candidate := repoRel
+		if rs.BaseDir != "" {
+			candidate = strings.TrimPrefix(repoRel, rs.BaseDir+"/")
+		}
+		if isDir {
+			candidate += "/"
+		}
+		eval := Evaluation{Source: rs.Source, File: rs.Path, Rule: rs.Match(candidate)}
+		if eval.Rule != nil && !decided {
+			eval.Decisive = true
+			decided = true
+			result.Ignored = !eval.Rule.Negate
+		}
+		result.Evaluations = append(result.Evaluations, eval)
+	}
+	if result.IgnoredParent != "" {
+		result.Ignored = true
+	}
+	return result
+}
+
+// Explain evaluates path (slash-separated, trailing '/' for directories) against this rule set.
+func (rs *RuleSet) Explain(path string) Evaluation {
+	eval := Evaluation{Source: rs.Source, File: rs.Path, Rule: rs.Match(toSlash(path))}
+	eval.Decisive = eval.Rule != nil
+	return eval
+}
@@ -0,0 +1,94 @@
+package ignore
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// coreExcludesFile locates the user's global excludes file. Like git, it prefers
+// core.excludesFile from the repository config, then ~/.gitconfig, then
+// $XDG_CONFIG_HOME/git/config, and falls back to $XDG_CONFIG_HOME/git/ignore.
+// Only files that exist are returned. consulted lists every file whose change could
+// change the answer: the config files and the excludes file itself, existing or not.
+func coreExcludesFile(gitDir string) (file string, consulted []string) {
+	home, _ := os.UserHomeDir()
+	xdgConfig := os.Getenv("XDG_CONFIG_HOME")
+	if xdgConfig == "" && home != "" {
+		xdgConfig = filepath.Join(home, ".config")
+	}
+
+	var configs []string
+	if gitDir != "" {
+		configs = append(configs, filepath.Join(gitDir, "config"))
+	}
+	if home != "" {
+		configs = append(configs, filepath.Join(home, ".gitconfig"))
+	}
+	if xdgConfig != "" {
+		configs = append(configs, filepath.Join(xdgConfig, "git", "config"))
+	}
+
+	for i, config := range configs {
+		if value := readCoreExcludesFile(config); value != "" {
+			path := expandHome(value, home)
+			return existingFile(path), append(configs[:i+1:i+1], path)
+		}
+	}
+	if xdgConfig != "" {
+		path := filepath.Join(xdgConfig, "git", "ignore")
+		return existingFile(path), append(configs, path)
+	}
+	return "", configs
+}
+
+// readCoreExcludesFile extracts core.excludesFile from a git config file.
+// It understands the subset of the format git writes for this key: a [core]
+// section header and a "key = value" line with optional quotes.
+func readCoreExcludesFile(configPath string) string {
+	file, err := os.Open(configPath)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	inCore := false
+	value := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			section := strings.ToLower(strings.TrimSpace(strings.Trim(line, "[]")))
+			inCore = section == "core"
+			continue
+		}
+		if !inCore {
+			continue
+		}
+		key, val, found := strings.Cut(line, "=")
+		if !found || !strings.EqualFold(strings.TrimSpace(key), "excludesfile") {
+			continue
+		}
+		// The last assignment wins, as in git.
+		value = strings.Trim(strings.TrimSpace(val), `"`)
+	}
+	return value
+}
+
+func expandHome(path, home string) string {
+	if home != "" && (path == "~" || strings.HasPrefix(path, "~/")) {
+		return filepath.Join(home, strings.TrimPrefix(path, "~"))
+	}
+	return path
+}
+
+func existingFile(path string) string {
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		return path
+	}
+	return ""
+}
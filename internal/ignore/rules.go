@@ -0,0 +1,93 @@
+// Package ignore evaluates gitignore-style rule files with git's precedence rules
+// and reports which rule decided the outcome for a path.
+package ignore
+
+import (
+	"os"
+	"strings"
+
+	gitignore "github.com/sabhiram/go-gitignore"
+)
+
+// Rule is a single pattern line from an ignore file.
+type Rule struct {
+	Pattern string `json:"pattern"` // The line as written, including a leading '!'
+	Line    int    `json:"line"`    // 1-based line number, as reported by `git check-ignore -v`
+	Negate  bool   `json:"negate"`  // True for '!' rules that re-include a path
+
+	matcher *gitignore.GitIgnore
+}
+
+// RuleSet is one parsed ignore file. Its patterns are relative to BaseDir.
+type RuleSet struct {
+	Source  string // Display name, e.g. "frontend/.gitignore" or ".git/info/exclude"
+	Path    string // File the rules were read from; empty for in-memory rules
+	BaseDir string // Slash-separated directory the patterns apply to, "" for the root
+	Rules   []Rule
+}
+
+// ParseRules parses gitignore-formatted content. Blank lines and comments are skipped.
+func ParseRules(source, baseDir, content string) *RuleSet {
+	rs := &RuleSet{Source: source, BaseDir: baseDir}
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		trimmed := strings.Trim(line, " \r")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		negate := strings.HasPrefix(trimmed, "!")
+		pattern := trimmed
+		if negate {
+			// Compile the positive form so we can tell when a negation matched;
+			// go-gitignore only reports matches of non-negated patterns.
+			pattern = trimmed[1:]
+		}
+		if strings.TrimSpace(pattern) == "" {
+			continue
+		}
+		rs.Rules = append(rs.Rules, Rule{
+			Pattern: trimmed,
+			Line:    i + 1,
+			Negate:  negate,
+			matcher: gitignore.CompileIgnoreLines(pattern),
+		})
+	}
+	return rs
+}
+
+// LoadRules reads and parses the ignore file at path.
+func LoadRules(source, path, baseDir string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rs := ParseRules(source, baseDir, string(data))
+	rs.Path = path
+	return rs, nil
+}
+
+// Match returns the last rule matching path, or nil if none does. path is relative
+// to BaseDir, slash-separated, with a trailing '/' for directories. As in git, the
+// last matching rule decides: the path is ignored unless that rule is negated.
+func (rs *RuleSet) Match(path string) *Rule {
+	if rs == nil {
+		return nil
+	}
+	var matched *Rule
+	for i := range rs.Rules {
+		if rs.Rules[i].matcher.MatchesPath(path) {
+			matched = &rs.Rules[i]
+		}
+	}
+	return matched
+}
+
+// MatchesPath reports whether path is ignored by this rule set alone.
+func (rs *RuleSet) MatchesPath(path string) bool {
+	rule := rs.Match(toSlash(path))
+	return rule != nil && !rule.Negate
+}
+
+func toSlash(path string) string {
+	return strings.ReplaceAll(path, string(os.PathSeparator), "/")
+}
@@ -0,0 +1,266 @@
+package ignore
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Stack evaluates every ignore file that applies to a project the way git does:
+//   - a .gitignore in a deeper directory overrides the ones above it,
+//   - all .gitignore files override .git/info/exclude,
+//   - .git/info/exclude overrides the user's core.excludesFile,
+//   - a path inside an ignored directory stays ignored, whatever its own rules say.
+//
+// Nested .gitignore files are loaded lazily and cached. A Stack is safe for
+// concurrent use.
+type Stack struct {
+	root     string // Project root as opened in the app
+	repoRoot string // Directory containing .git, or root when the project is not a repository
+	prefix   string // Slash-separated path of root relative to repoRoot, "" when they are equal
+	gitDir   string // Resolved git directory, "" when the project is not a repository
+
+	mu       sync.Mutex
+	global   []*RuleSet          // .git/info/exclude and core.excludesFile, highest precedence first
+	sources  []string            // Files the global rule sets depend on, see GlobalSources
+	dirRules map[string]*RuleSet // Repo-relative dir -> its .gitignore (nil when absent)
+	dirState map[string]bool     // Repo-relative dir -> ignored verdict
+}
+
+// NewStack prepares the ignore rules for the project at root. Ignore files that
+// cannot be read are treated as absent.
+func NewStack(root string) *Stack {
+	s := &Stack{
+		root:     root,
+		repoRoot: root,
+		dirRules: make(map[string]*RuleSet),
+		dirState: make(map[string]bool),
+	}
+	if absRoot, err := filepath.Abs(root); err == nil {
+		if repoRoot, gitDir, ok := findGitDir(absRoot); ok {
+			s.repoRoot = repoRoot
+			s.gitDir = gitDir
+			if rel, err := filepath.Rel(repoRoot, absRoot); err == nil && rel != "." {
+				s.prefix = filepath.ToSlash(rel)
+			}
+		}
+	}
+	s.loadGlobal()
+	return s
+}
+
+// loadGlobal reads .git/info/exclude and core.excludesFile. It must be called with
+// s.mu held or before the stack is shared.
+func (s *Stack) loadGlobal() {
+	s.global, s.sources = nil, nil
+	if s.gitDir != "" {
+		exclude := filepath.Join(s.gitDir, "info", "exclude")
+		s.sources = append(s.sources, exclude)
+		if rs, err := LoadRules(".git/info/exclude", exclude, ""); err == nil {
+			s.global = append(s.global, rs)
+		}
+	}
+	excludesFile, consulted := coreExcludesFile(s.gitDir)
+	s.sources = append(s.sources, consulted...)
+	if excludesFile != "" {
+		if rs, err := LoadRules("core.excludesFile ("+excludesFile+")", excludesFile, ""); err == nil {
+			s.global = append(s.global, rs)
+		}
+	}
+}
+
+// Root returns the project root the stack was created for.
+func (s *Stack) Root() string {
+	return s.root
+}
+
+// MatchesPath reports whether the project-relative path is ignored. Directories are
+// passed with a trailing separator, matching the convention of go-gitignore.
+func (s *Stack) MatchesPath(relPath string) bool {
+	if s == nil {
+		return false
+	}
+	relPath = toSlash(relPath)
+	isDir := strings.HasSuffix(relPath, "/")
+	relPath = strings.Trim(relPath, "/")
+	if relPath == "" || relPath == "." {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ignored(s.repoPath(relPath), isDir)
+}
+
+// Invalidate drops cached .gitignore contents and verdicts, e.g. after a
+// .gitignore file changed on disk.
+func (s *Stack) Invalidate() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dirRules = make(map[string]*RuleSet)
+	s.dirState = make(map[string]bool)
+}
+
+// Reload re-reads .git/info/exclude and core.excludesFile and drops everything
+// cached, e.g. after one of the GlobalSources changed on disk.
+func (s *Stack) Reload() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loadGlobal()
+	s.dirRules = make(map[string]*RuleSet)
+	s.dirState = make(map[string]bool)
+}
+
+// GlobalSources lists the files outside the project's .gitignore files that the
+// rules depend on: .git/info/exclude, the git config files naming core.excludesFile
+// and the excludes file itself. Files that do not exist yet are included.
+func (s *Stack) GlobalSources() []string {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.sources...)
+}
+
+func (s *Stack) repoPath(relPath string) string {
+	if s.prefix == "" {
+		return relPath
+	}
+	return s.prefix + "/" + relPath
+}
+
+// ignored must be called with s.mu held. repoRel is relative to the repository root.
+func (s *Stack) ignored(repoRel string, isDir bool) bool {
+	if parent := path.Dir(repoRel); parent != "." && s.insideProject(parent) {
+		if s.dirIgnored(parent) {
+			return true
+		}
+	}
+	rule, _ := s.decidingRule(repoRel, isDir)
+	return rule != nil && !rule.Negate
+}
+
+func (s *Stack) dirIgnored(repoDir string) bool {
+	if state, ok := s.dirState[repoDir]; ok {
+		return state
+	}
+	state := s.ignored(repoDir, true)
+	s.dirState[repoDir] = state
+	return state
+}
+
+// insideProject reports whether the repo-relative dir lies strictly below the project root.
+func (s *Stack) insideProject(repoDir string) bool {
+	if s.prefix == "" {
+		return true
+	}
+	return strings.HasPrefix(repoDir, s.prefix+"/")
+}
+
+// decidingRule returns the highest-precedence rule matching repoRel together with
+// the rule set it came from, or nil if no rule matches.
+func (s *Stack) decidingRule(repoRel string, isDir bool) (*Rule, *RuleSet) {
+	for _, rs := range s.applicableRuleSets(repoRel) {
+		candidate := repoRel
+		if rs.BaseDir != "" {
+			candidate = strings.TrimPrefix(repoRel, rs.BaseDir+"/")
+		}
+		if isDir {
+			candidate += "/"
+		}
+		if rule := rs.Match(candidate); rule != nil {
+			return rule, rs
+		}
+	}
+	return nil, nil
+}
+
+// applicableRuleSets lists the rule sets that apply to repoRel, highest precedence first.
+func (s *Stack) applicableRuleSets(repoRel string) []*RuleSet {
+	var sets []*RuleSet
+	dir := path.Dir(repoRel)
+	for {
+		if dir == "." {
+			dir = ""
+		}
+		if rs := s.rulesForDir(dir); rs != nil {
+			sets = append(sets, rs)
+		}
+		if dir == "" {
+			break
+		}
+		dir = path.Dir(dir)
+	}
+	return append(sets, s.global...)
+}
+
+func (s *Stack) rulesForDir(repoDir string) *RuleSet {
+	if rs, ok := s.dirRules[repoDir]; ok {
+		return rs
+	}
+	source := ".gitignore"
+	if repoDir != "" {
+		source = repoDir + "/.gitignore"
+	}
+	rs, err := LoadRules(source, filepath.Join(s.repoRoot, filepath.FromSlash(repoDir), ".gitignore"), repoDir)
+	if err != nil {
+		rs = nil
+	}
+	s.dirRules[repoDir] = rs
+	return rs
+}
+
+// findGitDir walks up from dir looking for a .git directory or gitfile.
+// It returns the repository root and the resolved git directory.
+func findGitDir(dir string) (string, string, bool) {
+	current := dir
+	for {
+		candidate := filepath.Join(current, ".git")
+		if info, err := os.Stat(candidate); err == nil {
+			if info.IsDir() {
+				return current, candidate, true
+			}
+			if gitDir := readGitFile(candidate); gitDir != "" {
+				return current, gitDir, true
+			}
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return "", "", false
+		}
+		current = parent
+	}
+}
+
+// readGitFile resolves a "gitdir: <path>" file used by worktrees and submodules.
+// info/exclude lives in the common dir, which worktrees point to via "commondir".
+func readGitFile(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	line := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(line, "gitdir:") {
+		return ""
+	}
+	gitDir := strings.TrimSpace(strings.TrimPrefix(line, "gitdir:"))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(path), gitDir)
+	}
+	if common, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir := strings.TrimSpace(string(common))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+		return filepath.Clean(commonDir)
+	}
+	return gitDir
+}
@@ -0,0 +1,59 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestStackReloadGlobalSources(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	root := t.TempDir()
+	exclude := filepath.Join(root, ".git", "info", "exclude")
+	if err := os.MkdirAll(filepath.Dir(exclude), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(exclude, []byte("build/\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewStack(root)
+	globalIgnore := filepath.Join(home, ".config", "git", "ignore")
+	for _, want := range []string{exclude, filepath.Join(home, ".gitconfig"), globalIgnore} {
+		if !slices.Contains(s.GlobalSources(), want) {
+			t.Errorf("GlobalSources() = %v, missing %s", s.GlobalSources(), want)
+		}
+	}
+	if !s.MatchesPath("build/") {
+		t.Error("directory build/ should match the build/ pattern")
+	}
+	if s.MatchesPath("build") {
+		t.Error("build without a trailing slash is a file and should not match build/")
+	}
+	if s.MatchesPath("debug.log") {
+		t.Error("debug.log matched before the global excludes file existed")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(globalIgnore), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(globalIgnore, []byte("*.log\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(exclude, []byte(""), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if !s.MatchesPath("build/") || s.MatchesPath("debug.log") {
+		t.Error("the stack should keep its rules until Reload")
+	}
+	s.Reload()
+	if s.MatchesPath("build/") {
+		t.Error("build/ still ignored after .git/info/exclude was emptied")
+	}
+	if !s.MatchesPath("debug.log") {
+		t.Error("debug.log not ignored after core.excludesFile appeared")
+	}
+}
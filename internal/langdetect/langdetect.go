@@ -0,0 +1,110 @@
+// Package langdetect guesses a file's language from its name.
+package langdetect
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// byName covers files whose language is given by the whole name rather than the extension.
+var byName = map[string]string{
+	"dockerfile":     "Dockerfile",
+	"makefile":       "Makefile",
+	"gnumakefile":    "Makefile",
+	"cmakelists.txt": "CMake",
+	"go.mod":         "Go Module",
+	"go.sum":         "Go Checksums",
+	"gemfile":        "Ruby",
+	"rakefile":       "Ruby",
+	"justfile":       "Just",
+	".gitignore":     "Ignore List",
+	".dockerignore":  "Ignore List",
+}
+
+var byExtension = map[string]string{
+	".go":      "Go",
+	".js":      "JavaScript",
+	".mjs":     "JavaScript",
+	".cjs":     "JavaScript",
+	".jsx":     "JavaScript",
+	".ts":      "TypeScript",
+	".mts":     "TypeScript",
+	".cts":     "TypeScript",
+	".tsx":     "TypeScript",
+	".vue":     "Vue",
+	".svelte":  "Svelte",
+	".py":      "Python",
+	".pyi":     "Python",
+	".rb":      "Ruby",
+	".rs":      "Rust",
+	".java":    "Java",
+	".kt":      "Kotlin",
+	".kts":     "Kotlin",
+	".scala":   "Scala",
+	".swift":   "Swift",
+	".m":       "Objective-C",
+	".mm":      "Objective-C++",
+	".c":       "C",
+	".h":       "C",
+	".cc":      "C++",
+	".cpp":     "C++",
+	".cxx":     "C++",
+	".hpp":     "C++",
+	".hh":      "C++",
+	".cs":      "C#",
+	".fs":      "F#",
+	".php":     "PHP",
+	".lua":     "Lua",
+	".dart":    "Dart",
+	".ex":      "Elixir",
+	".exs":     "Elixir",
+	".erl":     "Erlang",
+	".hs":      "Haskell",
+	".clj":     "Clojure",
+	".r":       "R",
+	".jl":      "Julia",
+	".zig":     "Zig",
+	".sh":      "Shell",
+	".bash":    "Shell",
+	".zsh":     "Shell",
+	".fish":    "Shell",
+	".ps1":     "PowerShell",
+	".bat":     "Batch",
+	".sql":     "SQL",
+	".html":    "HTML",
+	".htm":     "HTML",
+	".css":     "CSS",
+	".scss":    "SCSS",
+	".sass":    "Sass",
+	".less":    "Less",
+	".json":    "JSON",
+	".jsonc":   "JSON",
+	".yaml":    "YAML",
+	".yml":     "YAML",
+	".toml":    "TOML",
+	".ini":     "INI",
+	".xml":     "XML",
+	".proto":   "Protocol Buffers",
+	".graphql": "GraphQL",
+	".gql":     "GraphQL",
+	".md":      "Markdown",
+	".mdx":     "MDX",
+	".rst":     "reStructuredText",
+	".txt":     "Text",
+	".tf":      "Terraform",
+	".nix":     "Nix",
+	".glob":    "Ignore List",
+}
+
+// Detect returns the language of the file called name, or "" when it is not known.
+// name may be a bare file name or a path.
+func Detect(name string) string {
+	base := strings.ToLower(filepath.Base(name))
+	if lang, ok := byName[base]; ok {
+		return lang
+	}
+	if strings.HasPrefix(base, "dockerfile.") {
+		return "Dockerfile"
+	}
+	return byExtension[filepath.Ext(base)]
+}
@@ -0,0 +1,132 @@
+package lexical
+
+import (
+	"math"
+	"path"
+	"sort"
+	"strings"
+)
+
+// BM25 parameters: k1 saturates repeated terms, b normalizes for document length.
+const (
+	k1 = 1.2
+	b  = 0.75
+	// pathWeight counts a term in the file path as this many occurrences in the
+	// content; file and directory names are strong hints of what a file is about.
+	pathWeight = 3
+)
+
+// Document is a file to rank.
+type Document struct {
+	Path    string // Slash-separated, relative to the project root
+	Content string
+}
+
+// Result is a ranked document.
+type Result struct {
+	Path    string
+	Score   float64
+	Matched []string // Query terms found in the document, strongest contribution first
+}
+
+type docStats struct {
+	path   string
+	tf     map[string]float64
+	length float64
+}
+
+// Index holds the term statistics of a document set.
+type Index struct {
+	docs   []docStats
+	df     map[string]int // Documents containing each term
+	avgLen float64
+}
+
+// NewIndex builds an index over docs.
+func NewIndex(docs []Document) *Index {
+	ix := &Index{df: make(map[string]int)}
+	total := 0.0
+	for _, doc := range docs {
+		stats := docStats{path: doc.Path, tf: make(map[string]float64)}
+		for _, term := range Terms(strings.TrimSuffix(doc.Path, path.Ext(doc.Path))) {
+			stats.tf[term] += pathWeight
+			stats.length += pathWeight
+		}
+		for _, term := range Terms(doc.Content) {
+			stats.tf[term]++
+			stats.length++
+		}
+		for term := range stats.tf {
+			ix.df[term]++
+		}
+		total += stats.length
+		ix.docs = append(ix.docs, stats)
+	}
+	if len(ix.docs) > 0 {
+		ix.avgLen = total / float64(len(ix.docs))
+	}
+	return ix
+}
+
+// Search returns up to k documents matching query, best first. Documents matching
+// no query term are left out.
+func (ix *Index) Search(query string, k int) []Result {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, term := range Terms(query) {
+		if !seen[term] && ix.df[term] > 0 {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	if len(terms) == 0 || ix.avgLen == 0 {
+		return nil
+	}
+
+	n := float64(len(ix.docs))
+	idf := make(map[string]float64, len(terms))
+	for _, term := range terms {
+		df := float64(ix.df[term])
+		idf[term] = math.Log(1 + (n-df+0.5)/(df+0.5))
+	}
+
+	type contribution struct {
+		term  string
+		score float64
+	}
+	var results []Result
+	for _, doc := range ix.docs {
+		var parts []contribution
+		total := 0.0
+		norm := k1 * (1 - b + b*doc.length/ix.avgLen)
+		for _, term := range terms {
+			tf := doc.tf[term]
+			if tf == 0 {
+				continue
+			}
+			score := idf[term] * tf * (k1 + 1) / (tf + norm)
+			parts = append(parts, contribution{term, score})
+			total += score
+		}
+		if len(parts) == 0 {
+			continue
+		}
+		sort.SliceStable(parts, func(i, j int) bool { return parts[i].score > parts[j].score })
+		matched := make([]string, len(parts))
+		for i, p := range parts {
+			matched[i] = p.term
+		}
+		results = append(results, Result{Path: doc.path, Score: total, Matched: matched})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Path < results[j].Path
+	})
+	if k > 0 && len(results) > k {
+		results = results[:k]
+	}
+	return results
+}
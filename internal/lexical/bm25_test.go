@@ -0,0 +1,110 @@
+package lexical
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func resultPaths(results []Result) []string {
+	paths := make([]string, len(results))
+	for i, r := range results {
+		paths[i] = r.Path
+	}
+	return paths
+}
+
+func TestSearch(t *testing.T) {
+	filler := strings.Repeat("alpha beta gamma delta ", 10)
+	tests := []struct {
+		name  string
+		docs  []Document
+		query string
+		k     int
+		want  []string
+	}{
+		{
+			name: "more occurrences and path terms rank first",
+			docs: []Document{
+				{Path: "auth/login.go", Content: "login password"},
+				{Path: "docs/guide.md", Content: "how to login"},
+				{Path: "auth/logout.go", Content: "logout session"},
+			},
+			query: "login",
+			want:  []string{"auth/login.go", "docs/guide.md"},
+		},
+		{
+			// Both match one query term once in documents of equal length; the
+			// rarer term weighs more.
+			name: "rare terms outweigh common ones",
+			docs: []Document{
+				{Path: "a", Content: "login alpha"},
+				{Path: "b", Content: "session alpha"},
+				{Path: "c", Content: "login beta"},
+				{Path: "d", Content: "login gamma"},
+			},
+			query: "login session",
+			want:  []string{"b", "a", "c", "d"},
+		},
+		{
+			name: "shorter documents rank first for the same count",
+			docs: []Document{
+				{Path: "long", Content: "cache " + filler},
+				{Path: "short", Content: "cache store"},
+				{Path: "other", Content: filler},
+			},
+			query: "cache",
+			want:  []string{"short", "long"},
+		},
+		{
+			name: "ties are ordered by path",
+			docs: []Document{
+				{Path: "z", Content: "token"},
+				{Path: "m", Content: "token"},
+			},
+			query: "token",
+			want:  []string{"m", "z"},
+		},
+		{
+			name: "k caps the results",
+			docs: []Document{
+				{Path: "a", Content: "cache cache"},
+				{Path: "b", Content: "cache"},
+			},
+			query: "cache",
+			k:     1,
+			want:  []string{"a"},
+		},
+		{
+			name:  "unknown and stop-word queries match nothing",
+			docs:  []Document{{Path: "a", Content: "cache"}},
+			query: "the missing",
+			want:  []string{},
+		},
+	}
+	for _, tt := range tests {
+		got := resultPaths(NewIndex(tt.docs).Search(tt.query, tt.k))
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: Search(%q) = %v, want %v", tt.name, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSearchMatchedTerms(t *testing.T) {
+	ix := NewIndex([]Document{
+		{Path: "store/cache.go", Content: "cache entries expire"},
+		{Path: "a", Content: "expire"},
+		{Path: "b", Content: "expire"},
+	})
+	results := ix.Search("expire cache unknown", 0)
+	if len(results) != 3 || results[0].Path != "store/cache.go" {
+		t.Fatalf("Search = %v, want store/cache.go first of 3", resultPaths(results))
+	}
+	// cache is rarer and also in the path, so it contributes more than expire.
+	if want := []string{"cache", "expire"}; !slices.Equal(results[0].Matched, want) {
+		t.Errorf("Matched = %v, want %v", results[0].Matched, want)
+	}
+	if results[0].Score <= results[1].Score {
+		t.Errorf("scores %v, want the two-term match ahead", []float64{results[0].Score, results[1].Score})
+	}
+}
@@ -0,0 +1,94 @@
+// Package lexical ranks files against a text query with BM25 over identifier and
+// path terms. It needs no model, so it works offline.
+package lexical
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopwords are dropped from documents and queries: common English words and
+// keywords that appear in nearly every source file.
+var stopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "but": true,
+	"by": true, "can": true, "do": true, "for": true, "from": true, "has": true, "have": true, "if": true,
+	"in": true, "into": true, "is": true, "it": true, "its": true, "of": true, "on": true, "or": true,
+	"so": true, "that": true, "the": true, "their": true, "then": true, "there": true, "these": true,
+	"this": true, "to": true, "was": true, "we": true, "when": true, "which": true, "while": true,
+	"will": true, "with": true, "should": true, "would": true, "want": true, "need": true, "make": true,
+	"add": true, "use": true, "new": true, "all": true, "any": true, "not": true, "no": true,
+	"func": true, "function": true, "return": true, "var": true, "let": true, "const": true,
+	"else": true, "import": true, "package": true, "def": true, "self": true, "nil": true, "null": true,
+	"true": true, "false": true, "string": true, "int": true, "err": true, "error": true,
+}
+
+// Terms splits text into lower-case search terms. Identifiers are split at
+// camelCase, snake_case and kebab-case boundaries and between letters and digits;
+// a compound identifier also yields itself as one term, so "parseConfig" gives
+// "parseconfig", "parse" and "config". Words are reduced to a crude singular.
+// Stopwords, single characters and plain numbers are dropped.
+func Terms(text string) []string {
+	var terms []string
+	add := func(word string) {
+		word = stem(strings.ToLower(word))
+		if len(word) < 2 || stopwords[word] || isNumber(word) {
+			return
+		}
+		terms = append(terms, word)
+	}
+	for _, ident := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-'
+	}) {
+		parts := splitIdentifier(ident)
+		if len(parts) > 1 {
+			add(strings.NewReplacer("_", "", "-", "").Replace(ident))
+		}
+		for _, part := range parts {
+			add(part)
+		}
+	}
+	return terms
+}
+
+// splitIdentifier cuts ident at '_', '-', lower-to-upper and letter-digit
+// boundaries, keeping acronyms together: "HTTPServer2" gives "HTTP", "Server", "2".
+func splitIdentifier(ident string) []string {
+	var parts []string
+	for _, word := range strings.FieldsFunc(ident, func(r rune) bool { return r == '_' || r == '-' }) {
+		runes := []rune(word)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			prev, cur := runes[i-1], runes[i]
+			boundary := (unicode.IsLower(prev) && unicode.IsUpper(cur)) ||
+				(unicode.IsLetter(prev) && unicode.IsDigit(cur)) ||
+				(unicode.IsDigit(prev) && unicode.IsLetter(cur)) ||
+				(unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1]))
+			if boundary {
+				parts = append(parts, string(runes[start:i]))
+				start = i
+			}
+		}
+		parts = append(parts, string(runes[start:]))
+	}
+	return parts
+}
+
+// stem maps plurals to the singular so "files" matches "file".
+func stem(word string) string {
+	switch {
+	case len(word) > 4 && strings.HasSuffix(word, "ies"):
+		return word[:len(word)-3] + "y"
+	case len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+func isNumber(word string) bool {
+	for _, r := range word {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,32 @@
+package lexical
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTerms(t *testing.T) {
+	tests := []struct {
+		name, text string
+		want       []string
+	}{
+		{"camelCase", "parseConfig", []string{"parseconfig", "parse", "config"}},
+		{"PascalCase", "ParseConfig", []string{"parseconfig", "parse", "config"}},
+		{"snake_case", "load_user_profile", []string{"loaduserprofile", "load", "user", "profile"}},
+		{"kebab-case", "user-profile", []string{"userprofile", "user", "profile"}},
+		{"acronym then word", "HTTPServer2", []string{"httpserver2", "http", "server"}},
+		{"acronym inside", "XMLHttpRequest", []string{"xmlhttprequest", "xml", "http", "request"}},
+		{"trailing acronym", "parseURL", []string{"parseurl", "parse", "url"}},
+		{"plurals", "files queries indexes", []string{"file", "query", "indexe"}},
+		{"kept endings", "status class bus", []string{"status", "class", "bus"}},
+		{"compound plural", "userFiles", []string{"userfile", "user", "file"}},
+		{"stop words and keywords", "The function should return an error", nil},
+		{"numbers and single letters", "x 42 v2", []string{"v2"}},
+		{"punctuation", "cfg.Load(path)", []string{"cfg", "load", "path"}},
+	}
+	for _, tt := range tests {
+		if got := Terms(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: Terms(%q) = %q, want %q", tt.name, tt.text, got, tt.want)
+		}
+	}
+}
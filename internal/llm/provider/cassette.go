@@ -0,0 +1,320 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Cassette calls are keyed by a fingerprint of what was asked, not by provider or
+// model, so calls recorded with one vendor replay under any configuration.
+const (
+	cassetteGenerate   = "generate"
+	cassetteChat       = "chat"
+	cassetteStructured = "structured"
+	cassetteEmbed      = "embed"
+	cassetteListModels = "list_models"
+
+	cassettePromptPreviewChars = 300
+)
+
+// cassetteEntry is one recorded call, stored as <method>-<fingerprint>.json.
+type cassetteEntry struct {
+	Method        string      `json:"method"`
+	Fingerprint   string      `json:"fingerprint"`
+	Provider      string      `json:"provider,omitempty"` // Where the call was recorded
+	Model         string      `json:"model,omitempty"`
+	PromptPreview string      `json:"promptPreview,omitempty"` // Start of the prompt, for people reading the cassette
+	Response      string      `json:"response,omitempty"`
+	APICall       string      `json:"apiCall,omitempty"`
+	Vectors       [][]float32 `json:"vectors,omitempty"`
+	Models        []ModelInfo `json:"models,omitempty"`
+}
+
+// cassette is a directory of recorded calls.
+type cassette struct {
+	dir string
+}
+
+func cassetteFingerprint(method string, parts ...string) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	for _, part := range parts {
+		h.Write([]byte{0})
+		h.Write([]byte(part))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:24]
+}
+
+func conversationFingerprint(messages []Message) string {
+	parts := make([]string, 0, 2*len(messages))
+	for _, m := range messages {
+		parts = append(parts, m.Role, m.Content)
+	}
+	return cassetteFingerprint(cassetteChat, parts...)
+}
+
+func schemaFingerprintPart(schema Schema) string {
+	definition, _ := json.Marshal(schema.Definition) // Map keys are sorted, so this is stable
+	return schema.Name + "\x00" + string(definition)
+}
+
+func (c cassette) path(method, fingerprint string) string {
+	return filepath.Join(c.dir, method+"-"+fingerprint+".json")
+}
+
+func (c cassette) load(method, fingerprint string) (cassetteEntry, error) {
+	var entry cassetteEntry
+	data, err := os.ReadFile(c.path(method, fingerprint))
+	if errors.Is(err, os.ErrNotExist) {
+		return entry, fmt.Errorf("no recorded %s call with fingerprint %s in %s", method, fingerprint, c.dir)
+	}
+	if err != nil {
+		return entry, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return entry, fmt.Errorf("failed to parse cassette %s: %w", c.path(method, fingerprint), err)
+	}
+	return entry, nil
+}
+
+func (c cassette) save(entry cassetteEntry) error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+	if err := os.WriteFile(c.path(entry.Method, entry.Fingerprint), append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+func promptPreview(prompt string) string {
+	runes := []rune(prompt)
+	if len(runes) > cassettePromptPreviewChars {
+		return string(runes[:cassettePromptPreviewChars]) + "…"
+	}
+	return prompt
+}
+
+// replayProvider serves calls from a cassette without contacting any vendor. A call
+// that was never recorded fails.
+type replayProvider struct {
+	cassette cassette
+	model    string
+}
+
+func newReplayProvider(cfg Config) (LLMProvider, error) {
+	dir := strings.TrimSpace(cfg.CassetteDir)
+	if dir == "" {
+		return nil, errors.New("replay provider requires a cassette directory")
+	}
+	return &replayProvider{cassette: cassette{dir: dir}, model: strings.TrimSpace(cfg.Model)}, nil
+}
+
+func (r *replayProvider) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	entry, err := r.cassette.load(cassetteListModels, cassetteFingerprint(cassetteListModels))
+	if err != nil {
+		return nil, err
+	}
+	return entry.Models, nil
+}
+
+func (r *replayProvider) Generate(ctx context.Context, prompt string) (string, string, error) {
+	entry, err := r.cassette.load(cassetteGenerate, cassetteFingerprint(cassetteGenerate, prompt))
+	if err != nil {
+		return "", "", err
+	}
+	return entry.Response, entry.APICall, nil
+}
+
+func (r *replayProvider) Chat(ctx context.Context, messages []Message) (string, string, error) {
+	entry, err := r.cassette.load(cassetteChat, conversationFingerprint(messages))
+	if err != nil {
+		return "", "", err
+	}
+	return entry.Response, entry.APICall, nil
+}
+
+// GenerateStructured implements StructuredGenerator.
+func (r *replayProvider) GenerateStructured(ctx context.Context, prompt string, schema Schema) (string, string, error) {
+	entry, err := r.cassette.load(cassetteStructured, cassetteFingerprint(cassetteStructured, prompt, schemaFingerprintPart(schema)))
+	if err != nil {
+		return "", "", err
+	}
+	return entry.Response, entry.APICall, nil
+}
+
+// EmbeddingModel implements Embedder.
+func (r *replayProvider) EmbeddingModel() string {
+	if r.model != "" {
+		return r.model
+	}
+	return "replay"
+}
+
+// Embed implements Embedder.
+func (r *replayProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	entry, err := r.cassette.load(cassetteEmbed, cassetteFingerprint(cassetteEmbed, texts...))
+	if err != nil {
+		return nil, err
+	}
+	return entry.Vectors, nil
+}
+
+// recorder passes calls through to a provider and saves every successful one to a
+// cassette. A call whose recording cannot be saved fails, so a cassette is never
+// silently incomplete. It offers StructuredGenerator and Embedder only when the
+// wrapped provider does; see NewRecorder.
+type recorder struct {
+	inner    LLMProvider
+	cassette cassette
+	provider string
+	model    string
+}
+
+// NewRecorder wraps inner so that its calls are recorded into cfg.CassetteDir for
+// the replay provider to serve later. cfg also names the provider and model noted
+// in the recordings.
+func NewRecorder(inner LLMProvider, cfg Config) (LLMProvider, error) {
+	dir := strings.TrimSpace(cfg.CassetteDir)
+	if dir == "" {
+		return nil, errors.New("recording requires a cassette directory")
+	}
+	r := &recorder{inner: inner, cassette: cassette{dir: dir}, provider: cfg.Provider, model: cfg.Model}
+	_, structured := inner.(StructuredGenerator)
+	_, embedder := inner.(Embedder)
+	switch {
+	case structured && embedder:
+		return &structuredEmbeddingRecorder{r}, nil
+	case structured:
+		return &structuredRecorder{r}, nil
+	case embedder:
+		return &embeddingRecorder{r}, nil
+	}
+	return r, nil
+}
+
+type structuredRecorder struct{ *recorder }
+
+type embeddingRecorder struct{ *recorder }
+
+type structuredEmbeddingRecorder struct{ *recorder }
+
+func (s *structuredRecorder) GenerateStructured(ctx context.Context, prompt string, schema Schema) (string, string, error) {
+	return s.generateStructured(ctx, prompt, schema)
+}
+
+func (e *embeddingRecorder) EmbeddingModel() string { return e.embeddingModel() }
+
+func (e *embeddingRecorder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return e.embed(ctx, texts)
+}
+
+func (s *structuredEmbeddingRecorder) GenerateStructured(ctx context.Context, prompt string, schema Schema) (string, string, error) {
+	return s.generateStructured(ctx, prompt, schema)
+}
+
+func (s *structuredEmbeddingRecorder) EmbeddingModel() string { return s.embeddingModel() }
+
+func (s *structuredEmbeddingRecorder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return s.embed(ctx, texts)
+}
+
+func (r *recorder) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	models, err := r.inner.ListModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return models, r.cassette.save(cassetteEntry{
+		Method:      cassetteListModels,
+		Fingerprint: cassetteFingerprint(cassetteListModels),
+		Provider:    r.provider,
+		Models:      models,
+	})
+}
+
+func (r *recorder) Generate(ctx context.Context, prompt string) (string, string, error) {
+	raw, apiCall, err := r.inner.Generate(ctx, prompt)
+	if err != nil {
+		return raw, apiCall, err
+	}
+	return raw, apiCall, r.cassette.save(cassetteEntry{
+		Method:        cassetteGenerate,
+		Fingerprint:   cassetteFingerprint(cassetteGenerate, prompt),
+		Provider:      r.provider,
+		Model:         r.model,
+		PromptPreview: promptPreview(prompt),
+		Response:      raw,
+		APICall:       apiCall,
+	})
+}
+
+func (r *recorder) Chat(ctx context.Context, messages []Message) (string, string, error) {
+	raw, apiCall, err := r.inner.Chat(ctx, messages)
+	if err != nil {
+		return raw, apiCall, err
+	}
+	preview := ""
+	if len(messages) > 0 {
+		preview = promptPreview(messages[len(messages)-1].Content) // The new turn
+	}
+	return raw, apiCall, r.cassette.save(cassetteEntry{
+		Method:        cassetteChat,
+		Fingerprint:   conversationFingerprint(messages),
+		Provider:      r.provider,
+		Model:         r.model,
+		PromptPreview: preview,
+		Response:      raw,
+		APICall:       apiCall,
+	})
+}
+
+func (r *recorder) generateStructured(ctx context.Context, prompt string, schema Schema) (string, string, error) {
+	raw, apiCall, err := r.inner.(StructuredGenerator).GenerateStructured(ctx, prompt, schema)
+	if err != nil {
+		return raw, apiCall, err
+	}
+	return raw, apiCall, r.cassette.save(cassetteEntry{
+		Method:        cassetteStructured,
+		Fingerprint:   cassetteFingerprint(cassetteStructured, prompt, schemaFingerprintPart(schema)),
+		Provider:      r.provider,
+		Model:         r.model,
+		PromptPreview: promptPreview(prompt),
+		Response:      raw,
+		APICall:       apiCall,
+	})
+}
+
+func (r *recorder) embeddingModel() string {
+	return r.inner.(Embedder).EmbeddingModel()
+}
+
+func (r *recorder) embed(ctx context.Context, texts []string) ([][]float32, error) {
+	embedder := r.inner.(Embedder)
+	vectors, err := embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	preview := ""
+	if len(texts) > 0 {
+		preview = promptPreview(texts[0])
+	}
+	return vectors, r.cassette.save(cassetteEntry{
+		Method:        cassetteEmbed,
+		Fingerprint:   cassetteFingerprint(cassetteEmbed, texts...),
+		Provider:      r.provider,
+		Model:         embedder.EmbeddingModel(),
+		PromptPreview: preview,
+		Vectors:       vectors,
+	})
+}
@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"context"
+	"testing"
+)
+
+// scriptedProvider answers every prompt with a fixed reply and counts its calls.
+type scriptedProvider struct {
+	reply string
+	calls int
+}
+
+func (s *scriptedProvider) ListModels(context.Context) ([]ModelInfo, error) {
+	return []ModelInfo{{Name: "scripted"}}, nil
+}
+
+func (s *scriptedProvider) Generate(_ context.Context, prompt string) (string, string, error) {
+	s.calls++
+	return s.reply + ": " + prompt, "scripted call", nil
+}
+
+func (s *scriptedProvider) Chat(_ context.Context, messages []Message) (string, string, error) {
+	s.calls++
+	return s.reply + ": " + messages[len(messages)-1].Content, "scripted call", nil
+}
+
+func (s *scriptedProvider) GenerateStructured(_ context.Context, prompt string, _ Schema) (string, string, error) {
+	s.calls++
+	return `{"reply": "` + s.reply + `"}`, "scripted call", nil
+}
+
+func TestReplayServesRecordedCalls(t *testing.T) {
+	dir := t.TempDir()
+	inner := &scriptedProvider{reply: "recorded"}
+	rec, err := NewRecorder(inner, Config{Provider: "scripted", Model: "m", CassetteDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := Schema{Name: "reply", Definition: map[string]any{"type": "object"}}
+	conversation := []Message{{Role: "user", Content: "hello"}}
+	ctx := context.Background()
+	if _, _, err := rec.Generate(ctx, "prompt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := rec.Chat(ctx, conversation); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := rec.(StructuredGenerator).GenerateStructured(ctx, "prompt", schema); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := rec.(Embedder); ok {
+		t.Error("recorder offers Embed for a provider without embeddings")
+	}
+
+	replay, err := Factory(Config{Provider: "replay", CassetteDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, apiCall, err := replay.Generate(ctx, "prompt"); err != nil || got != "recorded: prompt" || apiCall != "scripted call" {
+		t.Errorf("Generate = %q, %q, %v", got, apiCall, err)
+	}
+	if got, _, err := replay.Chat(ctx, conversation); err != nil || got != "recorded: hello" {
+		t.Errorf("Chat = %q, %v", got, err)
+	}
+	if got, _, err := replay.(StructuredGenerator).GenerateStructured(ctx, "prompt", schema); err != nil || got != `{"reply": "recorded"}` {
+		t.Errorf("GenerateStructured = %q, %v", got, err)
+	}
+	if models, err := replay.ListModels(ctx); err == nil {
+		t.Errorf("ListModels replayed %v without a recording", models)
+	}
+	if inner.calls != 3 {
+		t.Errorf("provider called %d times, want 3", inner.calls)
+	}
+}
+
+func TestReplayFailsForUnrecordedCalls(t *testing.T) {
+	dir := t.TempDir()
+	rec, err := NewRecorder(&scriptedProvider{reply: "recorded"}, Config{CassetteDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, _, err := rec.Generate(ctx, "prompt"); err != nil {
+		t.Fatal(err)
+	}
+	replay, err := Factory(Config{Provider: "replay", CassetteDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := replay.Generate(ctx, "another prompt"); err == nil {
+		t.Error("replayed a prompt that was never recorded")
+	}
+	schema := Schema{Name: "reply", Definition: map[string]any{"type": "object"}}
+	if _, _, err := replay.(StructuredGenerator).GenerateStructured(ctx, "prompt", schema); err == nil {
+		t.Error("replayed a plain call as a structured one")
+	}
+	if _, err := Factory(Config{Provider: "replay"}); err == nil {
+		t.Error("replay provider accepted an empty cassette directory")
+	}
+	if _, err := NewRecorder(&scriptedProvider{}, Config{}); err == nil {
+		t.Error("recorder accepted an empty cassette directory")
+	}
+}
@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Roles of a conversation message.
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message is one turn of a conversation.
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// validateConversation checks that messages can be sent: known roles, system
+// messages only before the first other turn, and a user turn at the end.
+func validateConversation(messages []Message) error {
+	if len(messages) == 0 {
+		return errors.New("conversation has no messages")
+	}
+	for i, m := range messages {
+		switch m.Role {
+		case RoleSystem:
+			if i > 0 && messages[i-1].Role != RoleSystem {
+				return fmt.Errorf("system message %d follows a conversation turn", i+1)
+			}
+		case RoleUser, RoleAssistant:
+		default:
+			return fmt.Errorf("message %d has unknown role %q", i+1, m.Role)
+		}
+	}
+	if messages[len(messages)-1].Role != RoleUser {
+		return errors.New("conversation must end with a user message")
+	}
+	return nil
+}
+
+// chatMessages converts messages for OpenAI-compatible chat APIs, which use the
+// same role names. With redact set the texts are replaced by placeholders for the
+// debug representation.
+func chatMessages(messages []Message, redact bool) []chatCompletionMessage {
+	out := make([]chatCompletionMessage, len(messages))
+	for i, m := range messages {
+		out[i] = chatCompletionMessage{Role: m.Role, Content: m.Content}
+		if redact {
+			out[i].Content = "[request_text]"
+		}
+	}
+	return out
+}
@@ -0,0 +1,181 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+const (
+	defaultOpenAIEmbeddingModel = "text-embedding-3-small"
+	defaultGeminiEmbeddingModel = "text-embedding-004"
+	// embeddingBatchSize bounds the inputs of one embeddings request; Gemini accepts
+	// at most 100 and OpenAI-compatible servers vary.
+	embeddingBatchSize = 64
+)
+
+// Embedder is implemented by providers that can compute text embeddings.
+type Embedder interface {
+	// EmbeddingModel names the model the vectors come from. Vectors of different
+	// models are not comparable.
+	EmbeddingModel() string
+	// Embed returns one vector per text, in order.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// openAIEmbedder calls an OpenAI-compatible /embeddings endpoint.
+type openAIEmbedder struct {
+	name    string // Provider name used in errors and logs
+	baseURL string
+	apiKey  string
+	model   string
+}
+
+// NewOpenAICompatibleEmbedder returns an Embedder for any OpenAI-compatible
+// embeddings endpoint, e.g. a local server at "http://localhost:11434/v1".
+// apiKey may be empty for servers that do not check it.
+func NewOpenAICompatibleEmbedder(baseURL, apiKey, model string) (Embedder, error) {
+	baseURL = strings.TrimSpace(baseURL)
+	if baseURL == "" {
+		return nil, errors.New("embeddings endpoint requires a base URL")
+	}
+	model = strings.TrimSpace(model)
+	if model == "" {
+		return nil, errors.New("embeddings endpoint requires a model")
+	}
+	return &openAIEmbedder{name: "embeddings", baseURL: baseURL, apiKey: strings.TrimSpace(apiKey), model: model}, nil
+}
+
+// EmbeddingModel implements Embedder.
+func (o *openAIProvider) EmbeddingModel() string { return defaultOpenAIEmbeddingModel }
+
+// Embed implements Embedder with OpenAI's embeddings API.
+func (o *openAIProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	e := openAIEmbedder{name: "openai", baseURL: o.baseURL, apiKey: o.apiKey, model: defaultOpenAIEmbeddingModel}
+	return e.Embed(ctx, texts)
+}
+
+func (e *openAIEmbedder) EmbeddingModel() string { return e.model }
+
+type openAIEmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type openAIEmbeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+func (e *openAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	endpoint := strings.TrimRight(e.baseURL, "/") + "/embeddings"
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += embeddingBatchSize {
+		batch := texts[start:min(start+embeddingBatchSize, len(texts))]
+		var decoded openAIEmbeddingResponse
+		headers := map[string]string{}
+		if e.apiKey != "" {
+			headers["Authorization"] = "Bearer " + e.apiKey
+		}
+		if err := postEmbeddings(ctx, e.name, endpoint, headers, openAIEmbeddingRequest{Model: e.model, Input: batch}, &decoded); err != nil {
+			return nil, err
+		}
+		if len(decoded.Data) != len(batch) {
+			return nil, fmt.Errorf("%s embeddings returned %d vectors for %d inputs", e.name, len(decoded.Data), len(batch))
+		}
+		out := make([][]float32, len(batch))
+		for _, item := range decoded.Data {
+			if item.Index < 0 || item.Index >= len(batch) {
+				return nil, fmt.Errorf("%s embeddings returned out-of-range index %d", e.name, item.Index)
+			}
+			out[item.Index] = item.Embedding
+		}
+		vectors = append(vectors, out...)
+	}
+	return vectors, nil
+}
+
+type geminiEmbedRequest struct {
+	Model   string        `json:"model"`
+	Content geminiContent `json:"content"`
+}
+
+type geminiBatchEmbedRequest struct {
+	Requests []geminiEmbedRequest `json:"requests"`
+}
+
+type geminiBatchEmbedResponse struct {
+	Embeddings []struct {
+		Values []float32 `json:"values"`
+	} `json:"embeddings"`
+}
+
+// EmbeddingModel implements Embedder.
+func (g *geminiProvider) EmbeddingModel() string { return defaultGeminiEmbeddingModel }
+
+// Embed implements Embedder with Gemini's batchEmbedContents.
+func (g *geminiProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if g.apiKey == "" {
+		return nil, errors.New("gemini API key is required")
+	}
+	model := "models/" + defaultGeminiEmbeddingModel
+	endpoint := fmt.Sprintf("%s/%s:batchEmbedContents", g.baseURL, model)
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += embeddingBatchSize {
+		batch := texts[start:min(start+embeddingBatchSize, len(texts))]
+		payload := geminiBatchEmbedRequest{Requests: make([]geminiEmbedRequest, len(batch))}
+		for i, text := range batch {
+			payload.Requests[i] = geminiEmbedRequest{Model: model, Content: geminiContent{Parts: []geminiPart{{Text: text}}}}
+		}
+		var decoded geminiBatchEmbedResponse
+		if err := postEmbeddings(ctx, "gemini", endpoint, map[string]string{"x-goog-api-key": g.apiKey}, payload, &decoded); err != nil {
+			return nil, err
+		}
+		if len(decoded.Embeddings) != len(batch) {
+			return nil, fmt.Errorf("gemini embeddings returned %d vectors for %d inputs", len(decoded.Embeddings), len(batch))
+		}
+		for _, embedding := range decoded.Embeddings {
+			vectors = append(vectors, embedding.Values)
+		}
+	}
+	return vectors, nil
+}
+
+func postEmbeddings(ctx context.Context, providerName, endpoint string, headers map[string]string, payload, out any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s embeddings payload: %w", providerName, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create %s embeddings request: %w", providerName, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s embeddings request failed: %w", providerName, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		limitedBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		log.Printf("%s embeddings returned status %d: %s", providerName, resp.StatusCode, string(limitedBody))
+		return fmt.Errorf("%s embeddings API returned non-2xx status %d", providerName, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s embeddings payload: %w", providerName, err)
+	}
+	return nil
+}
@@ -1,19 +1,27 @@
 package provider
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"log"
+	"net/http"
 	"strings"
 
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/llms/googleai"
-	"encoding/json"
 )
 
+const geminiAPIBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+
 type geminiProvider struct {
-	model  string
-	client *googleai.GoogleAI
+	model   string
+	client  *googleai.GoogleAI
+	apiKey  string
+	baseURL string // REST endpoint root; the langchaingo client always uses the public API
 }
 
 func newGeminiProvider(cfg Config) (LLMProvider, error) {
@@ -35,9 +43,16 @@ func newGeminiProvider(cfg Config) (LLMProvider, error) {
 		return nil, fmt.Errorf("failed to init gemini client: %w", err)
 	}
 
+	baseURL := geminiAPIBaseURL
+	if trimmed := strings.TrimRight(strings.TrimSpace(cfg.BaseURL), "/"); trimmed != "" {
+		baseURL = trimmed
+	}
+
 	return &geminiProvider{
-		client: client,
-		model:  model,
+		client:  client,
+		model:   model,
+		apiKey:  strings.TrimSpace(cfg.APIKey),
+		baseURL: baseURL,
 	}, nil
 }
 
@@ -46,6 +61,16 @@ func (g *geminiProvider) ListModels(_ context.Context) ([]ModelInfo, error) {
 }
 
 func (g *geminiProvider) Generate(ctx context.Context, prompt string) (string, string, error) {
+	if g.baseURL != geminiAPIBaseURL {
+		// The langchaingo client cannot be pointed elsewhere, so use the REST API.
+		payload := geminiGenerateRequest{
+			Contents:         []geminiContent{{Role: "user", Parts: []geminiPart{{Text: prompt}}}},
+			GenerationConfig: geminiGenerationConfig{Temperature: 0.1},
+		}
+		debugPayload := payload
+		debugPayload.Contents = []geminiContent{{Role: "user", Parts: []geminiPart{{Text: "[request_text]"}}}}
+		return g.generateContent(ctx, payload, debugPayload)
+	}
 	if g.client == nil {
 		return "", "", errors.New("gemini client is not configured")
 	}
@@ -70,3 +95,180 @@ func (g *geminiProvider) Generate(ctx context.Context, prompt string) (string, s
 	}
 	return output, debugString, nil
 }
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiGenerationConfig struct {
+	Temperature      float64        `json:"temperature"`
+	ResponseMIMEType string         `json:"responseMimeType,omitempty"`
+	ResponseSchema   map[string]any `json:"responseSchema,omitempty"`
+}
+
+type geminiGenerateRequest struct {
+	SystemInstruction *geminiContent         `json:"systemInstruction,omitempty"`
+	Contents          []geminiContent        `json:"contents"`
+	GenerationConfig  geminiGenerationConfig `json:"generationConfig"`
+}
+
+type geminiGenerateResponse struct {
+	Candidates []struct {
+		Content geminiContent `json:"content"`
+	} `json:"candidates"`
+}
+
+// GenerateStructured implements StructuredGenerator with Gemini's responseSchema.
+// The langchaingo client does not expose it, so this calls the REST API directly.
+func (g *geminiProvider) GenerateStructured(ctx context.Context, prompt string, schema Schema) (string, string, error) {
+	if len(schema.Definition) == 0 {
+		return "", "", errEmptySchema
+	}
+	payload := geminiGenerateRequest{
+		Contents: []geminiContent{{Role: "user", Parts: []geminiPart{{Text: prompt}}}},
+		GenerationConfig: geminiGenerationConfig{
+			Temperature:      0.1,
+			ResponseMIMEType: "application/json",
+			ResponseSchema:   geminiSchema(schema.Definition),
+		},
+	}
+	debugPayload := payload
+	debugPayload.Contents = []geminiContent{{Role: "user", Parts: []geminiPart{{Text: "[request_text]"}}}}
+	raw, apiCall, err := g.generateContent(ctx, payload, debugPayload)
+	return raw, apiCall, structuredError(err)
+}
+
+// Chat implements LLMProvider through the REST API, since the langchaingo client
+// rejects system messages. Assistant turns use Gemini's "model" role.
+func (g *geminiProvider) Chat(ctx context.Context, messages []Message) (string, string, error) {
+	if err := validateConversation(messages); err != nil {
+		return "", "", err
+	}
+	payload := geminiGenerateRequest{GenerationConfig: geminiGenerationConfig{Temperature: 0.1}}
+	debugPayload := payload
+	payload.SystemInstruction, payload.Contents = geminiConversation(messages, false)
+	debugPayload.SystemInstruction, debugPayload.Contents = geminiConversation(messages, true)
+	return g.generateContent(ctx, payload, debugPayload)
+}
+
+// geminiConversation splits messages into the system instruction and the contents
+// of a request. With redact set the texts are replaced by placeholders.
+func geminiConversation(messages []Message, redact bool) (*geminiContent, []geminiContent) {
+	var system *geminiContent
+	var contents []geminiContent
+	for _, m := range messages {
+		part := geminiPart{Text: m.Content}
+		if redact {
+			part.Text = "[request_text]"
+		}
+		switch m.Role {
+		case RoleSystem:
+			if system == nil {
+				system = &geminiContent{}
+			}
+			system.Parts = append(system.Parts, part)
+		case RoleAssistant:
+			contents = append(contents, geminiContent{Role: "model", Parts: []geminiPart{part}})
+		default:
+			contents = append(contents, geminiContent{Role: "user", Parts: []geminiPart{part}})
+		}
+	}
+	return system, contents
+}
+
+// generateContent posts payload to the generateContent endpoint and returns the
+// text of the first candidate. debugPayload is the sanitized body for the debug string.
+func (g *geminiProvider) generateContent(ctx context.Context, payload, debugPayload geminiGenerateRequest) (string, string, error) {
+	if g.apiKey == "" {
+		return "", "", errors.New("gemini API key is required")
+	}
+	endpoint := fmt.Sprintf("%s/models/%s:generateContent", g.baseURL, strings.TrimPrefix(g.model, "models/"))
+	debug := map[string]any{
+		"provider": "gemini",
+		"endpoint": endpoint,
+		"method":   http.MethodPost,
+		"headers": map[string]string{
+			"x-goog-api-key": "[apikey]",
+			"Content-Type":   "application/json",
+		},
+		"body": debugPayload,
+	}
+	debugString := ""
+	if data, err := json.MarshalIndent(debug, "", "  "); err == nil {
+		debugString = string(data)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", debugString, fmt.Errorf("failed to marshal gemini payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", debugString, fmt.Errorf("failed to create gemini request: %w", err)
+	}
+	req.Header.Set("x-goog-api-key", g.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Printf("gemini request failed (model=%s): %v", g.model, err)
+		return "", debugString, fmt.Errorf("gemini request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		limitedBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		log.Printf("gemini returned status %d for model %s: %s", resp.StatusCode, g.model, string(limitedBody))
+		return "", debugString, &statusError{api: "gemini API", status: resp.StatusCode, body: string(limitedBody)}
+	}
+
+	var decoded geminiGenerateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return "", debugString, fmt.Errorf("failed to decode gemini payload: %w", err)
+	}
+	var text strings.Builder
+	if len(decoded.Candidates) > 0 {
+		for _, part := range decoded.Candidates[0].Content.Parts {
+			text.WriteString(part.Text)
+		}
+	}
+	if strings.TrimSpace(text.String()) == "" {
+		return "", debugString, errors.New("gemini response did not contain text output")
+	}
+	return strings.TrimSpace(text.String()), debugString, nil
+}
+
+// geminiSchema converts a JSON schema to the OpenAPI subset Gemini accepts for
+// responseSchema, which rejects keywords such as additionalProperties.
+func geminiSchema(def map[string]any) map[string]any {
+	out := make(map[string]any, len(def))
+	for key, value := range def {
+		switch key {
+		case "additionalProperties", "$schema", "strict":
+			continue
+		case "properties":
+			if props, ok := value.(map[string]any); ok {
+				converted := make(map[string]any, len(props))
+				for name, prop := range props {
+					if sub, ok := prop.(map[string]any); ok {
+						converted[name] = geminiSchema(sub)
+					} else {
+						converted[name] = prop
+					}
+				}
+				value = converted
+			}
+		case "items":
+			if sub, ok := value.(map[string]any); ok {
+				value = geminiSchema(sub)
+			}
+		}
+		out[key] = value
+	}
+	return out
+}
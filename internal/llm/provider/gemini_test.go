@@ -0,0 +1,108 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// geminiTestServer answers generateContent with reply and records the request paths.
+func geminiTestServer(t *testing.T, reply string) (*httptest.Server, *[]string) {
+	t.Helper()
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.Header.Get("x-goog-api-key") != "test-key" {
+			http.Error(w, "missing key", http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"candidates": []any{map[string]any{"content": map[string]any{"parts": []any{map[string]any{"text": reply}}}}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &paths
+}
+
+func newTestGemini(t *testing.T, baseURL string) *geminiProvider {
+	t.Helper()
+	llm, err := Factory(Config{Provider: "gemini", Model: "gemini-test", APIKey: "test-key", BaseURL: baseURL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return llm.(*geminiProvider)
+}
+
+func TestGeminiRESTCallsUseBaseURL(t *testing.T) {
+	server, paths := geminiTestServer(t, `{"files": []}`)
+	g := newTestGemini(t, server.URL+"/v1beta/")
+
+	schema := Schema{Name: "selection", Definition: map[string]any{"type": "object"}}
+	if out, _, err := g.GenerateStructured(context.Background(), "pick files", schema); err != nil || out != `{"files": []}` {
+		t.Fatalf("GenerateStructured = %q, %v", out, err)
+	}
+	if out, _, err := g.Generate(context.Background(), "hello"); err != nil || out != `{"files": []}` {
+		t.Fatalf("Generate = %q, %v", out, err)
+	}
+	want := "/v1beta/models/gemini-test:generateContent"
+	if len(*paths) != 2 || (*paths)[0] != want || (*paths)[1] != want {
+		t.Errorf("requests = %v, want 2 to %s", *paths, want)
+	}
+}
+
+func TestGeminiEmbedUsesBaseURL(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		json.NewEncoder(w).Encode(map[string]any{
+			"embeddings": []any{map[string]any{"values": []float32{1, 0}}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	g := newTestGemini(t, server.URL)
+
+	vectors, err := g.Embed(context.Background(), []string{"query"})
+	if err != nil || len(vectors) != 1 {
+		t.Fatalf("Embed = %v, %v", vectors, err)
+	}
+	if want := "/models/" + defaultGeminiEmbeddingModel + ":batchEmbedContents"; len(paths) != 1 || paths[0] != want {
+		t.Errorf("requests = %v, want one to %s", paths, want)
+	}
+}
+
+func TestGeminiChatUsesBaseURL(t *testing.T) {
+	var request geminiGenerateRequest
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		json.NewDecoder(r.Body).Decode(&request)
+		json.NewEncoder(w).Encode(map[string]any{
+			"candidates": []any{map[string]any{"content": map[string]any{"parts": []any{map[string]any{"text": "second answer"}}}}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	g := newTestGemini(t, server.URL)
+
+	reply, _, err := g.Chat(context.Background(), []Message{
+		{Role: RoleSystem, Content: "be brief"},
+		{Role: RoleUser, Content: "first"},
+		{Role: RoleAssistant, Content: "first answer"},
+		{Role: RoleUser, Content: "second"},
+	})
+	if err != nil || reply != "second answer" {
+		t.Fatalf("Chat = %q, %v", reply, err)
+	}
+	if want := "/models/gemini-test:generateContent"; len(paths) != 1 || paths[0] != want {
+		t.Errorf("requests = %v, want one to %s", paths, want)
+	}
+	var roles []string
+	for _, c := range request.Contents {
+		roles = append(roles, c.Role)
+	}
+	if request.SystemInstruction == nil || request.SystemInstruction.Parts[0].Text != "be brief" || strings.Join(roles, ",") != "user,model,user" {
+		t.Errorf("request = %+v", request)
+	}
+}
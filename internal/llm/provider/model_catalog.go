@@ -4,36 +4,36 @@ import "fmt"
 
 var openAIModelCatalog = []ModelInfo{
 	// GPT-5 family (latest reasoning-capable models)
-	{Name: "gpt-5.1", Description: "Latest GPT-5.1 flagship for complex reasoning and coding tasks"},
-	{Name: "gpt-5", Description: "Previous GPT-5 flagship reasoning model"},
-	{Name: "gpt-5-mini", Description: "Cost-optimized GPT-5 mini model"},
-	{Name: "gpt-5-nano", Description: "High-throughput GPT-5 nano model"},
+	{Name: "gpt-5.1", Description: "Latest GPT-5.1 flagship for complex reasoning and coding tasks", ContextWindow: 400_000},
+	{Name: "gpt-5", Description: "Previous GPT-5 flagship reasoning model", ContextWindow: 400_000},
+	{Name: "gpt-5-mini", Description: "Cost-optimized GPT-5 mini model", ContextWindow: 400_000},
+	{Name: "gpt-5-nano", Description: "High-throughput GPT-5 nano model", ContextWindow: 400_000},
 
 	// GPT-4 family
-	{Name: "gpt-4o-mini", Description: "Latest GPT-4o mini for general reasoning"},
-	{Name: "gpt-4.1-mini", Description: "GPT-4.1 mini tier"},
-	{Name: "o4-mini", Description: "Reasoning optimized 04-mini"},
-	{Name: "gpt-4o", Description: "Full GPT-4o"},
-	{Name: "gpt-4.1", Description: "Full GPT-4.1"},
+	{Name: "gpt-4o-mini", Description: "Latest GPT-4o mini for general reasoning", ContextWindow: 128_000},
+	{Name: "gpt-4.1-mini", Description: "GPT-4.1 mini tier", ContextWindow: 1_047_576},
+	{Name: "o4-mini", Description: "Reasoning optimized 04-mini", ContextWindow: 200_000},
+	{Name: "gpt-4o", Description: "Full GPT-4o", ContextWindow: 128_000},
+	{Name: "gpt-4.1", Description: "Full GPT-4.1", ContextWindow: 1_047_576},
 }
 
 var openRouterModelCatalog = []ModelInfo{
-	{Name: "openai/gpt-5", Description: "GPT-5 family routed via OpenRouter"},
-	{Name: "anthropic/claude-4.5-sonnet", Description: "Claude 4.5 Sonnet via OpenRouter"},
-	{Name: "google/gemini-2.5-pro", Description: "Gemini 2.5 Pro via OpenRouter"},
-	{Name: "google/gemini-2.5-flash", Description: "Gemini 2.5 Flash via OpenRouter"},
-	{Name: "google/gemini-2.0-flash", Description: "Gemini 2.0 Flash via OpenRouter"},
-	{Name: "openai/gpt-4o-mini", Description: "GPT-4o mini from OpenRouter catalog"},
-	{Name: "meta-llama/llama-3.1-70b-instruct", Description: "Llama 3.1 70B Instruct via OpenRouter"},
-	{Name: "x-ai/grok-code-fast-1", Description: "Grok Code Fast 1 via OpenRouter"},
-	{Name: "x-ai/grok-4-fast", Description: "Grok 4 Fast via OpenRouter"},
-	{Name: "minimax/minimax-m2", Description: "Minimax M2 via OpenRouter"},
-	{Name: "z-ai/glm-4.6", Description: "GLM 4.6 via OpenRouter"},
+	{Name: "openai/gpt-5", Description: "GPT-5 family routed via OpenRouter", ContextWindow: 400_000},
+	{Name: "anthropic/claude-4.5-sonnet", Description: "Claude 4.5 Sonnet via OpenRouter", ContextWindow: 200_000},
+	{Name: "google/gemini-2.5-pro", Description: "Gemini 2.5 Pro via OpenRouter", ContextWindow: 1_048_576},
+	{Name: "google/gemini-2.5-flash", Description: "Gemini 2.5 Flash via OpenRouter", ContextWindow: 1_048_576},
+	{Name: "google/gemini-2.0-flash", Description: "Gemini 2.0 Flash via OpenRouter", ContextWindow: 1_048_576},
+	{Name: "openai/gpt-4o-mini", Description: "GPT-4o mini from OpenRouter catalog", ContextWindow: 128_000},
+	{Name: "meta-llama/llama-3.1-70b-instruct", Description: "Llama 3.1 70B Instruct via OpenRouter", ContextWindow: 131_072},
+	{Name: "x-ai/grok-code-fast-1", Description: "Grok Code Fast 1 via OpenRouter", ContextWindow: 256_000},
+	{Name: "x-ai/grok-4-fast", Description: "Grok 4 Fast via OpenRouter", ContextWindow: 2_000_000},
+	{Name: "minimax/minimax-m2", Description: "Minimax M2 via OpenRouter", ContextWindow: 204_800},
+	{Name: "z-ai/glm-4.6", Description: "GLM 4.6 via OpenRouter", ContextWindow: 202_752},
 }
 
 var geminiModelCatalog = []ModelInfo{
-	{Name: "gemini-2.5-pro", Description: "Most capable Gemini 2.5 Pro"},
-	{Name: "gemini-2.5-flash", Description: "Flash"},
+	{Name: "gemini-2.5-pro", Description: "Most capable Gemini 2.5 Pro", ContextWindow: 1_048_576},
+	{Name: "gemini-2.5-flash", Description: "Flash", ContextWindow: 1_048_576},
 }
 
 func cloneModelCatalog(models []ModelInfo) []ModelInfo {
@@ -58,3 +58,18 @@ func ModelCatalog(providerName string) ([]ModelInfo, error) {
 		return nil, fmt.Errorf("provider %s is not supported", providerName)
 	}
 }
+
+// ModelContextWindow returns the context window of a catalog model in tokens, or 0
+// when the model is not in the catalog.
+func ModelContextWindow(providerName, model string) int64 {
+	models, err := ModelCatalog(providerName)
+	if err != nil {
+		return 0
+	}
+	for _, m := range models {
+		if m.Name == model {
+			return m.ContextWindow
+		}
+	}
+	return 0
+}
@@ -72,7 +72,7 @@ func (o *openAIProvider) Generate(ctx context.Context, prompt string) (string, s
 	// For GPT-5 family models, use the Responses API with reasoning and verbosity controls,
 	// and **never** send temperature/top_p/logprobs.
 	if isGPT5FamilyModel(o.model) {
-		return o.generateViaResponsesAPI(ctx, prompt)
+		return o.generateViaResponsesAPI(ctx, prompt, "[request_text]", nil)
 	}
 
 	// For non-GPT-5 models we keep the existing behaviour with a small temperature.
@@ -95,12 +95,22 @@ type responsesAPIReasoningConfig struct {
 }
 
 type responsesAPITextConfig struct {
-	Verbosity string `json:"verbosity"`
+	Verbosity string                    `json:"verbosity"`
+	Format    *responsesAPIOutputFormat `json:"format,omitempty"`
+}
+
+// responsesAPIOutputFormat is the Responses API's text.format; the json_schema
+// type enforces a schema on the reply.
+type responsesAPIOutputFormat struct {
+	Type   string         `json:"type"`
+	Name   string         `json:"name"`
+	Strict bool           `json:"strict"`
+	Schema map[string]any `json:"schema"`
 }
 
 type responsesAPIRequest struct {
 	Model           string                      `json:"model"`
-	Input           string                      `json:"input"`
+	Input           any                         `json:"input"` // The prompt, or the messages of a conversation
 	Reasoning       responsesAPIReasoningConfig `json:"reasoning"`
 	Text            responsesAPITextConfig      `json:"text"`
 	MaxOutputTokens int                         `json:"max_output_tokens,omitempty"`
@@ -111,7 +121,74 @@ type responsesAPIResponse struct {
 	OutputText string          `json:"output_text"`
 }
 
-func (o *openAIProvider) generateViaResponsesAPI(ctx context.Context, prompt string) (string, string, error) {
+// GenerateStructured implements StructuredGenerator. GPT-5 models get the schema as
+// the Responses API text.format; other models as the Chat Completions response_format.
+func (o *openAIProvider) GenerateStructured(ctx context.Context, prompt string, schema Schema) (string, string, error) {
+	if len(schema.Definition) == 0 {
+		return "", "", errEmptySchema
+	}
+	if isGPT5FamilyModel(o.model) {
+		raw, apiCall, err := o.generateViaResponsesAPI(ctx, prompt, "[request_text]", &responsesAPIOutputFormat{
+			Type:   "json_schema",
+			Name:   schema.Name,
+			Strict: true,
+			Schema: schema.Definition,
+		})
+		return raw, apiCall, structuredError(err)
+	}
+
+	apiKey := strings.TrimSpace(o.apiKey)
+	if apiKey == "" {
+		return "", "", errors.New("openai API key is required")
+	}
+	endpoint := strings.TrimRight(o.baseURL, "/") + "/chat/completions"
+	payload := openAIChatRequest{
+		Model:          o.model,
+		Messages:       []chatCompletionMessage{{Role: "user", Content: prompt}},
+		Temperature:    0.1,
+		ResponseFormat: jsonSchemaResponseFormat(schema),
+	}
+	debugPayload := payload
+	debugPayload.Messages = []chatCompletionMessage{{Role: "user", Content: "[request_text]"}}
+	raw, apiCall, err := postChatCompletion(ctx, "openai", endpoint, apiKey, o.model, payload, debugPayload)
+	return raw, apiCall, structuredError(err)
+}
+
+type openAIChatRequest struct {
+	Model          string                  `json:"model"`
+	Messages       []chatCompletionMessage `json:"messages"`
+	Temperature    float64                 `json:"temperature"`
+	ResponseFormat *chatResponseFormat     `json:"response_format,omitempty"`
+}
+
+// Chat implements LLMProvider. GPT-5 models get the messages as Responses API input
+// items; other models go through Chat Completions.
+func (o *openAIProvider) Chat(ctx context.Context, messages []Message) (string, string, error) {
+	if err := validateConversation(messages); err != nil {
+		return "", "", err
+	}
+	if isGPT5FamilyModel(o.model) {
+		return o.generateViaResponsesAPI(ctx, chatMessages(messages, false), chatMessages(messages, true), nil)
+	}
+
+	apiKey := strings.TrimSpace(o.apiKey)
+	if apiKey == "" {
+		return "", "", errors.New("openai API key is required")
+	}
+	endpoint := strings.TrimRight(o.baseURL, "/") + "/chat/completions"
+	payload := openAIChatRequest{
+		Model:       o.model,
+		Messages:    chatMessages(messages, false),
+		Temperature: 0.1,
+	}
+	debugPayload := payload
+	debugPayload.Messages = chatMessages(messages, true)
+	return postChatCompletion(ctx, "openai", endpoint, apiKey, o.model, payload, debugPayload)
+}
+
+// generateViaResponsesAPI sends input, a prompt string or conversation messages, to
+// the Responses API. debugInput stands in for it in the debug representation.
+func (o *openAIProvider) generateViaResponsesAPI(ctx context.Context, input, debugInput any, format *responsesAPIOutputFormat) (string, string, error) {
 	apiKey := strings.TrimSpace(o.apiKey)
 	if apiKey == "" {
 		return "", "", errors.New("openai API key is required for GPT-5 models")
@@ -125,12 +202,13 @@ func (o *openAIProvider) generateViaResponsesAPI(ctx context.Context, prompt str
 
 	payload := responsesAPIRequest{
 		Model: o.model,
-		Input: prompt,
+		Input: input,
 		Reasoning: responsesAPIReasoningConfig{
 			Effort: "medium",
 		},
 		Text: responsesAPITextConfig{
 			Verbosity: "high",
+			Format:    format,
 		},
 		// Явно ограничиваем длину ответа, чтобы модель уверенно возвращала сообщение.
 		// Значение можно будет вынести в настройки при необходимости.
@@ -140,12 +218,13 @@ func (o *openAIProvider) generateViaResponsesAPI(ctx context.Context, prompt str
 	// Build sanitized debug view BEFORE marshalling real payload.
 	debugPayload := responsesAPIRequest{
 		Model: o.model,
-		Input: "[request_text]",
+		Input: debugInput,
 		Reasoning: responsesAPIReasoningConfig{
 			Effort: "medium",
 		},
 		Text: responsesAPITextConfig{
 			Verbosity: "high",
+			Format:    format,
 		},
 		MaxOutputTokens: payload.MaxOutputTokens,
 	}
@@ -191,7 +270,7 @@ func (o *openAIProvider) generateViaResponsesAPI(ctx context.Context, prompt str
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
 		limitedBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
 		log.Printf("openai responses API returned status %d: %s", resp.StatusCode, string(limitedBody))
-		return "", debugString, fmt.Errorf("openai responses API returned non-2xx status %d", resp.StatusCode)
+		return "", debugString, &statusError{api: "openai responses API", status: resp.StatusCode, body: string(limitedBody)}
 	}
 
 	var decoded responsesAPIResponse
@@ -1,14 +1,10 @@
 package provider
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"log"
-	"net/http"
 	"strings"
 
 	"github.com/tmc/langchaingo/llms"
@@ -68,7 +64,7 @@ func (o *openRouterProvider) Generate(ctx context.Context, prompt string) (strin
 	// Для моделей семейства GPT‑5 используем ручной вызов OpenRouter Chat Completions API
 	// с явным указанием reasoning.effort и text.verbosity и без передачи temperature.
 	if isGPT5FamilyModel(o.model) {
-		return o.generateViaOpenRouterAPI(ctx, prompt)
+		return o.generateViaOpenRouterAPI(ctx, []Message{{Role: RoleUser, Content: prompt}}, nil)
 	}
 
 	// Для остальных моделей сохраняем текущее поведение через langchaingo.
@@ -85,19 +81,6 @@ func (o *openRouterProvider) Generate(ctx context.Context, prompt string) (strin
 	return output, debug, nil
 }
 
-type openRouterChatMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
-}
-
-type openRouterChatChoice struct {
-	Message openRouterChatMessage `json:"message"`
-}
-
-type openRouterChatResponse struct {
-	Choices []openRouterChatChoice `json:"choices"`
-}
-
 type openRouterReasoningConfig struct {
 	Effort string `json:"effort"`
 }
@@ -107,16 +90,35 @@ type openRouterTextConfig struct {
 }
 
 type openRouterChatRequest struct {
-	Model     string                   `json:"model"`
-	Messages  []openRouterChatMessage  `json:"messages"`
-	Reasoning openRouterReasoningConfig `json:"reasoning"`
-	Text      openRouterTextConfig      `json:"text"`
+	Model          string                     `json:"model"`
+	Messages       []chatCompletionMessage    `json:"messages"`
+	Reasoning      *openRouterReasoningConfig `json:"reasoning,omitempty"`
+	Text           *openRouterTextConfig      `json:"text,omitempty"`
+	ResponseFormat *chatResponseFormat        `json:"response_format,omitempty"`
+}
+
+// GenerateStructured implements StructuredGenerator through the Chat Completions
+// response_format; OpenRouter passes it on to models that support structured outputs.
+func (o *openRouterProvider) GenerateStructured(ctx context.Context, prompt string, schema Schema) (string, string, error) {
+	if len(schema.Definition) == 0 {
+		return "", "", errEmptySchema
+	}
+	raw, apiCall, err := o.generateViaOpenRouterAPI(ctx, []Message{{Role: RoleUser, Content: prompt}}, jsonSchemaResponseFormat(schema))
+	return raw, apiCall, structuredError(err)
+}
+
+// Chat implements LLMProvider through the Chat Completions API for every model.
+func (o *openRouterProvider) Chat(ctx context.Context, messages []Message) (string, string, error) {
+	if err := validateConversation(messages); err != nil {
+		return "", "", err
+	}
+	return o.generateViaOpenRouterAPI(ctx, messages, nil)
 }
 
-func (o *openRouterProvider) generateViaOpenRouterAPI(ctx context.Context, prompt string) (string, string, error) {
+func (o *openRouterProvider) generateViaOpenRouterAPI(ctx context.Context, messages []Message, format *chatResponseFormat) (string, string, error) {
 	apiKey := strings.TrimSpace(o.apiKey)
 	if apiKey == "" {
-		return "", "", errors.New("openrouter API key is required for GPT-5 models")
+		return "", "", errors.New("openrouter API key is required")
 	}
 
 	baseURL := strings.TrimSpace(o.baseURL)
@@ -126,97 +128,20 @@ func (o *openRouterProvider) generateViaOpenRouterAPI(ctx context.Context, promp
 	endpoint := strings.TrimRight(baseURL, "/") + "/chat/completions"
 
 	payload := openRouterChatRequest{
-		Model: o.model,
-		Messages: []openRouterChatMessage{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
-		Reasoning: openRouterReasoningConfig{
-			Effort: "medium",
-		},
-		Text: openRouterTextConfig{
-			Verbosity: "high",
-		},
+		Model:          o.model,
+		Messages:       chatMessages(messages, false),
+		ResponseFormat: format,
 	}
-
-	// Build sanitized debug view BEFORE marshalling real payload.
-	debugPayload := openRouterChatRequest{
-		Model: o.model,
-		Messages: []openRouterChatMessage{
-			{
-				Role:    "user",
-				Content: "[request_text]",
-			},
-		},
-		Reasoning: openRouterReasoningConfig{
-			Effort: "medium",
-		},
-		Text: openRouterTextConfig{
-			Verbosity: "high",
-		},
-	}
-
-	debug := map[string]any{
-		"provider": "openrouter",
-		"endpoint": endpoint,
-		"method":   http.MethodPost,
-		"headers": map[string]string{
-			"Authorization": "Bearer [apikey]",
-			"Content-Type":  "application/json",
-		},
-		"body": debugPayload,
-	}
-	debugBytes, err := json.MarshalIndent(debug, "", "  ")
-	if err != nil {
-		debugBytes = nil
-	}
-	debugString := string(debugBytes)
-
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return "", debugString, fmt.Errorf("failed to marshal OpenRouter chat payload: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
-	if err != nil {
-		return "", debugString, fmt.Errorf("failed to create OpenRouter chat request: %w", err)
-	}
-	req.Header.Set("Authorization", "Bearer "+apiKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		log.Printf("openrouter chat request failed (model=%s): %v", o.model, err)
-		return "", debugString, fmt.Errorf("openrouter chat request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-		limitedBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
-		log.Printf("openrouter chat returned status %d for model %s: %s", resp.StatusCode, o.model, string(limitedBody))
-		return "", debugString, fmt.Errorf("openrouter chat API returned non-2xx status %d", resp.StatusCode)
-	}
-
-	var decoded openRouterChatResponse
-	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
-		log.Printf("failed to decode openrouter chat payload for model %s: %v", o.model, err)
-		return "", debugString, fmt.Errorf("failed to decode openrouter chat payload: %w", err)
-	}
-
-	if len(decoded.Choices) == 0 {
-		log.Printf("openrouter chat response did not contain any choices for model %s", o.model)
-		return "", debugString, errors.New("openrouter chat response did not contain any choices")
+	if isGPT5FamilyModel(o.model) {
+		payload.Reasoning = &openRouterReasoningConfig{Effort: "medium"}
+		payload.Text = &openRouterTextConfig{Verbosity: "high"}
 	}
 
-	text := strings.TrimSpace(decoded.Choices[0].Message.Content)
-	if text == "" {
-		log.Printf("openrouter chat response contained empty message content for model %s", o.model)
-		return "", debugString, errors.New("openrouter chat response did not contain text output")
-	}
+	// Sanitized debug view: same request without the prompt text.
+	debugPayload := payload
+	debugPayload.Messages = chatMessages(messages, true)
 
-	return text, debugString, nil
+	return postChatCompletion(ctx, "openrouter", endpoint, apiKey, o.model, payload, debugPayload)
 }
 
 // buildGenericAPICallDebug builds a high-level debug representation for SDK-based calls (non‑GPT‑5).
@@ -12,12 +12,17 @@ type Config struct {
 	Model    string
 	APIKey   string
 	BaseURL  string
+	// CassetteDir holds recorded calls: the "replay" provider serves them, and with
+	// Record set the calls of any other provider are saved there.
+	CassetteDir string
+	Record      bool
 }
 
 // ModelInfo contains provider specific model metadata.
 type ModelInfo struct {
-	Name        string `json:"name"`
-	Description string `json:"description,omitempty"`
+	Name          string `json:"name"`
+	Description   string `json:"description,omitempty"`
+	ContextWindow int64  `json:"contextWindow,omitempty"` // Input and output tokens together; 0 when unknown
 }
 
 // LLMProvider describes the common capabilities we need from each vendor specific client.
@@ -29,20 +34,33 @@ type LLMProvider interface {
 	// - a sanitized debug representation of the API call (no API keys, no raw prompt; placeholders instead),
 	// - and an error if the call failed.
 	Generate(ctx context.Context, prompt string) (string, string, error)
+	// Chat continues a conversation. messages are the turns so far, oldest first, ending
+	// with a user turn; it returns the assistant reply and the debug representation like
+	// Generate. Earlier turns are resent unchanged, so vendors can reuse their prompt
+	// cache for the shared prefix.
+	Chat(ctx context.Context, messages []Message) (string, string, error)
 }
 
 // Factory builds provider implementations based on the given configuration.
 func Factory(cfg Config) (LLMProvider, error) {
+	var llm LLMProvider
+	var err error
 	switch cfg.Provider {
 	case "", "none":
 		return nil, errors.New("provider is not configured")
+	case "replay":
+		return newReplayProvider(cfg)
 	case "openai":
-		return newOpenAIProvider(cfg)
+		llm, err = newOpenAIProvider(cfg)
 	case "openrouter":
-		return newOpenRouterProvider(cfg)
+		llm, err = newOpenRouterProvider(cfg)
 	case "gemini":
-		return newGeminiProvider(cfg)
+		llm, err = newGeminiProvider(cfg)
 	default:
 		return nil, fmt.Errorf("provider %s is not supported", cfg.Provider)
 	}
+	if err != nil || !cfg.Record {
+		return llm, err
+	}
+	return NewRecorder(llm, cfg)
 }
@@ -0,0 +1,180 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// Schema is a JSON schema the model's reply must conform to.
+type Schema struct {
+	// Name identifies the schema in the request (letters, digits, "_" and "-").
+	Name string
+	// Definition is the JSON schema itself. Every object should list all of its
+	// properties in "required" and set "additionalProperties" to false, as strict
+	// modes demand.
+	Definition map[string]any
+}
+
+// StructuredGenerator is implemented by providers that can constrain a reply to a
+// JSON schema using the vendor's native structured-output feature. Callers should
+// type-assert for it and fall back to Generate with format instructions in the prompt.
+type StructuredGenerator interface {
+	// GenerateStructured behaves like Generate, but the returned text is a JSON
+	// document conforming to schema.
+	GenerateStructured(ctx context.Context, prompt string, schema Schema) (string, string, error)
+}
+
+var errEmptySchema = errors.New("structured output requires a schema definition")
+
+// ErrSchemaUnsupported is wrapped by GenerateStructured errors when the vendor
+// rejects the request for its schema, e.g. because the model has no structured
+// output. Only then is a retry through Generate worthwhile.
+var ErrSchemaUnsupported = errors.New("structured output is not supported for this model")
+
+// statusError is a non-2xx reply from a vendor API.
+type statusError struct {
+	api    string // e.g. "openai chat API"
+	status int
+	body   string // Start of the reply body
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s returned non-2xx status %d", e.api, e.status)
+}
+
+// schemaRejectionHints are fragments of the messages vendors send back when a model
+// cannot honour a response schema, compared in lower case.
+var schemaRejectionHints = []string{
+	"response_format", "json_schema", "response_schema", "responseschema",
+	"response_mime_type", "responsemimetype", "structured output", "no endpoints found",
+}
+
+// structuredError marks err with ErrSchemaUnsupported when it is a request error
+// whose message names the schema. Authentication, rate-limit and server errors,
+// and cancellations, are returned unchanged.
+func structuredError(err error) error {
+	var se *statusError
+	if !errors.As(err, &se) {
+		return err
+	}
+	switch se.status {
+	case http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity:
+	default:
+		return err
+	}
+	body := strings.ToLower(se.body)
+	for _, hint := range schemaRejectionHints {
+		if strings.Contains(body, hint) {
+			return fmt.Errorf("%w: %w", ErrSchemaUnsupported, err)
+		}
+	}
+	return err
+}
+
+// chatResponseFormat is the response_format of OpenAI-compatible chat completions.
+type chatResponseFormat struct {
+	Type       string          `json:"type"`
+	JSONSchema *chatJSONSchema `json:"json_schema,omitempty"`
+}
+
+type chatJSONSchema struct {
+	Name   string         `json:"name"`
+	Strict bool           `json:"strict"`
+	Schema map[string]any `json:"schema"`
+}
+
+func jsonSchemaResponseFormat(schema Schema) *chatResponseFormat {
+	return &chatResponseFormat{
+		Type: "json_schema",
+		JSONSchema: &chatJSONSchema{
+			Name:   schema.Name,
+			Strict: true,
+			Schema: schema.Definition,
+		},
+	}
+}
+
+type chatCompletionMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatCompletionChoice struct {
+	Message chatCompletionMessage `json:"message"`
+}
+
+type chatCompletionResponse struct {
+	Choices []chatCompletionChoice `json:"choices"`
+}
+
+// postChatCompletion sends payload to an OpenAI-compatible chat completions endpoint
+// and returns the first choice's text. debugPayload is the sanitized body shown in the
+// returned debug string.
+func postChatCompletion(ctx context.Context, providerName, endpoint, apiKey, model string, payload, debugPayload any) (string, string, error) {
+	debug := map[string]any{
+		"provider": providerName,
+		"endpoint": endpoint,
+		"method":   http.MethodPost,
+		"headers": map[string]string{
+			"Authorization": "Bearer [apikey]",
+			"Content-Type":  "application/json",
+		},
+		"body": debugPayload,
+	}
+	debugBytes, err := json.MarshalIndent(debug, "", "  ")
+	if err != nil {
+		debugBytes = nil
+	}
+	debugString := string(debugBytes)
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", debugString, fmt.Errorf("failed to marshal %s chat payload: %w", providerName, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", debugString, fmt.Errorf("failed to create %s chat request: %w", providerName, err)
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Printf("%s chat request failed (model=%s): %v", providerName, model, err)
+		return "", debugString, fmt.Errorf("%s chat request failed: %w", providerName, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		limitedBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		log.Printf("%s chat returned status %d for model %s: %s", providerName, resp.StatusCode, model, string(limitedBody))
+		return "", debugString, &statusError{api: providerName + " chat API", status: resp.StatusCode, body: string(limitedBody)}
+	}
+
+	var decoded chatCompletionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		log.Printf("failed to decode %s chat payload for model %s: %v", providerName, model, err)
+		return "", debugString, fmt.Errorf("failed to decode %s chat payload: %w", providerName, err)
+	}
+
+	if len(decoded.Choices) == 0 {
+		log.Printf("%s chat response did not contain any choices for model %s", providerName, model)
+		return "", debugString, fmt.Errorf("%s chat response did not contain any choices", providerName)
+	}
+
+	text := strings.TrimSpace(decoded.Choices[0].Message.Content)
+	if text == "" {
+		log.Printf("%s chat response contained empty message content for model %s", providerName, model)
+		return "", debugString, fmt.Errorf("%s chat response did not contain text output", providerName)
+	}
+
+	return text, debugString, nil
+}
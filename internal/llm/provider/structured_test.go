@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGenerateStructuredReportsUnsupportedSchemas(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		unsupported bool
+	}{
+		{"openai rejects response_format", http.StatusBadRequest,
+			`{"error": {"message": "Invalid parameter: 'response_format' of type 'json_schema' is not supported with this model."}}`, true},
+		{"openrouter has no endpoint", http.StatusNotFound,
+			`{"error": {"message": "No endpoints found that can handle the requested parameters."}}`, true},
+		{"unrelated bad request", http.StatusBadRequest,
+			`{"error": {"message": "This model's maximum context length is 8192 tokens."}}`, false},
+		{"authentication", http.StatusUnauthorized,
+			`{"error": {"message": "Incorrect API key provided; response_format ignored."}}`, false},
+		{"rate limit", http.StatusTooManyRequests, `{"error": {"message": "Rate limit reached"}}`, false},
+		{"server error", http.StatusInternalServerError, `{"error": {"message": "json_schema backend failed"}}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			t.Cleanup(server.Close)
+			for _, cfg := range []Config{
+				{Provider: "openai", Model: "gpt-4o-mini", APIKey: "test-key", BaseURL: server.URL},
+				{Provider: "openai", Model: "gpt-5-mini", APIKey: "test-key", BaseURL: server.URL},
+				{Provider: "openrouter", Model: "vendor/model", APIKey: "test-key", BaseURL: server.URL},
+				{Provider: "gemini", Model: "gemini-test", APIKey: "test-key", BaseURL: server.URL},
+			} {
+				llm, err := Factory(cfg)
+				if err != nil {
+					t.Fatal(err)
+				}
+				schema := Schema{Name: "selection", Definition: map[string]any{"type": "object"}}
+				_, _, err = llm.(StructuredGenerator).GenerateStructured(context.Background(), "pick files", schema)
+				if err == nil {
+					t.Fatalf("%s %s: want an error", cfg.Provider, cfg.Model)
+				}
+				if got := errors.Is(err, ErrSchemaUnsupported); got != tt.unsupported {
+					t.Errorf("%s %s: errors.Is(%v, ErrSchemaUnsupported) = %v, want %v", cfg.Provider, cfg.Model, err, got, tt.unsupported)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateStructuredKeepsCancellation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(server.Close)
+	llm, err := Factory(Config{Provider: "openai", Model: "gpt-4o-mini", APIKey: "test-key", BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = llm.(StructuredGenerator).GenerateStructured(ctx, "pick files", Schema{Name: "s", Definition: map[string]any{"type": "object"}})
+	if !errors.Is(err, context.Canceled) || errors.Is(err, ErrSchemaUnsupported) {
+		t.Errorf("err = %v, want a cancellation that is not ErrSchemaUnsupported", err)
+	}
+}
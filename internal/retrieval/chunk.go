@@ -0,0 +1,57 @@
+package retrieval
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Chunk is a span of lines of one file, the unit that gets embedded.
+type Chunk struct {
+	StartLine int // 1-based, inclusive
+	EndLine   int
+	Text      string
+}
+
+// Split cuts content into chunks of at most maxChars bytes, breaking at line ends.
+// Longer lines are cut. At most maxChunks chunks are returned, so the end of very
+// long files is not embedded.
+func Split(content string, maxChars, maxChunks int) []Chunk {
+	var chunks []Chunk
+	var b strings.Builder
+	start, line := 1, 0
+	flush := func() {
+		if strings.TrimSpace(b.String()) != "" {
+			chunks = append(chunks, Chunk{StartLine: start, EndLine: line, Text: b.String()})
+		}
+		b.Reset()
+		start = line + 1
+	}
+	for _, text := range strings.SplitAfter(content, "\n") {
+		if text == "" {
+			continue
+		}
+		line++
+		if len(text) > maxChars {
+			// Cut at a rune boundary so the chunk stays valid UTF-8.
+			cut := maxChars
+			for cut > 0 && !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+			text = text[:cut]
+		}
+		if b.Len()+len(text) > maxChars {
+			line--
+			flush()
+			line++
+		}
+		b.WriteString(text)
+		if len(chunks) >= maxChunks {
+			return chunks[:maxChunks]
+		}
+	}
+	flush()
+	if len(chunks) > maxChunks {
+		chunks = chunks[:maxChunks]
+	}
+	return chunks
+}
@@ -0,0 +1,21 @@
+package retrieval
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitCutsLongLinesAtRuneBoundaries(t *testing.T) {
+	line := strings.Repeat("é", 50) // 2 bytes per rune
+	for maxChars := 1; maxChars <= 12; maxChars++ {
+		for _, chunk := range Split("x"+line+"\nnext line\n", maxChars, 100) {
+			if !utf8.ValidString(chunk.Text) {
+				t.Fatalf("maxChars %d: chunk %q is not valid UTF-8", maxChars, chunk.Text)
+			}
+			if len(chunk.Text) > maxChars {
+				t.Fatalf("maxChars %d: chunk is %d bytes", maxChars, len(chunk.Text))
+			}
+		}
+	}
+}
@@ -0,0 +1,307 @@
+// Package retrieval ranks project files by embedding similarity to a task text.
+// Files are split into line chunks, each chunk is embedded once and kept in an
+// on-disk index; a file's score is the similarity of its best chunk.
+package retrieval
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"shotgun_code/internal/walker"
+)
+
+const (
+	// indexVersion changes whenever the chunking or the stored layout does, so
+	// indexes written by older builds are rebuilt instead of misread.
+	indexVersion   = 1
+	chunkChars     = 1_500
+	maxFileChunks  = 16
+	embedBatchSize = 128 // Chunks embedded between progress commits
+)
+
+// Embedder computes embeddings; provider.Embedder satisfies it.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// File is a project file to keep in the index.
+type File struct {
+	RelPath string // Slash-separated, relative to the project root
+	AbsPath string
+	ModTime time.Time
+	Size    int64
+}
+
+// Hit is a file ranked by Search.
+type Hit struct {
+	RelPath   string  `json:"relPath"`
+	Score     float64 `json:"score"`     // Cosine similarity of the best chunk
+	StartLine int     `json:"startLine"` // Lines of the best chunk
+	EndLine   int     `json:"endLine"`
+}
+
+// UpdateStats reports what Update did.
+type UpdateStats struct {
+	Embedded  int `json:"embedded"`  // Files (re-)embedded
+	Removed   int `json:"removed"`   // Files dropped from the index
+	Unchanged int `json:"unchanged"` // Files whose vectors were kept
+	Chunks    int `json:"chunks"`    // Chunks embedded
+}
+
+// Index holds the chunk embeddings of one project. It is safe for concurrent use.
+type Index struct {
+	mu    sync.RWMutex
+	path  string
+	data  indexData
+	stale map[string]bool // Files changed since they were embedded, from watcher events
+}
+
+type indexData struct {
+	Version int
+	Model   string
+	Files   map[string]*fileVectors
+}
+
+type fileVectors struct {
+	ModTime int64 // Unix nanoseconds
+	Size    int64
+	Chunks  []chunkVector
+}
+
+type chunkVector struct {
+	StartLine int
+	EndLine   int
+	Vector    []float32 // Unit length
+}
+
+// Open loads the index stored at path. A missing file, or one written for another
+// model or layout, yields an empty index that Save will overwrite.
+func Open(path, model string) (*Index, error) {
+	ix := &Index{
+		path:  path,
+		data:  indexData{Version: indexVersion, Model: model, Files: make(map[string]*fileVectors)},
+		stale: make(map[string]bool),
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ix, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open retrieval index: %w", err)
+	}
+	defer file.Close()
+
+	var stored indexData
+	if err := gob.NewDecoder(file).Decode(&stored); err != nil {
+		return ix, nil // Unreadable: rebuild
+	}
+	if stored.Version == indexVersion && stored.Model == model && stored.Files != nil {
+		ix.data = stored
+	}
+	return ix, nil
+}
+
+// Save writes the index to disk, replacing the previous file atomically.
+func (ix *Index) Save() error {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	if err := os.MkdirAll(filepath.Dir(ix.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create retrieval index directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(ix.path), filepath.Base(ix.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write retrieval index: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := gob.NewEncoder(tmp).Encode(ix.data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to encode retrieval index: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write retrieval index: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), ix.path); err != nil {
+		return fmt.Errorf("failed to replace retrieval index: %w", err)
+	}
+	return nil
+}
+
+// Model returns the embedding model the index was built with.
+func (ix *Index) Model() string {
+	return ix.data.Model
+}
+
+// Len returns the number of indexed files and chunks.
+func (ix *Index) Len() (files, chunks int) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	for _, f := range ix.data.Files {
+		chunks += len(f.Chunks)
+	}
+	return len(ix.data.Files), chunks
+}
+
+// MarkStale makes the next Update re-embed relPath even if its size and
+// modification time look unchanged.
+func (ix *Index) MarkStale(relPath string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.stale[relPath] = true
+}
+
+// Update brings the index in line with files: files no longer listed are dropped,
+// and new, changed or stale ones are embedded. Unreadable files are skipped and
+// binary files are kept without vectors. Progress is kept when ctx is cancelled or
+// the embedder fails, so the next Update continues where this one stopped.
+func (ix *Index) Update(ctx context.Context, files []File, emb Embedder) (UpdateStats, error) {
+	var stats UpdateStats
+	listed := make(map[string]bool, len(files))
+	var pending []File
+
+	ix.mu.Lock()
+	for _, f := range files {
+		listed[f.RelPath] = true
+		if existing, ok := ix.data.Files[f.RelPath]; ok && !ix.stale[f.RelPath] &&
+			existing.ModTime == f.ModTime.UnixNano() && existing.Size == f.Size {
+			stats.Unchanged++
+			continue
+		}
+		pending = append(pending, f)
+	}
+	for rel := range ix.data.Files {
+		if !listed[rel] {
+			delete(ix.data.Files, rel)
+			stats.Removed++
+		}
+	}
+	ix.mu.Unlock()
+
+	var batch []File
+	var batchChunks [][]Chunk
+	var texts []string
+	commit := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		var vectors [][]float32
+		if len(texts) > 0 {
+			var err error
+			if vectors, err = emb.Embed(ctx, texts); err != nil {
+				return err
+			}
+		}
+		if len(vectors) != len(texts) {
+			return fmt.Errorf("embedder returned %d vectors for %d chunks", len(vectors), len(texts))
+		}
+		ix.mu.Lock()
+		next := 0
+		for i, f := range batch {
+			entry := &fileVectors{ModTime: f.ModTime.UnixNano(), Size: f.Size}
+			for _, chunk := range batchChunks[i] {
+				entry.Chunks = append(entry.Chunks, chunkVector{StartLine: chunk.StartLine, EndLine: chunk.EndLine, Vector: normalize(vectors[next])})
+				next++
+			}
+			ix.data.Files[f.RelPath] = entry
+			delete(ix.stale, f.RelPath)
+		}
+		ix.mu.Unlock()
+		stats.Embedded += len(batch)
+		stats.Chunks += len(texts)
+		batch, batchChunks, texts = nil, nil, nil
+		return nil
+	}
+
+	for _, f := range pending {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+		content, err := os.ReadFile(f.AbsPath)
+		if err != nil {
+			continue
+		}
+		// Binary files are recorded without chunks so they are not read again until they change.
+		var chunks []Chunk
+		if !walker.IsBinary(content) {
+			chunks = Split(strings.ToValidUTF8(string(content), "\uFFFD"), chunkChars, maxFileChunks)
+		}
+		batch = append(batch, f)
+		batchChunks = append(batchChunks, chunks)
+		for _, chunk := range chunks {
+			// The path takes part in the embedding so names like "auth/session.go" count.
+			texts = append(texts, "File: "+f.RelPath+"\n"+chunk.Text)
+		}
+		if len(texts) >= embedBatchSize {
+			if err := commit(); err != nil {
+				return stats, err
+			}
+		}
+	}
+	return stats, commit()
+}
+
+// Search returns the k files whose best chunk is most similar to query, best first.
+func (ix *Index) Search(query []float32, k int) []Hit {
+	query = normalize(query)
+	ix.mu.RLock()
+	hits := make([]Hit, 0, len(ix.data.Files))
+	for rel, f := range ix.data.Files {
+		best := Hit{RelPath: rel, Score: math.Inf(-1)}
+		for _, chunk := range f.Chunks {
+			if score := dot(query, chunk.Vector); score > best.Score {
+				best.Score, best.StartLine, best.EndLine = score, chunk.StartLine, chunk.EndLine
+			}
+		}
+		if len(f.Chunks) > 0 {
+			hits = append(hits, best)
+		}
+	}
+	ix.mu.RUnlock()
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].RelPath < hits[j].RelPath
+	})
+	if k > 0 && len(hits) > k {
+		hits = hits[:k]
+	}
+	return hits
+}
+
+func normalize(v []float32) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return v
+	}
+	norm := float32(1 / math.Sqrt(sum))
+	out := make([]float32, len(v))
+	for i, x := range v {
+		out[i] = x * norm
+	}
+	return out
+}
+
+// dot returns the dot product; vectors of different lengths score 0.
+func dot(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
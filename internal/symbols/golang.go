@@ -0,0 +1,70 @@
+package symbols
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+)
+
+func extractGo(relPath string, content []byte) []Symbol {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, relPath, content, parser.SkipObjectResolution)
+	if err != nil && file == nil {
+		return nil
+	}
+	var symbols []Symbol
+	line := func(pos token.Pos) int { return fset.Position(pos).Line }
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if !d.Name.IsExported() {
+				continue
+			}
+			if d.Recv == nil || len(d.Recv.List) == 0 {
+				symbols = append(symbols, Symbol{Name: d.Name.Name, Kind: "func", Line: line(d.Pos())})
+				continue
+			}
+			recv := receiverType(d.Recv.List[0].Type)
+			if recv == "" || !ast.IsExported(recv) {
+				continue
+			}
+			symbols = append(symbols, Symbol{Name: recv + "." + d.Name.Name, Kind: "method", Line: line(d.Pos())})
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if !s.Name.IsExported() {
+						continue
+					}
+					kind := "type"
+					if _, ok := s.Type.(*ast.InterfaceType); ok {
+						kind = "interface"
+					}
+					symbols = append(symbols, Symbol{Name: s.Name.Name, Kind: kind, Line: line(s.Pos())})
+				case *ast.ValueSpec:
+					for _, name := range s.Names {
+						if name.IsExported() {
+							symbols = append(symbols, Symbol{Name: name.Name, Kind: d.Tok.String(), Line: line(name.Pos())})
+						}
+					}
+				}
+			}
+		}
+	}
+	return symbols
+}
+
+// receiverType returns the type name of a method receiver such as *Stack or List[T].
+func receiverType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverType(t.X)
+	case *ast.IndexExpr:
+		return receiverType(t.X)
+	case *ast.IndexListExpr:
+		return receiverType(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
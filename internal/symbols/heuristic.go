@@ -0,0 +1,124 @@
+package symbols
+
+import (
+	"regexp"
+	"strings"
+)
+
+// linePattern recognises a declaration on a single line. The first submatch is the name.
+type linePattern struct {
+	kind string
+	re   *regexp.Regexp
+}
+
+var scriptPatterns = []linePattern{
+	{"class", regexp.MustCompile(`^export\s+(?:default\s+)?(?:abstract\s+)?class\s+([\w$]+)`)},
+	{"func", regexp.MustCompile(`^export\s+(?:default\s+)?(?:async\s+)?function\s*\*?\s*([\w$]+)`)},
+	{"interface", regexp.MustCompile(`^export\s+(?:declare\s+)?interface\s+([\w$]+)`)},
+	{"type", regexp.MustCompile(`^export\s+(?:declare\s+)?type\s+([\w$]+)`)},
+	{"enum", regexp.MustCompile(`^export\s+(?:declare\s+)?(?:const\s+)?enum\s+([\w$]+)`)},
+	{"const", regexp.MustCompile(`^export\s+(?:declare\s+)?(?:const|let|var)\s+([\w$]+)`)},
+	{"func", regexp.MustCompile(`^(?:module\.)?exports\.([\w$]+)\s*=`)},
+}
+
+var rustPatterns = []linePattern{
+	{"func", regexp.MustCompile(`^\s*pub(?:\([\w:]+\))?\s+(?:const\s+)?(?:async\s+)?(?:unsafe\s+)?fn\s+(\w+)`)},
+	{"type", regexp.MustCompile(`^\s*pub(?:\([\w:]+\))?\s+struct\s+(\w+)`)},
+	{"enum", regexp.MustCompile(`^\s*pub(?:\([\w:]+\))?\s+enum\s+(\w+)`)},
+	{"interface", regexp.MustCompile(`^\s*pub(?:\([\w:]+\))?\s+trait\s+(\w+)`)},
+	{"type", regexp.MustCompile(`^\s*pub(?:\([\w:]+\))?\s+type\s+(\w+)`)},
+	{"const", regexp.MustCompile(`^\s*pub(?:\([\w:]+\))?\s+(?:const|static)\s+(\w+)`)},
+}
+
+// classPatterns covers the public types of Java-family and C# sources.
+var classPatterns = []linePattern{
+	{"class", regexp.MustCompile(`^\s*(?:public\s+)?(?:abstract\s+|final\s+|sealed\s+|static\s+|data\s+|open\s+)*class\s+(\w+)`)},
+	{"interface", regexp.MustCompile(`^\s*(?:public\s+)?(?:sealed\s+)?interface\s+(\w+)`)},
+	{"enum", regexp.MustCompile(`^\s*(?:public\s+)?enum\s+(?:class\s+)?(\w+)`)},
+	{"type", regexp.MustCompile(`^\s*(?:public\s+)?(?:record|object|struct)\s+(\w+)`)},
+}
+
+var (
+	pythonDefPattern   = regexp.MustCompile(`^(\s*)(?:async\s+)?def\s+(\w+)`)
+	pythonClassPattern = regexp.MustCompile(`^(\s*)class\s+(\w+)`)
+	vueScriptPattern   = regexp.MustCompile(`(?is)<script\b[^>]*>`)
+)
+
+func extractPattern(content string, patterns []linePattern) []Symbol {
+	var symbols []Symbol
+	for i, line := range strings.Split(content, "\n") {
+		for _, p := range patterns {
+			if m := p.re.FindStringSubmatch(line); m != nil {
+				symbols = append(symbols, Symbol{Name: m[1], Kind: p.kind, Line: i + 1})
+				break
+			}
+		}
+	}
+	return symbols
+}
+
+// extractScript finds exported declarations in JavaScript or TypeScript. lineOffset
+// is added to line numbers, for scripts embedded in a larger file.
+func extractScript(content string, lineOffset int) []Symbol {
+	symbols := extractPattern(content, scriptPatterns)
+	for i := range symbols {
+		symbols[i].Line += lineOffset
+	}
+	return symbols
+}
+
+// extractVue reads the exports of every <script> block of a single-file component.
+func extractVue(content string) []Symbol {
+	var symbols []Symbol
+	for _, loc := range vueScriptPattern.FindAllStringIndex(content, -1) {
+		body := content[loc[1]:]
+		if end := strings.Index(strings.ToLower(body), "</script>"); end >= 0 {
+			body = body[:end]
+		}
+		offset := strings.Count(content[:loc[1]], "\n")
+		symbols = append(symbols, extractScript(body, offset)...)
+	}
+	return symbols
+}
+
+// extractPython lists public top-level classes and functions and the public
+// methods of top-level classes, going by indentation: methods are the defs at the
+// indentation of the first statement in the class body, whatever its width.
+func extractPython(content string) []Symbol {
+	var symbols []Symbol
+	class, bodyIndent := "", ""
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		indent := line[:len(line)-len(trimmed)]
+		if class != "" && bodyIndent == "" && indent != "" && strings.TrimSpace(trimmed) != "" && !strings.HasPrefix(trimmed, "#") {
+			bodyIndent = indent
+		}
+		if m := pythonClassPattern.FindStringSubmatch(line); m != nil {
+			if m[1] == "" {
+				class, bodyIndent = m[2], ""
+				if !strings.HasPrefix(class, "_") {
+					symbols = append(symbols, Symbol{Name: class, Kind: "class", Line: i + 1})
+				}
+			}
+			continue
+		}
+		if m := pythonDefPattern.FindStringSubmatch(line); m != nil {
+			name := m[2]
+			switch {
+			case m[1] == "":
+				class = ""
+				if !strings.HasPrefix(name, "_") {
+					symbols = append(symbols, Symbol{Name: name, Kind: "func", Line: i + 1})
+				}
+			case class != "" && !strings.HasPrefix(class, "_") && !strings.HasPrefix(name, "_") && m[1] == bodyIndent:
+				symbols = append(symbols, Symbol{Name: class + "." + name, Kind: "method", Line: i + 1})
+			}
+			continue
+		}
+		// Any other top-level statement ends the current class.
+		if line != "" && line[0] != ' ' && line[0] != '\t' && !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "@") {
+			class = ""
+		}
+	}
+	return symbols
+}
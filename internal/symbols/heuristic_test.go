@@ -0,0 +1,30 @@
+package symbols
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExtractPythonMethodsAtAnyIndent(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"four spaces", "class Store:\n    \"\"\"Doc.\"\"\"\n    def get(self):\n        def inner():\n            pass\n    async def put(self):\n        pass\n    def _private(self):\n        pass\n"},
+		{"two spaces", "class Store:\n  def get(self):\n    def inner():\n      pass\n  async def put(self):\n    pass\n"},
+		{"tabs", "class Store:\n\t# comment\n\n\tdef get(self):\n\t\tdef inner():\n\t\t\tpass\n\tasync def put(self):\n\t\tpass\n"},
+		{"eight spaces", "class Store:\n        def get(self):\n                def inner():\n                        pass\n        async def put(self):\n                pass\n"},
+	}
+	want := []string{"Store", "Store.get", "Store.put", "helper"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, s := range extractPython(tt.source + "\ndef helper():\n    pass\n") {
+				got = append(got, s.Name)
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("symbols = %v, want %v", got, want)
+			}
+		})
+	}
+}
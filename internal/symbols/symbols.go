@@ -0,0 +1,50 @@
+// Package symbols extracts a compact, ctags-style map of the exported declarations
+// in a source file: types, functions, methods and top-level values with their lines.
+package symbols
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Symbol is one exported declaration.
+type Symbol struct {
+	Name string `json:"name"` // Methods are qualified with their type, e.g. "Stack.Explain"
+	Kind string `json:"kind"` // "type", "func", "method", "class", "interface", "const", "var", ...
+	Line int    `json:"line"` // 1-based
+}
+
+// Extract returns the exported symbols of the file at relPath in declaration order.
+// Languages without an extractor yield nil.
+func Extract(relPath string, content []byte) []Symbol {
+	switch strings.ToLower(path.Ext(relPath)) {
+	case ".go":
+		return extractGo(relPath, content)
+	case ".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx", ".mts", ".cts":
+		return extractScript(string(content), 0)
+	case ".vue", ".svelte":
+		return extractVue(string(content))
+	case ".py", ".pyi":
+		return extractPython(string(content))
+	case ".rs":
+		return extractPattern(string(content), rustPatterns)
+	case ".java", ".kt", ".kts", ".cs", ".scala":
+		return extractPattern(string(content), classPatterns)
+	}
+	return nil
+}
+
+// Format renders symbols as an indented block under the file path:
+//
+//	internal/ignore/stack.go
+//	  19 type Stack
+//	  32 func NewStack
+func Format(relPath string, symbols []Symbol) string {
+	var b strings.Builder
+	b.WriteString(relPath + "\n")
+	for _, s := range symbols {
+		fmt.Fprintf(&b, "  %d %s %s\n", s.Line, s.Kind, s.Name)
+	}
+	return b.String()
+}
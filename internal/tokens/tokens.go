@@ -0,0 +1,18 @@
+// Package tokens estimates LLM token counts without a model-specific tokenizer.
+package tokens
+
+// bytesPerToken matches the estimate the frontend shows for generated context.
+const bytesPerToken = 3
+
+// Estimate returns the approximate number of tokens in size bytes of text.
+func Estimate(size int64) int64 {
+	if size <= 0 {
+		return 0
+	}
+	return (size + bytesPerToken - 1) / bytesPerToken
+}
+
+// EstimateText returns the approximate number of tokens in text.
+func EstimateText(text string) int64 {
+	return Estimate(int64(len(text)))
+}
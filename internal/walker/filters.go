@@ -0,0 +1,205 @@
+package walker
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Action is a filter's decision for a single entry.
+// When several filters match, the strongest action wins (Exclude > Prune > Mark > Keep).
+type Action int
+
+const (
+	// Keep means the filter has no opinion about the entry.
+	Keep Action = iota
+	// Mark keeps the entry and records the filter name in Entry.Marks.
+	Mark
+	// Prune marks the entry and, for directories, stops the walk from descending into it.
+	Prune
+	// Exclude drops the entry and everything below it.
+	Exclude
+)
+
+// Filter decides what happens to each entry found by the walker.
+type Filter interface {
+	// Name identifies the filter in Entry.Marks.
+	Name() string
+	// Check returns the action for e. Only Name, Path, RelPath, IsDir, IsSymlink,
+	// Size, Mode and Depth are populated when Check is called.
+	Check(e *Entry) Action
+}
+
+// Names of the built-in filters, as recorded in Entry.Marks.
+const (
+	FilterGitignore    = "gitignore"
+	FilterCustomIgnore = "custom-ignore"
+	FilterExcluded     = "excluded"
+	FilterSize         = "size"
+	FilterBinary       = "binary"
+)
+
+// Matcher is implemented by compiled gitignore-style rule sets.
+// Directory paths are passed with a trailing separator.
+type Matcher interface {
+	MatchesPath(path string) bool
+}
+
+type ignoreFilter struct {
+	name    string
+	matcher Matcher
+	onMatch Action
+}
+
+// Ignore returns a filter applying onMatch to entries matched by m.
+func Ignore(name string, m Matcher, onMatch Action) Filter {
+	return &ignoreFilter{name: name, matcher: m, onMatch: onMatch}
+}
+
+// Gitignore returns a filter for the project's .gitignore rules.
+func Gitignore(m Matcher, onMatch Action) Filter {
+	return Ignore(FilterGitignore, m, onMatch)
+}
+
+// CustomIgnore returns a filter for the user's custom ignore rules.
+func CustomIgnore(m Matcher, onMatch Action) Filter {
+	return Ignore(FilterCustomIgnore, m, onMatch)
+}
+
+func (f *ignoreFilter) Name() string { return f.name }
+
+func (f *ignoreFilter) Check(e *Entry) Action {
+	path := e.RelPath
+	if e.IsDir && !strings.HasSuffix(path, string(os.PathSeparator)) {
+		path += string(os.PathSeparator)
+	}
+	if f.matcher.MatchesPath(path) {
+		return f.onMatch
+	}
+	return Keep
+}
+
+type excludedFilter struct {
+	paths map[string]bool
+}
+
+// Excluded returns a filter dropping the given relative paths and everything below them.
+// Paths may use either separator and an optional "./" prefix.
+func Excluded(paths []string) Filter {
+	set := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		if p = normalize(p); p != "" {
+			set[p] = true
+		}
+	}
+	return &excludedFilter{paths: set}
+}
+
+func (f *excludedFilter) Name() string { return FilterExcluded }
+
+func (f *excludedFilter) Check(e *Entry) Action {
+	if f.paths[e.SlashPath()] {
+		return Exclude
+	}
+	return Keep
+}
+
+type sizeFilter struct {
+	limit   int64
+	onMatch Action
+}
+
+// MaxSize returns a filter applying onMatch to files larger than limit bytes.
+func MaxSize(limit int64, onMatch Action) Filter {
+	return &sizeFilter{limit: limit, onMatch: onMatch}
+}
+
+func (f *sizeFilter) Name() string { return FilterSize }
+
+func (f *sizeFilter) Check(e *Entry) Action {
+	if !e.IsDir && f.limit > 0 && e.Size > f.limit {
+		return f.onMatch
+	}
+	return Keep
+}
+
+type binaryFilter struct {
+	onMatch Action
+}
+
+// Binary returns a filter applying onMatch to files that look binary.
+func Binary(onMatch Action) Filter {
+	return &binaryFilter{onMatch: onMatch}
+}
+
+func (f *binaryFilter) Name() string { return FilterBinary }
+
+func (f *binaryFilter) Check(e *Entry) Action {
+	if e.IsDir || !e.Mode.IsRegular() {
+		return Keep
+	}
+	if IsBinaryFile(e.Path) {
+		return f.onMatch
+	}
+	return Keep
+}
+
+// binarySniffLen matches the heuristic git uses: a NUL byte in the first 8000 bytes.
+const binarySniffLen = 8000
+
+// IsBinaryFile reports whether the file at path looks binary.
+// Unreadable files are reported as not binary so callers surface the read error.
+func IsBinaryFile(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	buf := make([]byte, binarySniffLen)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false
+	}
+	return IsBinary(buf[:n])
+}
+
+// IsBinary reports whether data looks like binary content.
+func IsBinary(data []byte) bool {
+	if len(data) > binarySniffLen {
+		data = data[:binarySniffLen]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
+
+type matchFilesFilter struct {
+	name  string
+	match func(relPath string) bool
+}
+
+// MatchFiles returns a filter excluding every file for which match returns false.
+// match receives slash-separated paths. Directories are kept; combine with
+// Options.PruneEmptyDirs to hide directories left without matching files.
+func MatchFiles(name string, match func(relPath string) bool) Filter {
+	return &matchFilesFilter{name: name, match: match}
+}
+
+func (f *matchFilesFilter) Name() string { return f.name }
+
+func (f *matchFilesFilter) Check(e *Entry) Action {
+	if e.IsDir || f.match(e.SlashPath()) {
+		return Keep
+	}
+	return Exclude
+}
+
+func normalize(rel string) string {
+	rel = strings.TrimSpace(rel)
+	if rel == "" || rel == "." {
+		return ""
+	}
+	rel = filepath.ToSlash(rel)
+	rel = strings.TrimPrefix(rel, "./")
+	return strings.TrimPrefix(rel, "/")
+}
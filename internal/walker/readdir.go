@@ -0,0 +1,156 @@
+package walker
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ReadDir lists the direct children of relDir, a directory below root, with the
+// same symlink policy, filters and ordering as Walk. Directories are not descended,
+// except under PruneEmptyDirs, where a directory is returned only if something
+// inside it survives the filters. Use HasChildren to decide whether a returned
+// directory can be expanded.
+func ReadDir(ctx context.Context, root, relDir string, opts Options) ([]*Entry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	w, dir, ancestors, err := newDirWalk(root, relDir, opts)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := w.readDir(dir, ancestors)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.PruneEmptyDirs {
+		return entries, nil
+	}
+	kept := entries[:0]
+	for _, entry := range entries {
+		if entry.IsDir && !w.nonEmpty(ctx, entry, append(ancestors, entry.info)) {
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	return kept, ctx.Err()
+}
+
+// HasChildren reports whether the directory entry, as returned by ReadDir for the
+// same root and options, has any children to list. Pruned directories have none.
+func HasChildren(ctx context.Context, root string, dir *Entry, opts Options) bool {
+	if !dir.IsDir || dir.pruned {
+		return false
+	}
+	w, _, ancestors, err := newDirWalk(root, dir.RelPath, opts)
+	if err != nil {
+		return false
+	}
+	return w.nonEmpty(ctx, dir, ancestors)
+}
+
+// newDirWalk prepares a walk rooted at root and the Entry for relDir inside it.
+// ancestors holds the stat of every directory from root down to relDir, for loop detection.
+// A symlink along relDir is entered only where Walk would follow it: under
+// SymlinkFollowWithinRoot, to a directory inside the root. Other paths are rejected.
+func newDirWalk(root, relDir string, opts Options) (*walk, *Entry, []fs.FileInfo, error) {
+	relDir = filepath.Clean(filepath.FromSlash(relDir))
+	if relDir == ".." || strings.HasPrefix(relDir, ".."+string(os.PathSeparator)) || filepath.IsAbs(relDir) {
+		return nil, nil, nil, &fs.PathError{Op: "readdir", Path: relDir, Err: fs.ErrInvalid}
+	}
+
+	w := &walk{root: root, opts: opts}
+	if opts.Symlinks == SymlinkFollowWithinRoot {
+		if real, err := filepath.EvalSymlinks(root); err == nil {
+			w.realRoot = real
+		}
+	}
+
+	var ancestors []fs.FileInfo
+	current := root
+	info, err := os.Stat(current)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	ancestors = append(ancestors, info)
+	depth := 0
+	if relDir != "." {
+		for _, part := range strings.Split(relDir, string(os.PathSeparator)) {
+			current = filepath.Join(current, part)
+			if info, err = os.Lstat(current); err != nil {
+				return nil, nil, nil, err
+			}
+			if info.Mode()&fs.ModeSymlink != 0 {
+				if info, err = w.enterSymlink(current, ancestors); err != nil {
+					return nil, nil, nil, err
+				}
+			}
+			ancestors = append(ancestors, info)
+			depth++
+		}
+	}
+	if !info.IsDir() {
+		return nil, nil, nil, &fs.PathError{Op: "readdir", Path: current, Err: fs.ErrInvalid}
+	}
+
+	dir := &Entry{
+		Name:    filepath.Base(current),
+		Path:    current,
+		RelPath: relDir,
+		IsDir:   true,
+		Mode:    info.Mode(),
+		ModTime: info.ModTime(),
+		Depth:   depth,
+		info:    info,
+	}
+	return w, dir, ancestors, nil
+}
+
+// enterSymlink resolves a symlinked directory on the way down to a ReadDir target,
+// refusing links that leave the root or loop back into one of the ancestors.
+func (w *walk) enterSymlink(path string, ancestors []fs.FileInfo) (fs.FileInfo, error) {
+	denied := &fs.PathError{Op: "readdir", Path: path, Err: fs.ErrPermission}
+	if w.opts.Symlinks != SymlinkFollowWithinRoot || w.realRoot == "" {
+		return nil, denied
+	}
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return nil, err
+	}
+	if !within(w.realRoot, target) {
+		return nil, denied
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return nil, err
+	}
+	for _, ancestor := range ancestors {
+		if os.SameFile(ancestor, info) {
+			return nil, denied
+		}
+	}
+	return info, nil
+}
+
+// nonEmpty reports whether dir has a child that would be listed. Under PruneEmptyDirs
+// only directories that eventually contain a file count, so it may search deeper.
+func (w *walk) nonEmpty(ctx context.Context, dir *Entry, ancestors []fs.FileInfo) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	entries, err := w.readDir(dir, ancestors)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if !entry.IsDir || !w.opts.PruneEmptyDirs {
+			return true
+		}
+		if !entry.pruned && w.nonEmpty(ctx, entry, append(ancestors, entry.info)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package walker
+
+// Render calls fn for every entry below root in display order, passing the
+// ASCII tree line for it (e.g. "│   ├── main.go"). The root line itself is
+// left to the caller. Returning an error from fn stops rendering.
+func Render(root *Entry, fn func(e *Entry, line string) error) error {
+	return renderChildren(root, "", fn)
+}
+
+func renderChildren(dir *Entry, prefix string, fn func(e *Entry, line string) error) error {
+	for i, entry := range dir.Children {
+		branch := "├── "
+		nextPrefix := prefix + "│   "
+		if i == len(dir.Children)-1 {
+			branch = "└── "
+			nextPrefix = prefix + "    "
+		}
+		if err := fn(entry, prefix+branch+entry.Name); err != nil {
+			return err
+		}
+		if entry.IsDir {
+			if err := renderChildren(entry, nextPrefix, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
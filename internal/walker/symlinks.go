@@ -0,0 +1,86 @@
+package walker
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SymlinkPolicy controls how symbolic links are treated.
+type SymlinkPolicy int
+
+const (
+	// SymlinkListOnly lists links as leaf entries; their targets are never read or entered.
+	SymlinkListOnly SymlinkPolicy = iota
+	// SymlinkSkip leaves links out of the walk entirely.
+	SymlinkSkip
+	// SymlinkFollowWithinRoot resolves links whose target lies inside the walk root and
+	// treats them like the target. Links leaving the root, dangling links and links
+	// that would loop back into a directory being walked are listed only.
+	SymlinkFollowWithinRoot
+)
+
+// specialModes are file types the walker never lists: reading a FIFO can block
+// forever and sockets or devices have no meaningful content.
+const specialModes = fs.ModeNamedPipe | fs.ModeSocket | fs.ModeDevice | fs.ModeCharDevice | fs.ModeIrregular
+
+func isSpecial(mode fs.FileMode) bool {
+	return mode&specialModes != 0
+}
+
+// resolveSymlink applies the symlink policy to entry. It returns false when the
+// entry must be left out of the walk.
+func (w *walk) resolveSymlink(entry *Entry, ancestors []fs.FileInfo) bool {
+	switch w.opts.Symlinks {
+	case SymlinkSkip:
+		return false
+	case SymlinkFollowWithinRoot:
+	default:
+		return true
+	}
+
+	if w.realRoot == "" {
+		return true
+	}
+	target, err := filepath.EvalSymlinks(entry.Path)
+	if err != nil {
+		return true // Dangling or unreadable link: list only
+	}
+	entry.LinkTarget = target
+	if !within(w.realRoot, target) {
+		return true
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return true
+	}
+	if isSpecial(info.Mode()) {
+		return false
+	}
+	if info.IsDir() {
+		for _, ancestor := range ancestors {
+			if ancestor != nil && os.SameFile(ancestor, info) {
+				return true // Loop back into a directory being walked: list only
+			}
+		}
+	}
+
+	entry.Followed = true
+	entry.IsDir = info.IsDir()
+	entry.Mode = info.Mode()
+	entry.ModTime = info.ModTime()
+	entry.info = info
+	if !entry.IsDir {
+		entry.Size = info.Size()
+	}
+	return true
+}
+
+// within reports whether path equals root or lies below it.
+func within(root, path string) bool {
+	if path == root {
+		return true
+	}
+	return strings.HasPrefix(path, strings.TrimSuffix(root, string(os.PathSeparator))+string(os.PathSeparator))
+}
@@ -0,0 +1,176 @@
+//go:build unix
+
+package walker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"syscall"
+	"testing"
+)
+
+// symlinkFixture builds:
+//
+//	real/file.txt
+//	real/sub/inner.txt
+//	link-file -> real/file.txt
+//	link-dir  -> real
+//	real/sub/loop -> .. (back into real)
+//	link-root -> /
+//	dangling  -> missing
+//	fifo        (named pipe)
+func symlinkFixture(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "real", "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{"real/file.txt", "real/sub/inner.txt"} {
+		if err := os.WriteFile(filepath.Join(root, file), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	links := map[string]string{
+		"link-file":     filepath.Join("real", "file.txt"),
+		"link-dir":      "real",
+		"real/sub/loop": "..",
+		"link-root":     string(os.PathSeparator),
+		"dangling":      "missing",
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(root, filepath.FromSlash(name))); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+	}
+	if err := syscall.Mkfifo(filepath.Join(root, "fifo"), 0o644); err != nil {
+		t.Skipf("FIFOs not supported: %v", err)
+	}
+	return root
+}
+
+func walkPaths(t *testing.T, root string, policy SymlinkPolicy) map[string]*Entry {
+	t.Helper()
+	tree, err := Walk(context.Background(), root, Options{Symlinks: policy})
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := make(map[string]*Entry)
+	tree.Visit(func(e *Entry) error {
+		if e.RelPath != "." {
+			paths[e.SlashPath()] = e
+		}
+		return nil
+	})
+	return paths
+}
+
+func TestWalkSymlinkPolicies(t *testing.T) {
+	root := symlinkFixture(t)
+
+	tests := []struct {
+		name     string
+		policy   SymlinkPolicy
+		want     []string // Every path in the walk
+		followed []string // Links resolved and treated like their target
+	}{
+		{
+			name:   "skip",
+			policy: SymlinkSkip,
+			want:   []string{"real", "real/file.txt", "real/sub", "real/sub/inner.txt"},
+		},
+		{
+			name:   "list",
+			policy: SymlinkListOnly,
+			want:   []string{"dangling", "link-dir", "link-file", "link-root", "real", "real/file.txt", "real/sub", "real/sub/inner.txt", "real/sub/loop"},
+		},
+		{
+			name:   "follow within root",
+			policy: SymlinkFollowWithinRoot,
+			want: []string{
+				"dangling", "link-dir", "link-dir/file.txt", "link-dir/sub", "link-dir/sub/inner.txt", "link-dir/sub/loop",
+				"link-file", "link-root", "real", "real/file.txt", "real/sub", "real/sub/inner.txt", "real/sub/loop",
+			},
+			followed: []string{"link-dir", "link-file"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths := walkPaths(t, root, tt.policy)
+			var got []string
+			for p := range paths {
+				got = append(got, p)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("walk = %v, want %v", got, tt.want)
+			}
+			for p, e := range paths {
+				if e.Followed != slices.Contains(tt.followed, p) {
+					t.Errorf("%s: Followed = %v", p, e.Followed)
+				}
+			}
+		})
+	}
+}
+
+func TestWalkFollowListsLoopsAndOutsideLinks(t *testing.T) {
+	root := symlinkFixture(t)
+	paths := walkPaths(t, root, SymlinkFollowWithinRoot)
+
+	// A link back into a directory being walked is listed, not entered.
+	for _, loop := range []string{"real/sub/loop", "link-dir/sub/loop"} {
+		e := paths[loop]
+		if e == nil || !e.IsSymlink || e.Followed || len(e.Children) != 0 {
+			t.Errorf("%s should be listed as an unfollowed link, got %+v", loop, e)
+		}
+	}
+	// A link to / leaves the root: listed with its target, never entered or read.
+	e := paths["link-root"]
+	if e == nil || e.Followed || e.HasContent() || e.LinkTarget != string(os.PathSeparator) {
+		t.Errorf("link-root should be listed only, got %+v", e)
+	}
+	if e := paths["dangling"]; e == nil || e.Followed || e.HasContent() {
+		t.Errorf("dangling link should be listed only, got %+v", e)
+	}
+}
+
+func TestReadDirSkipsFIFO(t *testing.T) {
+	root := symlinkFixture(t)
+	entries, err := ReadDir(context.Background(), root, ".", Options{Symlinks: SymlinkSkip})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.Name == "fifo" {
+			t.Fatal("ReadDir listed a FIFO")
+		}
+	}
+	if len(entries) != 1 || entries[0].Name != "real" {
+		t.Errorf("ReadDir = %v entries, want only real", len(entries))
+	}
+}
+
+func TestReadDirThroughSymlinks(t *testing.T) {
+	root := symlinkFixture(t)
+	tests := []struct {
+		relDir string
+		policy SymlinkPolicy
+		ok     bool
+	}{
+		{"link-dir", SymlinkFollowWithinRoot, true},
+		{"link-dir/sub", SymlinkFollowWithinRoot, true},
+		{"link-dir", SymlinkListOnly, false},
+		{"link-dir/sub", SymlinkSkip, false},
+		{"link-root", SymlinkFollowWithinRoot, false},
+		{"link-root/etc", SymlinkFollowWithinRoot, false},
+		{"real/sub/loop", SymlinkFollowWithinRoot, false},
+	}
+	for _, tt := range tests {
+		_, err := ReadDir(context.Background(), root, filepath.FromSlash(tt.relDir), Options{Symlinks: tt.policy})
+		if (err == nil) != tt.ok {
+			t.Errorf("ReadDir(%s, policy %d) error = %v, want ok %v", tt.relDir, tt.policy, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+// Package walker lists a project directory once and applies the same filters,
+// ordering and limits for every consumer: the file tree shown in the UI, the
+// shotgun context generator and the auto-context tree.
+package walker
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Entry is a single file or directory found by Walk.
+type Entry struct {
+	Name      string
+	Path      string // Full path
+	RelPath   string // Path relative to the walk root, OS-specific separators; "." for the root
+	IsDir     bool
+	IsSymlink bool
+	// LinkTarget is the resolved target of a symlink; empty for dangling links and
+	// for links that were only listed.
+	LinkTarget string
+	// Followed is true for symlinks resolved inside the root; IsDir, Size and Mode
+	// then describe the target.
+	Followed bool
+	Size     int64
+	Mode     fs.FileMode
+	ModTime  time.Time
+	Depth    int      // 0 for the root, 1 for its children, ...
+	Marks    []string // Names of filters that matched this entry without excluding it
+	Children []*Entry
+
+	pruned bool        // A filter asked not to descend into this directory
+	info   fs.FileInfo // Stat of the directory (or link target), used for loop detection
+}
+
+// HasContent reports whether consumers may read the entry's content: a regular
+// file, or a symlink the walker followed to a regular file inside the root.
+func (e *Entry) HasContent() bool {
+	return !e.IsDir && (!e.IsSymlink || e.Followed)
+}
+
+// Pruned reports whether a filter asked not to descend into this directory.
+func (e *Entry) Pruned() bool {
+	return e.pruned
+}
+
+// Marked reports whether the filter with the given name matched this entry.
+func (e *Entry) Marked(filterName string) bool {
+	for _, m := range e.Marks {
+		if m == filterName {
+			return true
+		}
+	}
+	return false
+}
+
+// SlashPath returns RelPath with forward slashes, or "" for the root.
+func (e *Entry) SlashPath() string {
+	if e.RelPath == "." {
+		return ""
+	}
+	return filepath.ToSlash(e.RelPath)
+}
+
+// Visit calls fn for e and every descendant in display order. Returning
+// an error from fn stops the traversal.
+func (e *Entry) Visit(fn func(*Entry) error) error {
+	if err := fn(e); err != nil {
+		return err
+	}
+	for _, child := range e.Children {
+		if err := child.Visit(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Options configures a walk.
+type Options struct {
+	Filters []Filter
+	// MaxDepth limits recursion; directories at this depth are listed without children.
+	// Zero means unlimited.
+	MaxDepth int
+	Symlinks SymlinkPolicy
+	// PruneEmptyDirs drops directories that have no children left after filtering.
+	PruneEmptyDirs bool
+	// OnError is called for directories that cannot be read below the root.
+	// The directory is kept without children and the walk continues.
+	OnError func(path string, err error)
+}
+
+// Walk reads root recursively and returns it as an Entry tree. Children are sorted
+// directories first, then case-insensitively by name. Sockets, devices and FIFOs are
+// always skipped. An error is returned only when the root itself cannot be read or
+// ctx is cancelled.
+func Walk(ctx context.Context, root string, opts Options) (*Entry, error) {
+	rootInfo, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	rootEntry := &Entry{
+		Name:    filepath.Base(root),
+		Path:    root,
+		RelPath: ".",
+		IsDir:   true,
+		Mode:    rootInfo.Mode(),
+		ModTime: rootInfo.ModTime(),
+		info:    rootInfo,
+	}
+	w := &walk{root: root, opts: opts}
+	if opts.Symlinks == SymlinkFollowWithinRoot {
+		if real, err := filepath.EvalSymlinks(root); err == nil {
+			w.realRoot = real
+		}
+	}
+	children, err := w.walkDir(ctx, rootEntry, []fs.FileInfo{rootInfo})
+	if err != nil {
+		return nil, err
+	}
+	rootEntry.Children = children
+	return rootEntry, nil
+}
+
+// walk holds the state shared by one Walk call.
+type walk struct {
+	root     string
+	realRoot string // root with symlinks resolved; set only when following links
+	opts     Options
+}
+
+// walkDir reads dir and recurses. ancestors holds the stat of dir and every
+// directory above it, so followed links pointing back up can be detected.
+func (w *walk) walkDir(ctx context.Context, dir *Entry, ancestors []fs.FileInfo) ([]*Entry, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	entries, err := w.readDir(dir, ancestors)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]*Entry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir && entry.pruned && w.opts.PruneEmptyDirs {
+			continue
+		}
+		if entry.IsDir && !entry.pruned && (w.opts.MaxDepth == 0 || entry.Depth < w.opts.MaxDepth) {
+			children, err := w.walkDir(ctx, entry, append(ancestors, entry.info))
+			if err != nil {
+				if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+					return nil, err
+				}
+				if w.opts.OnError != nil {
+					w.opts.OnError(entry.Path, err)
+				}
+			} else {
+				entry.Children = children
+			}
+			if w.opts.PruneEmptyDirs && len(entry.Children) == 0 {
+				continue
+			}
+		}
+		nodes = append(nodes, entry)
+	}
+	return nodes, nil
+}
+
+// readDir lists the direct children of dir, applying the symlink policy, filters and sorting.
+func (w *walk) readDir(dir *Entry, ancestors []fs.FileInfo) ([]*Entry, error) {
+	dirEntries, err := os.ReadDir(dir.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*Entry, 0, len(dirEntries))
+	for _, de := range dirEntries {
+		path := filepath.Join(dir.Path, de.Name())
+		relPath, _ := filepath.Rel(w.root, path)
+		entry := &Entry{
+			Name:      de.Name(),
+			Path:      path,
+			RelPath:   relPath,
+			IsDir:     de.IsDir(),
+			IsSymlink: de.Type()&fs.ModeSymlink != 0,
+			Mode:      de.Type(),
+			Depth:     dir.Depth + 1,
+		}
+		if entry.IsSymlink {
+			if !w.resolveSymlink(entry, ancestors) {
+				continue
+			}
+		} else if info, infoErr := de.Info(); infoErr == nil {
+			if isSpecial(info.Mode()) {
+				continue
+			}
+			entry.Mode = info.Mode()
+			entry.ModTime = info.ModTime()
+			entry.info = info
+			if !entry.IsDir {
+				entry.Size = info.Size()
+			}
+		} else if isSpecial(de.Type()) {
+			// The entry vanished or cannot be stat'ed; the type from the directory
+			// listing still tells a FIFO or device apart from a file.
+			continue
+		}
+		if applyFilters(entry, w.opts.Filters) == Exclude {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	sortEntries(entries)
+	return entries, nil
+}
+
+func applyFilters(entry *Entry, filters []Filter) Action {
+	result := Keep
+	for _, f := range filters {
+		if f == nil {
+			continue
+		}
+		action := f.Check(entry)
+		switch action {
+		case Exclude:
+			return Exclude
+		case Mark, Prune:
+			entry.Marks = append(entry.Marks, f.Name())
+			if action == Prune {
+				entry.pruned = true
+			}
+		}
+		if action > result {
+			result = action
+		}
+	}
+	return result
+}
+
+// sortEntries orders directories first, then files, each case-insensitively by name.
+func sortEntries(entries []*Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].IsDir != entries[j].IsDir {
+			return entries[i].IsDir
+		}
+		return strings.ToLower(entries[i].Name) < strings.ToLower(entries[j].Name)
+	})
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf8"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// defaultMaxFileSizeBytes is the per-file ceiling used when none is configured.
+const defaultMaxFileSizeBytes = 1_000_000 // 1MB
+
+// Large-file policies: what the generator does with files above the per-file ceiling.
+// Either way the tree line of the file says so; truncate is the default so a large
+// file is never left out without a trace in its content block.
+const (
+	largeFilePolicyOmit     = "omit"     // List the file in the tree with its size, leave its content out
+	largeFilePolicyTruncate = "truncate" // Include the first MaxFileSizeBytes bytes of the file and a marker
+)
+
+// LargeFileSettings is the per-file size ceiling and what to do with files above it.
+type LargeFileSettings struct {
+	MaxFileSizeBytes int64  `json:"maxFileSizeBytes"`
+	Policy           string `json:"policy"` // "omit" or "truncate"
+}
+
+// largeFileSettings returns the effective settings, filling in defaults.
+func (a *App) largeFileSettings() LargeFileSettings {
+	s := LargeFileSettings{MaxFileSizeBytes: a.settings.MaxFileSizeBytes, Policy: a.settings.LargeFilePolicy}
+	if s.MaxFileSizeBytes <= 0 {
+		s.MaxFileSizeBytes = defaultMaxFileSizeBytes
+	}
+	if s.Policy != largeFilePolicyOmit {
+		s.Policy = largeFilePolicyTruncate
+	}
+	return s
+}
+
+// GetLargeFileSettings returns the per-file size ceiling and large-file policy.
+func (a *App) GetLargeFileSettings() LargeFileSettings {
+	return a.largeFileSettings()
+}
+
+// SetLargeFileSettings saves the per-file size ceiling and large-file policy and refreshes the tree.
+func (a *App) SetLargeFileSettings(settings LargeFileSettings) error {
+	if settings.MaxFileSizeBytes <= 0 {
+		return fmt.Errorf("maximum file size must be positive, got %d", settings.MaxFileSizeBytes)
+	}
+	if settings.MaxFileSizeBytes > maxOutputSizeBytes {
+		return fmt.Errorf("maximum file size %d exceeds the context limit of %d bytes", settings.MaxFileSizeBytes, maxOutputSizeBytes)
+	}
+	switch settings.Policy {
+	case largeFilePolicyOmit, largeFilePolicyTruncate:
+	default:
+		return fmt.Errorf("unknown large file policy %q (expected %q or %q)", settings.Policy, largeFilePolicyOmit, largeFilePolicyTruncate)
+	}
+	a.settings.MaxFileSizeBytes = settings.MaxFileSizeBytes
+	a.settings.LargeFilePolicy = settings.Policy
+	runtime.LogInfof(a.ctx, "App setting large files changed to: %d bytes, %s", settings.MaxFileSizeBytes, settings.Policy)
+	if err := a.saveSettings(); err != nil {
+		return err
+	}
+	a.notifySettingsChanged()
+	return nil
+}
+
+// readFileLimited reads at most limit bytes of the file at path. truncated is true
+// when the file holds more, which also catches files that grew after the walk. A
+// truncated read ends on a UTF-8 character boundary.
+func readFileLimited(path string, limit int64) (content []byte, truncated bool, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, false, err
+	}
+	defer file.Close()
+
+	content, err = io.ReadAll(io.LimitReader(file, limit+1))
+	if err != nil {
+		return nil, false, err
+	}
+	if int64(len(content)) > limit {
+		return trimPartialRune(content[:limit]), true, nil
+	}
+	return content, false, nil
+}
+
+// trimPartialRune drops a UTF-8 sequence cut off at the end of content. Content that
+// is not UTF-8 at that point is returned unchanged.
+func trimPartialRune(content []byte) []byte {
+	for i := 1; i <= utf8.UTFMax && i <= len(content); i++ {
+		start := len(content) - i
+		if !utf8.RuneStart(content[start]) {
+			continue
+		}
+		if !utf8.FullRune(content[start:]) {
+			return content[:start]
+		}
+		break
+	}
+	return content
+}
+
+// truncationMarker closes the content of a truncated file, so the cut is visible
+// to whoever reads the context.
+func truncationMarker(shown, size int64) string {
+	return fmt.Sprintf("\n... (truncated: showing the first %s of %s)", formatFileSize(shown), formatFileSize(size))
+}
+
+// formatFileSize renders a byte count for tree annotations, e.g. "48.2 MB".
+func formatFileSize(size int64) string {
+	const unit = 1000
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "kMGTPE"[exp])
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadFileLimitedStopsOnRuneBoundary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "text.txt")
+	if err := os.WriteFile(path, []byte("añ€😀z"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		limit         int64
+		want          string
+		wantTruncated bool
+	}{
+		{1, "a", true},
+		{2, "a", true}, // ñ is 2 bytes
+		{3, "añ", true},
+		{5, "añ", true}, // € is 3 bytes
+		{6, "añ€", true},
+		{9, "añ€", true}, // 😀 is 4 bytes
+		{10, "añ€😀", true},
+		{11, "añ€😀z", false},
+	}
+	for _, tt := range tests {
+		content, truncated, err := readFileLimited(path, tt.limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != tt.want || truncated != tt.wantTruncated {
+			t.Errorf("readFileLimited(%d) = %q, %v; want %q, %v", tt.limit, content, truncated, tt.want, tt.wantTruncated)
+		}
+	}
+
+	// Bytes that are not UTF-8 are cut at the limit as before.
+	binary := filepath.Join(t.TempDir(), "data.bin")
+	if err := os.WriteFile(binary, []byte{0xff, 0xfe, 0xfd, 0xfc}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if content, _, _ := readFileLimited(binary, 3); len(content) != 3 {
+		t.Errorf("non-UTF-8 content cut to %d bytes, want 3", len(content))
+	}
+}
@@ -38,6 +38,14 @@ func (a *App) SelectDirectory() (string, error) {
 */
 
 func main() {
+	// Developer commands run instead of the UI.
+	if len(os.Args) > 1 && os.Args[1] == "eval-autocontext" {
+		if err := runAutoContextEval(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	app := NewApp() // Creates an instance of App from app.go
 	// Load icons
 
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"shotgun_code/internal/ignore"
+	"shotgun_code/internal/langdetect"
+	"shotgun_code/internal/tokens"
+	"shotgun_code/internal/walker"
+)
+
+const includeFilterName = "include"
+
+// listingWalkOptions configures the walker for the file tree shown in the UI.
+// Ignore rules only mark entries so the frontend can toggle them; while custom rules
+// are in use, folders matched by them are listed but not expanded. Folders matched
+// by git ignore rules are expanded so files inside them can still be selected.
+func (a *App) listingWalkOptions(gitIgn *ignore.Stack) walker.Options {
+	var filters []walker.Filter
+	if gitIgn != nil {
+		filters = append(filters, walker.Gitignore(gitIgn, walker.Mark))
+	}
+	if a.currentCustomIgnorePatterns != nil {
+		onMatch := walker.Mark
+		if a.useCustomIgnore {
+			onMatch = walker.Prune
+		}
+		filters = append(filters, walker.CustomIgnore(a.currentCustomIgnorePatterns, onMatch))
+	}
+	filters = append(filters, walker.MaxSize(a.largeFileSettings().MaxFileSizeBytes, walker.Mark))
+	opts := walker.Options{
+		Filters:  filters,
+		Symlinks: a.walkerSymlinkPolicy(),
+		OnError: func(path string, err error) {
+			runtime.LogWarningf(a.ctx, "Error building subtree for %s: %v", path, err)
+		},
+	}
+	a.applyIncludeMode(&opts)
+	return opts
+}
+
+// selectionWalkOptions configures the walker for everything derived from the user's
+// selection: context generation, its progress count and the auto-context tree.
+// excludedPaths come from the frontend and already reflect the ignore toggles. Custom
+// rules are applied here as well while in use: the listing does not expand folders
+// they match, so in include mode such folders are pruned before the frontend can
+// exclude them. Files above the per-file ceiling are marked so the generator can omit or truncate them.
+func (a *App) selectionWalkOptions(excludedPaths []string) walker.Options {
+	opts := walker.Options{
+		Filters: []walker.Filter{
+			walker.Excluded(excludedPaths),
+			walker.MaxSize(a.largeFileSettings().MaxFileSizeBytes, walker.Mark),
+		},
+		Symlinks: a.walkerSymlinkPolicy(),
+		OnError: func(path string, err error) {
+			runtime.LogWarningf(a.ctx, "Skipping unreadable directory %s: %v", path, err)
+		},
+	}
+	if a.useCustomIgnore && a.currentCustomIgnorePatterns != nil {
+		opts.Filters = append(opts.Filters, walker.CustomIgnore(a.currentCustomIgnorePatterns, walker.Exclude))
+	}
+	a.applyIncludeMode(&opts)
+	return opts
+}
+
+// ruleWalkOptions is selectionWalkOptions for callers that start from files rather
+// than from the tree's selection: the .gitignore rules the frontend would turn into
+// exclusions are applied directly.
+func (a *App) ruleWalkOptions(rootDir string) walker.Options {
+	opts := a.selectionWalkOptions(nil)
+	if a.useGitignore {
+		opts.Filters = append(opts.Filters, walker.Gitignore(a.projectIgnoreStack(rootDir), walker.Exclude))
+	}
+	return opts
+}
+
+func (a *App) applyIncludeMode(opts *walker.Options) {
+	include := a.activeIncludeMatcher()
+	if include == nil {
+		return
+	}
+	opts.Filters = append(opts.Filters, walker.MatchFiles(includeFilterName, include.MatchesFile))
+	opts.PruneEmptyDirs = true
+}
+
+// treeTotals decides how much of each file counts toward the totals of the folders
+// above it: what context generation would include with the current ignore toggles
+// and large-file policy.
+type treeTotals struct {
+	useGitignore    bool
+	useCustomIgnore bool
+	maxFileSize     int64
+	omitOversized   bool
+}
+
+func (a *App) treeTotals() treeTotals {
+	largeFiles := a.largeFileSettings()
+	return treeTotals{
+		useGitignore:    a.useGitignore,
+		useCustomIgnore: a.useCustomIgnore,
+		maxFileSize:     largeFiles.MaxFileSizeBytes,
+		omitOversized:   largeFiles.Policy == largeFilePolicyOmit,
+	}
+}
+
+// excluded reports whether the ignore rules keep n out of the context.
+func (t treeTotals) excluded(n *FileNode) bool {
+	return (t.useGitignore && n.IsGitignored) || (t.useCustomIgnore && n.IsCustomIgnored)
+}
+
+// fileNodesFromEntries converts walker entries into the FileNode tree sent to the frontend.
+// Directories carry the totals of the files below them that context generation includes.
+func fileNodesFromEntries(entries []*walker.Entry, totals treeTotals) []*FileNode {
+	nodes := make([]*FileNode, 0, len(entries))
+	for _, entry := range entries {
+		node := &FileNode{
+			Name:            entry.Name,
+			Path:            entry.Path,
+			RelPath:         entry.RelPath,
+			IsDir:           entry.IsDir,
+			IsSymlink:       entry.IsSymlink && !entry.Followed,
+			IsOversized:     entry.HasContent() && entry.Marked(walker.FilterSize),
+			IsGitignored:    entry.Marked(walker.FilterGitignore),
+			IsCustomIgnored: entry.Marked(walker.FilterCustomIgnore),
+		}
+		if entry.HasContent() {
+			node.Size = entry.Size
+			node.ModTime = entry.ModTime.UnixMilli()
+			node.Language = langdetect.Detect(entry.Name)
+			node.Tokens = tokens.Estimate(entry.Size)
+		}
+		if len(entry.Children) > 0 {
+			node.HasChildren = true
+			node.Children = fileNodesFromEntries(entry.Children, totals)
+			for _, child := range node.Children {
+				node.addTotals(child, totals)
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// addTotals folds child's size, token estimate, file count and latest change into n.
+// Ignored children and omitted large files add nothing; truncated ones add the part
+// that is included.
+func (n *FileNode) addTotals(child *FileNode, totals treeTotals) {
+	if totals.excluded(child) || child.IsSymlink {
+		return
+	}
+	if child.IsDir {
+		n.Size += child.Size
+		n.Tokens += child.Tokens
+		n.FileCount += child.FileCount
+	} else {
+		if child.IsOversized && totals.omitOversized {
+			return
+		}
+		size := child.Size
+		if child.IsOversized && totals.maxFileSize > 0 {
+			size = min(size, totals.maxFileSize)
+		}
+		n.Size += size
+		n.Tokens += tokens.Estimate(size)
+		n.FileCount++
+	}
+	if child.ModTime > n.ModTime {
+		n.ModTime = child.ModTime
+	}
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"shotgun_code/internal/ignore"
+	"shotgun_code/internal/walker"
+)
+
+// writeProject creates files below a new temp dir; paths use forward slashes.
+func writeProject(t *testing.T, files map[string]string) string {
+	t.Helper()
+	// Keep the user's global git excludes out of the fixtures.
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	for rel, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// frontendExcludedPaths mirrors buildExcludedPathsPayload in MainLayout.vue for a
+// tree without manual toggles other than manual, which are excluded.
+func frontendExcludedPaths(a *App, nodes []*FileNode, manual map[string]bool) []string {
+	var out []string
+	for _, n := range nodes {
+		rel := filepath.ToSlash(n.RelPath)
+		if manual[rel] || (a.useGitignore && n.IsGitignored) || (a.useCustomIgnore && n.IsCustomIgnored) {
+			out = append(out, n.RelPath)
+			continue
+		}
+		out = append(out, frontendExcludedPaths(a, n.Children, manual)...)
+	}
+	return out
+}
+
+// listedFiles returns the files of the tree that the frontend leaves selected.
+func listedFiles(a *App, nodes []*FileNode, manual map[string]bool) []string {
+	var out []string
+	for _, n := range nodes {
+		rel := filepath.ToSlash(n.RelPath)
+		if manual[rel] || (a.useGitignore && n.IsGitignored) || (a.useCustomIgnore && n.IsCustomIgnored) {
+			continue
+		}
+		if !n.IsDir && !n.IsSymlink {
+			out = append(out, rel)
+		}
+		out = append(out, listedFiles(a, n.Children, manual)...)
+	}
+	sort.Strings(out)
+	return out
+}
+
+var contextFileRegex = regexp.MustCompile(`(?m)^<file path="([^"]+)"`)
+
+func TestWalkConsumersAgree(t *testing.T) {
+	files := map[string]string{
+		".gitignore":                 "build/\n*.log\n",
+		"main.go":                    "package main\n",
+		"util.go":                    "package main\n",
+		"debug.log":                  "log\n",
+		"build/out.txt":              "out\n",
+		"docs/readme.md":             "# docs\n",
+		"docs/notes.txt":             "notes\n",
+		"vendor/lib.go":              "package lib\n",
+		"internal/nested/.gitignore": "secret.go\n",
+		"internal/nested/secret.go":  "package nested\n",
+		"internal/nested/open.go":    "package nested\n",
+		"assets/logo.png":            "\x89PNG\x00\x01\x02",
+		"big.txt":                    strings.Repeat("x", 2000),
+	}
+	base := []string{".gitignore", "assets/logo.png", "big.txt", "docs/notes.txt", "docs/readme.md", "internal/nested/.gitignore", "internal/nested/open.go", "main.go", "util.go"}
+
+	tests := []struct {
+		name        string
+		noGitignore bool
+		noCustom    bool
+		include     string
+		manual      []string
+		want        []string
+		wantOmitted []string // Listed in the context tree without content
+	}{
+		{
+			name:        "defaults",
+			want:        base,
+			wantOmitted: []string{"assets/logo.png"},
+		},
+		{
+			name:        "gitignore off",
+			noGitignore: true,
+			want:        sorted(append(slices.Clone(base), "build/out.txt", "debug.log", "internal/nested/secret.go")),
+			wantOmitted: []string{"assets/logo.png"},
+		},
+		{
+			name:        "custom rules off",
+			noCustom:    true,
+			want:        sorted(append(slices.Clone(base), "vendor/lib.go")),
+			wantOmitted: []string{"assets/logo.png"},
+		},
+		{
+			name:    "include patterns",
+			include: "**/*.go\ndocs/\n",
+			want:    []string{"docs/notes.txt", "docs/readme.md", "internal/nested/open.go", "main.go", "util.go"},
+		},
+		{
+			name:        "manual exclusions",
+			manual:      []string{"docs", "util.go"},
+			want:        []string{".gitignore", "assets/logo.png", "big.txt", "internal/nested/.gitignore", "internal/nested/open.go", "main.go"},
+			wantOmitted: []string{"assets/logo.png"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := writeProject(t, files)
+			a := &App{ctx: context.Background(), useGitignore: !tt.noGitignore, useCustomIgnore: !tt.noCustom}
+			a.settings.MaxFileSizeBytes = 1000
+			a.settings.CustomIgnoreRules = "vendor/\n"
+			a.currentCustomIgnorePatterns = ignore.ParseRules(customIgnoreRulesSource, "", a.settings.CustomIgnoreRules)
+			if tt.include != "" {
+				matcher, err := compileIncludePatterns(tt.include)
+				if err != nil {
+					t.Fatal(err)
+				}
+				a.useIncludePatterns, a.currentIncludePatterns = true, matcher
+			}
+			manual := make(map[string]bool)
+			for _, p := range tt.manual {
+				manual[p] = true
+			}
+
+			// ListFiles, as the frontend sees it.
+			nodes, err := a.listFileTree(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			listed := listedFiles(a, nodes[0].Children, manual)
+			if !slices.Equal(listed, tt.want) {
+				t.Errorf("ListFiles selection = %v, want %v", listed, tt.want)
+			}
+
+			// Context generation, with the exclusions the frontend derives from that tree.
+			output, _, err := a.generateShotgunOutput(context.Background(), root, frontendExcludedPaths(a, nodes[0].Children, manual), func(*generationProgressState) {})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var generated []string
+			for _, m := range contextFileRegex.FindAllStringSubmatch(output, -1) {
+				generated = append(generated, m[1])
+			}
+			for _, omitted := range tt.wantOmitted {
+				generated = append(generated, omitted)
+				if !strings.Contains(output, filepath.Base(omitted)+" (") {
+					t.Errorf("context tree does not annotate omitted file %s", omitted)
+				}
+			}
+			sort.Strings(generated)
+			if !slices.Equal(generated, listed) {
+				t.Errorf("context files = %v, want %v", generated, listed)
+			}
+			// big.txt is over the per-file limit and truncated under the default policy.
+			if slices.Contains(listed, "big.txt") && !strings.Contains(output, "(truncated: showing the first 1.0 kB of 2.0 kB)") {
+				t.Error("truncated big.txt has no truncation marker")
+			}
+
+			// The auto-context tree gets only the rule-based exclusions from the frontend.
+			_, tree, err := buildAutoContextTree(context.Background(), root, a.selectionWalkOptions(frontendExcludedPaths(a, nodes[0].Children, nil)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var auto []string
+			tree.Visit(func(e *walker.Entry) error {
+				if e.HasContent() {
+					auto = append(auto, e.SlashPath())
+				}
+				return nil
+			})
+			sort.Strings(auto)
+			if wantAuto := listedFiles(a, nodes[0].Children, nil); !slices.Equal(auto, wantAuto) {
+				t.Errorf("auto-context files = %v, want %v", auto, wantAuto)
+			}
+		})
+	}
+}
+
+func sorted(paths []string) []string {
+	sort.Strings(paths)
+	return paths
+}
+
+func TestTreeTotalsCountIncludedFiles(t *testing.T) {
+	root := writeProject(t, map[string]string{
+		".gitignore":    "*.log\n",                 // 6 bytes
+		"src/a.go":      "package a\n",             // 10 bytes
+		"src/debug.log": "debug\n",                 // Gitignored
+		"vendor/c.go":   "package c\n",             // Custom-ignored
+		"big.txt":       strings.Repeat("x", 2000), // Over the 1000-byte limit
+	})
+
+	tests := []struct {
+		name      string
+		policy    string
+		gitignore bool
+		wantFiles int
+		wantSize  int64
+	}{
+		{"truncate", largeFilePolicyTruncate, true, 3, 6 + 10 + 1000},
+		{"omit", largeFilePolicyOmit, true, 2, 6 + 10},
+		{"gitignore off", largeFilePolicyOmit, false, 3, 6 + 10 + 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &App{ctx: context.Background(), useGitignore: tt.gitignore, useCustomIgnore: true}
+			a.settings.MaxFileSizeBytes = 1000
+			a.settings.LargeFilePolicy = tt.policy
+			a.currentCustomIgnorePatterns = ignore.ParseRules(customIgnoreRulesSource, "", "vendor/\n")
+			nodes, err := a.listFileTree(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := nodes[0]; got.FileCount != tt.wantFiles || got.Size != tt.wantSize {
+				t.Errorf("root totals = %d files, %d bytes; want %d files, %d bytes", got.FileCount, got.Size, tt.wantFiles, tt.wantSize)
+			}
+		})
+	}
+}
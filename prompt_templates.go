@@ -0,0 +1,506 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+//go:embed design/prompts/prompt_makeDiffGitFormat.md design/prompts/prompt_makePlan.md design/prompts/prompt_analyzeBug.md design/prompts/prompt_projectManager.md
+var builtinPromptFS embed.FS
+
+const maxPromptTemplateVersions = 20
+
+// Types of a template variable.
+const (
+	templateVarText   = "text"
+	templateVarNumber = "number"
+	templateVarDate   = "date" // YYYY-MM-DD; empty means today
+	templateVarChoice = "choice"
+)
+
+// Variables the built-in templates use. The frontend fills them from the task,
+// the custom prompt rules and the generated context.
+const (
+	templateVarTask          = "TASK"
+	templateVarRules         = "RULES"
+	templateVarFileStructure = "FILE_STRUCTURE"
+	templateVarCurrentDate   = "CURRENT_DATE"
+)
+
+var (
+	templatePlaceholderRegex = regexp.MustCompile(`\{([A-Z][A-Z0-9_]*)\}`)
+	templateIDRegex          = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+)
+
+// TemplateVariable is a {NAME} placeholder a template declares.
+type TemplateVariable struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"` // "text", "number", "date" or "choice"
+	Description string   `json:"description,omitempty"`
+	Required    bool     `json:"required,omitempty"`
+	Default     string   `json:"default,omitempty"` // Used when no value is given
+	Options     []string `json:"options,omitempty"` // Allowed values of a choice
+}
+
+// PromptTemplate is a prompt with {NAME} placeholders. A user template with the id
+// of a built-in one overrides it until it is deleted.
+type PromptTemplate struct {
+	ID          string                  `json:"id"`
+	Name        string                  `json:"name"`
+	Description string                  `json:"description,omitempty"`
+	Content     string                  `json:"content"`
+	Variables   []TemplateVariable      `json:"variables"`
+	Builtin     bool                    `json:"builtin"`           // Shipped with the app and not edited
+	Overrides   bool                    `json:"overrides"`         // A user edit of a built-in template
+	Version     int                     `json:"version"`           // 0 for an unedited built-in template
+	UpdatedAt   time.Time               `json:"updatedAt"`         // Zero for an unedited built-in template
+	History     []PromptTemplateVersion `json:"history,omitempty"` // Earlier versions, newest first
+}
+
+// PromptTemplateVersion is a saved earlier version of a user template.
+type PromptTemplateVersion struct {
+	Version   int                `json:"version"`
+	Name      string             `json:"name"`
+	Content   string             `json:"content"`
+	Variables []TemplateVariable `json:"variables"`
+	SavedAt   time.Time          `json:"savedAt"`
+}
+
+type builtinPromptTemplate struct {
+	id, name, path string
+}
+
+// builtinPromptTemplates are listed in the order the frontend shows them; the ids
+// are the keys it uses.
+var builtinPromptTemplates = []builtinPromptTemplate{
+	{"architect", "Architect", "design/prompts/prompt_makePlan.md"},
+	{"findBug", "Test", "design/prompts/prompt_analyzeBug.md"},
+	{"dev", "Dev", "design/prompts/prompt_makeDiffGitFormat.md"},
+	{"projectManager", "Project: Update Tasks", "design/prompts/prompt_projectManager.md"},
+}
+
+var builtinTemplateVariables = map[string]TemplateVariable{
+	templateVarTask:          {Name: templateVarTask, Type: templateVarText, Description: "What the model should do", Default: "No task provided by the user."},
+	templateVarRules:         {Name: templateVarRules, Type: templateVarText, Description: "Custom prompt rules"},
+	templateVarFileStructure: {Name: templateVarFileStructure, Type: templateVarText, Description: "The generated project context", Default: "No file structure context provided."},
+	templateVarCurrentDate:   {Name: templateVarCurrentDate, Type: templateVarDate, Description: "Today's date"},
+}
+
+type promptTemplateFile struct {
+	Templates []PromptTemplate `json:"templates"`
+}
+
+// PromptTemplateRegistry holds the built-in templates and the user templates stored
+// next to settings.json.
+type PromptTemplateRegistry struct {
+	app      *App
+	path     string
+	mu       sync.Mutex
+	builtins []PromptTemplate
+	user     []PromptTemplate
+	// unreadable is set when the stored templates could not be read, or parsed
+	// and moved aside; saving would overwrite them, so it is refused.
+	unreadable error
+}
+
+func NewPromptTemplateRegistry(app *App) *PromptTemplateRegistry {
+	return &PromptTemplateRegistry{app: app}
+}
+
+func (r *PromptTemplateRegistry) getFilePath() (string, error) {
+	if r.path != "" {
+		return r.path, nil
+	}
+	if r.app.configPath != "" {
+		r.path = filepath.Join(filepath.Dir(r.app.configPath), "prompt_templates.json")
+		return r.path, nil
+	}
+	return "", errors.New("config path not initialized in App")
+}
+
+// Load reads the built-in templates and the stored user templates. Like the
+// auto-context template, a built-in template on disk wins over the embedded copy.
+func (r *PromptTemplateRegistry) Load() error {
+	builtins, err := loadBuiltinPromptTemplates()
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.builtins = builtins
+	r.user, r.unreadable = nil, nil
+
+	path, err := r.getFilePath()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		r.unreadable = fmt.Errorf("%s cannot be read: %w", path, err)
+		return err
+	}
+	var file promptTemplateFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		// Keep the unreadable file for the user to repair; templates saved from
+		// now on start a new one.
+		backup := fmt.Sprintf("%s.%s.bak", path, time.Now().Format("20060102-150405"))
+		if renameErr := os.Rename(path, backup); renameErr != nil {
+			r.unreadable = fmt.Errorf("%s cannot be parsed: %w", path, err)
+			return fmt.Errorf("failed to parse %s, saving templates is disabled: %w", path, err)
+		}
+		return fmt.Errorf("failed to parse %s, moved it to %s: %w", path, backup, err)
+	}
+	r.user = file.Templates
+	return nil
+}
+
+func loadBuiltinPromptTemplates() ([]PromptTemplate, error) {
+	templates := make([]PromptTemplate, 0, len(builtinPromptTemplates))
+	for _, b := range builtinPromptTemplates {
+		content, err := os.ReadFile(b.path)
+		if err != nil {
+			if content, err = builtinPromptFS.ReadFile(b.path); err != nil {
+				return nil, fmt.Errorf("failed to load prompt template %s: %w", b.path, err)
+			}
+		}
+		t := PromptTemplate{ID: b.id, Name: b.name, Content: string(content), Builtin: true}
+		for _, name := range templatePlaceholders(t.Content) {
+			if v, ok := builtinTemplateVariables[name]; ok {
+				t.Variables = append(t.Variables, v)
+			}
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+func (r *PromptTemplateRegistry) saveLocked() error {
+	path, err := r.getFilePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(promptTemplateFile{Templates: r.user}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func (r *PromptTemplateRegistry) userIndexLocked(id string) int {
+	for i, t := range r.user {
+		if t.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r *PromptTemplateRegistry) builtinLocked(id string) (PromptTemplate, bool) {
+	for _, t := range r.builtins {
+		if t.ID == id {
+			return t, true
+		}
+	}
+	return PromptTemplate{}, false
+}
+
+func (r *PromptTemplateRegistry) getLocked(id string) (PromptTemplate, bool) {
+	if i := r.userIndexLocked(id); i >= 0 {
+		return r.user[i], true
+	}
+	return r.builtinLocked(id)
+}
+
+// List returns the templates without their history: built-in ones in their fixed
+// order, then user templates by name.
+func (r *PromptTemplateRegistry) List() []PromptTemplate {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	templates := make([]PromptTemplate, 0, len(r.builtins)+len(r.user))
+	for _, b := range r.builtins {
+		t, _ := r.getLocked(b.ID)
+		templates = append(templates, t)
+	}
+	var own []PromptTemplate
+	for _, t := range r.user {
+		if _, ok := r.builtinLocked(t.ID); !ok {
+			own = append(own, t)
+		}
+	}
+	sort.SliceStable(own, func(i, j int) bool { return strings.ToLower(own[i].Name) < strings.ToLower(own[j].Name) })
+	templates = append(templates, own...)
+	for i := range templates {
+		templates[i].History = nil
+	}
+	return templates
+}
+
+// Get returns the template id with its history.
+func (r *PromptTemplateRegistry) Get(id string) (PromptTemplate, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.getLocked(id)
+	t.History = append([]PromptTemplateVersion(nil), t.History...)
+	return t, ok
+}
+
+// Save validates t and stores it as the next version of the user template t.ID,
+// keeping the previous version in its history.
+func (r *PromptTemplateRegistry) Save(t PromptTemplate) (PromptTemplate, error) {
+	t.ID = strings.TrimSpace(t.ID)
+	t.Name = strings.TrimSpace(t.Name)
+	if err := validatePromptTemplate(t); err != nil {
+		return PromptTemplate{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.unreadable != nil {
+		return PromptTemplate{}, fmt.Errorf("failed to save prompt templates: %w", r.unreadable)
+	}
+	saved := PromptTemplate{
+		ID:          t.ID,
+		Name:        t.Name,
+		Description: strings.TrimSpace(t.Description),
+		Content:     t.Content,
+		Variables:   t.Variables,
+		Version:     1,
+		UpdatedAt:   time.Now(),
+	}
+	_, saved.Overrides = r.builtinLocked(t.ID)
+	i := r.userIndexLocked(t.ID)
+	if i >= 0 {
+		previous := r.user[i]
+		saved.Version = previous.Version + 1
+		saved.History = append([]PromptTemplateVersion{{
+			Version:   previous.Version,
+			Name:      previous.Name,
+			Content:   previous.Content,
+			Variables: previous.Variables,
+			SavedAt:   previous.UpdatedAt,
+		}}, previous.History...)
+		if len(saved.History) > maxPromptTemplateVersions {
+			saved.History = saved.History[:maxPromptTemplateVersions]
+		}
+		r.user[i] = saved
+	} else {
+		r.user = append(r.user, saved)
+	}
+	if err := r.saveLocked(); err != nil {
+		return PromptTemplate{}, fmt.Errorf("failed to save prompt templates: %w", err)
+	}
+	return saved, nil
+}
+
+// Delete removes the user template id. For an override this brings back the
+// built-in template.
+func (r *PromptTemplateRegistry) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.unreadable != nil {
+		return fmt.Errorf("failed to save prompt templates: %w", r.unreadable)
+	}
+	i := r.userIndexLocked(id)
+	if i < 0 {
+		if _, ok := r.builtinLocked(id); ok {
+			return fmt.Errorf("prompt template %s is built in and cannot be deleted", id)
+		}
+		return fmt.Errorf("prompt template %s not found", id)
+	}
+	r.user = append(r.user[:i], r.user[i+1:]...)
+	return r.saveLocked()
+}
+
+// validatePromptTemplate checks the id, the variable declarations and that every
+// {NAME} placeholder in the content is declared.
+func validatePromptTemplate(t PromptTemplate) error {
+	if !templateIDRegex.MatchString(t.ID) {
+		return fmt.Errorf("template id %q must be letters, digits, '-' or '_'", t.ID)
+	}
+	if t.Name == "" {
+		return errors.New("template name is required")
+	}
+	if strings.TrimSpace(t.Content) == "" {
+		return errors.New("template content is required")
+	}
+	declared := make(map[string]bool, len(t.Variables))
+	for _, v := range t.Variables {
+		if !templatePlaceholderRegex.MatchString("{" + v.Name + "}") {
+			return fmt.Errorf("variable name %q must be upper case letters, digits or '_'", v.Name)
+		}
+		if declared[v.Name] {
+			return fmt.Errorf("variable %s is declared twice", v.Name)
+		}
+		declared[v.Name] = true
+		switch v.Type {
+		case templateVarText, templateVarNumber, templateVarDate:
+		case templateVarChoice:
+			if len(v.Options) == 0 {
+				return fmt.Errorf("choice variable %s has no options", v.Name)
+			}
+		default:
+			return fmt.Errorf("variable %s has unknown type %q", v.Name, v.Type)
+		}
+		if v.Default != "" {
+			if err := checkTemplateValue(v, v.Default); err != nil {
+				return fmt.Errorf("default of %w", err)
+			}
+		}
+	}
+	for _, name := range templatePlaceholders(t.Content) {
+		if !declared[name] {
+			return fmt.Errorf("the template uses {%s} but does not declare it", name)
+		}
+	}
+	return nil
+}
+
+func checkTemplateValue(v TemplateVariable, value string) error {
+	switch v.Type {
+	case templateVarNumber:
+		if _, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err != nil {
+			return fmt.Errorf("variable %s must be a number, got %q", v.Name, value)
+		}
+	case templateVarDate:
+		if _, err := time.Parse("2006-01-02", strings.TrimSpace(value)); err != nil {
+			return fmt.Errorf("variable %s must be a date (YYYY-MM-DD), got %q", v.Name, value)
+		}
+	case templateVarChoice:
+		for _, option := range v.Options {
+			if value == option {
+				return nil
+			}
+		}
+		return fmt.Errorf("variable %s must be one of %s, got %q", v.Name, strings.Join(v.Options, ", "), value)
+	}
+	return nil
+}
+
+// templatePlaceholders returns the distinct {NAME} placeholders of content in order
+// of first use.
+func templatePlaceholders(content string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, m := range templatePlaceholderRegex.FindAllStringSubmatch(content, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// renderPromptTemplate fills the declared variables of t from values, falling back
+// to their defaults; an empty date is today. Values are inserted in one pass, so
+// braces inside them (e.g. in source code) are left alone.
+func renderPromptTemplate(t PromptTemplate, values map[string]string, now time.Time) (string, error) {
+	resolved := make(map[string]string, len(t.Variables))
+	for _, v := range t.Variables {
+		value, ok := values[v.Name]
+		if !ok || value == "" {
+			value = v.Default
+		}
+		if value == "" && v.Type == templateVarDate {
+			value = now.Format("2006-01-02")
+		}
+		if value == "" {
+			if v.Required {
+				return "", fmt.Errorf("variable %s is required", v.Name)
+			}
+		} else if err := checkTemplateValue(v, value); err != nil {
+			return "", err
+		}
+		resolved[v.Name] = value
+	}
+	return templatePlaceholderRegex.ReplaceAllStringFunc(t.Content, func(placeholder string) string {
+		if value, ok := resolved[placeholder[1:len(placeholder)-1]]; ok {
+			return value
+		}
+		return placeholder
+	}), nil
+}
+
+// --- App Methods Binding ---
+
+// ListPromptTemplates returns the built-in and user prompt templates.
+func (a *App) ListPromptTemplates() []PromptTemplate {
+	if a.promptTemplates == nil {
+		return []PromptTemplate{}
+	}
+	return a.promptTemplates.List()
+}
+
+// GetPromptTemplate returns the template id with its version history.
+func (a *App) GetPromptTemplate(id string) (PromptTemplate, error) {
+	if a.promptTemplates == nil {
+		return PromptTemplate{}, errors.New("prompt templates are not initialized")
+	}
+	t, ok := a.promptTemplates.Get(id)
+	if !ok {
+		return PromptTemplate{}, fmt.Errorf("prompt template %s not found", id)
+	}
+	return t, nil
+}
+
+// SavePromptTemplate creates or edits a user template. Saving under the id of a
+// built-in template overrides it.
+func (a *App) SavePromptTemplate(t PromptTemplate) (PromptTemplate, error) {
+	if a.promptTemplates == nil {
+		return PromptTemplate{}, errors.New("prompt templates are not initialized")
+	}
+	saved, err := a.promptTemplates.Save(t)
+	if err != nil {
+		return PromptTemplate{}, err
+	}
+	wailsRuntime.LogInfof(a.ctx, "Prompt template %s saved as version %d", saved.ID, saved.Version)
+	return saved, nil
+}
+
+// RestorePromptTemplateVersion saves an earlier version of the user template id as
+// its newest version.
+func (a *App) RestorePromptTemplateVersion(id string, version int) (PromptTemplate, error) {
+	t, err := a.GetPromptTemplate(id)
+	if err != nil {
+		return PromptTemplate{}, err
+	}
+	for _, v := range t.History {
+		if v.Version == version {
+			t.Name, t.Content, t.Variables = v.Name, v.Content, v.Variables
+			return a.SavePromptTemplate(t)
+		}
+	}
+	return PromptTemplate{}, fmt.Errorf("prompt template %s has no version %d", id, version)
+}
+
+// DeletePromptTemplate removes the user template id, restoring the built-in one it
+// overrode, if any.
+func (a *App) DeletePromptTemplate(id string) error {
+	if a.promptTemplates == nil {
+		return errors.New("prompt templates are not initialized")
+	}
+	return a.promptTemplates.Delete(id)
+}
+
+// RenderPromptTemplate fills the template id with values keyed by variable name.
+func (a *App) RenderPromptTemplate(id string, values map[string]string) (string, error) {
+	t, err := a.GetPromptTemplate(id)
+	if err != nil {
+		return "", err
+	}
+	return renderPromptTemplate(t, values, time.Now())
+}
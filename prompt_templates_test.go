@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestTemplateRegistry(t *testing.T) (*PromptTemplateRegistry, string) {
+	t.Helper()
+	dir := t.TempDir()
+	r := NewPromptTemplateRegistry(&App{configPath: filepath.Join(dir, "settings.json")})
+	return r, filepath.Join(dir, "prompt_templates.json")
+}
+
+var testUserTemplate = PromptTemplate{
+	ID:        "review",
+	Name:      "Review",
+	Content:   "Review {TASK}",
+	Variables: []TemplateVariable{{Name: "TASK", Type: templateVarText}},
+}
+
+func TestPromptTemplatesKeepUnparsableFile(t *testing.T) {
+	r, path := newTestTemplateRegistry(t)
+	broken := `{"templates": [{"id": "mine", "content": "half`
+	if err := os.WriteFile(path, []byte(broken), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Load(); err == nil {
+		t.Fatal("Load accepted an unparsable file")
+	}
+	backups, _ := filepath.Glob(path + ".*.bak")
+	if len(backups) != 1 {
+		t.Fatalf("backups = %v", backups)
+	}
+	if data, _ := os.ReadFile(backups[0]); string(data) != broken {
+		t.Errorf("backup = %q, want the unparsable file", data)
+	}
+
+	if _, err := r.Save(testUserTemplate); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(backups[0]); string(data) != broken {
+		t.Errorf("saving changed the backup to %q", data)
+	}
+	if err := r.Load(); err != nil {
+		t.Errorf("the file saved after the backup does not load: %v", err)
+	}
+}
+
+func TestPromptTemplatesRefuseToOverwriteUnreadableFile(t *testing.T) {
+	r, path := newTestTemplateRegistry(t)
+	if err := os.Mkdir(path, 0o755); err != nil { // Cannot be read as a file
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(path, "keep"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Load(); err == nil {
+		t.Fatal("Load read a directory")
+	}
+	if _, err := r.Save(testUserTemplate); err == nil || !strings.Contains(err.Error(), "cannot be read") {
+		t.Errorf("Save = %v, want a refusal", err)
+	}
+	if len(r.List()) != len(builtinPromptTemplates) {
+		t.Errorf("a refused save changed the templates: %v", r.List())
+	}
+}
@@ -0,0 +1,383 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"shotgun_code/internal/llm/provider"
+	"shotgun_code/internal/retrieval"
+	"shotgun_code/internal/walker"
+)
+
+const (
+	defaultRetrievalLimit     = 20
+	maxRetrievalLimit         = 200
+	defaultRetrievalPrefilter = 60 // Files auto-context chooses from when pre-filtering
+	retrievalFilterName       = "retrieval"
+)
+
+// retrievalRefreshDelay batches watcher events before changed files are re-embedded.
+var retrievalRefreshDelay = 10 * time.Second
+
+// RetrievalSettings configures embedding-based file retrieval. Without a BaseURL,
+// embeddings come from the active LLM provider.
+type RetrievalSettings struct {
+	Enabled       bool   `json:"enabled"`       // Keep the index current and pre-filter auto-context with it
+	BaseURL       string `json:"baseURL"`       // OpenAI-compatible embeddings endpoint, e.g. a local server
+	Model         string `json:"model"`         // Embedding model served at BaseURL
+	APIKey        string `json:"apiKey"`        // Optional key for BaseURL
+	PrefilterTopK int    `json:"prefilterTopK"` // Files shown to auto-context; 0 means the default
+}
+
+// RetrievalIndexStatus describes a project's retrieval index after an update.
+type RetrievalIndexStatus struct {
+	Model   string                `json:"model"`
+	Files   int                   `json:"files"`
+	Chunks  int                   `json:"chunks"`
+	Updated retrieval.UpdateStats `json:"updated"`
+}
+
+// errRetrievalIndexBuilding is returned by the pre-filter until the index of the
+// project has been brought up to date in the background.
+var errRetrievalIndexBuilding = errors.New("retrieval index is still being built")
+
+// retrievalState is the index of the current project and the pending watcher refresh.
+// Watchman reads it from its own goroutine, so fields other than updateMu are
+// guarded by mu and nothing there reads a.settings.
+type retrievalState struct {
+	mu       sync.Mutex
+	enabled  bool // Mirrors settings.Retrieval.Enabled
+	rootDir  string
+	index    *retrieval.Index
+	embedder provider.Embedder // Source of the index's vectors, reused by refreshes
+	walk     retrievalWalk     // How refreshes walk rootDir, captured when settings change
+	ready    bool              // index was brought up to date since it was opened
+	building bool              // A background update is running
+	updateMu sync.Mutex        // Serializes index updates so no file is embedded twice
+	timer    *time.Timer
+	// refreshed reports the outcome of a watcher refresh; nil logs it.
+	refreshed func(retrieval.UpdateStats, error)
+}
+
+// retrievalWalk is what an index update walks with: the listing's walk options and
+// the ignore toggles deciding which marked files count.
+type retrievalWalk struct {
+	opts            walker.Options
+	useGitignore    bool
+	useCustomIgnore bool
+}
+
+// retrievalWalkFor reads the settings into a retrievalWalk for rootDir. It must run
+// where the settings are changed, not on the watcher's goroutine.
+func (a *App) retrievalWalkFor(rootDir string) retrievalWalk {
+	return retrievalWalk{
+		opts:            a.listingWalkOptions(a.projectIgnoreStack(rootDir)),
+		useGitignore:    a.useGitignore,
+		useCustomIgnore: a.useCustomIgnore,
+	}
+}
+
+// noteRetrievalSettings captures the walk settings for the watcher refreshes of the
+// loaded index. Setters of anything retrievalWalkFor reads call it.
+func (a *App) noteRetrievalSettings() {
+	a.retrieval.mu.Lock()
+	rootDir := a.retrieval.rootDir
+	a.retrieval.mu.Unlock()
+	if rootDir == "" {
+		return
+	}
+	walk := a.retrievalWalkFor(rootDir)
+	a.retrieval.mu.Lock()
+	if a.retrieval.rootDir == rootDir {
+		a.retrieval.walk = walk
+	}
+	a.retrieval.mu.Unlock()
+}
+
+// GetRetrievalSettings returns the retrieval settings.
+func (a *App) GetRetrievalSettings() RetrievalSettings {
+	return a.settings.Retrieval
+}
+
+// SetRetrievalSettings saves the retrieval settings. A different embedding source
+// makes the next use start a fresh index.
+func (a *App) SetRetrievalSettings(settings RetrievalSettings) error {
+	settings.BaseURL = strings.TrimSpace(settings.BaseURL)
+	settings.Model = strings.TrimSpace(settings.Model)
+	settings.APIKey = strings.TrimSpace(settings.APIKey)
+	if settings.BaseURL != "" && settings.Model == "" {
+		return errors.New("an embedding model is required for a custom embeddings endpoint")
+	}
+	if settings.PrefilterTopK < 0 || settings.PrefilterTopK > maxRetrievalLimit {
+		return fmt.Errorf("pre-filter size must be between 0 and %d, got %d", maxRetrievalLimit, settings.PrefilterTopK)
+	}
+	a.settings.Retrieval = settings
+	runtime.LogInfof(a.ctx, "App setting retrieval changed to: enabled=%v, endpoint=%q, model=%q", settings.Enabled, settings.BaseURL, settings.Model)
+	a.retrieval.mu.Lock()
+	a.retrieval.enabled = settings.Enabled
+	a.retrieval.index, a.retrieval.embedder, a.retrieval.ready = nil, nil, false
+	a.retrieval.mu.Unlock()
+	return a.saveSettings()
+}
+
+// BuildRetrievalIndex brings the retrieval index of rootDir up to date, embedding
+// only files that are new or changed since the last update.
+func (a *App) BuildRetrievalIndex(rootDir string) (RetrievalIndexStatus, error) {
+	rootDir = strings.TrimSpace(rootDir)
+	if rootDir == "" {
+		return RetrievalIndexStatus{}, errors.New("project root is required")
+	}
+	index, embedder, err := a.retrievalIndexFor(rootDir)
+	if err != nil {
+		return RetrievalIndexStatus{}, err
+	}
+	stats, err := a.updateRetrievalIndex(a.ctx, rootDir, index, embedder, a.retrievalWalkFor(rootDir))
+	files, chunks := index.Len()
+	status := RetrievalIndexStatus{Model: index.Model(), Files: files, Chunks: chunks, Updated: stats}
+	if err != nil {
+		return status, err
+	}
+	runtime.LogInfof(a.ctx, "Retrieval index for %s: %d files, %d chunks (%d embedded, %d removed)", rootDir, files, chunks, stats.Embedded, stats.Removed)
+	return status, nil
+}
+
+// RetrieveFiles returns up to limit files of rootDir ranked by embedding similarity
+// to query. The index is updated first, so changed files are re-embedded.
+func (a *App) RetrieveFiles(rootDir, query string, limit int) ([]retrieval.Hit, error) {
+	rootDir = strings.TrimSpace(rootDir)
+	if rootDir == "" {
+		return nil, errors.New("project root is required")
+	}
+	if strings.TrimSpace(query) == "" {
+		return []retrieval.Hit{}, nil
+	}
+	if limit <= 0 {
+		limit = defaultRetrievalLimit
+	}
+	limit = min(limit, maxRetrievalLimit)
+	return a.retrieve(a.ctx, rootDir, query, limit)
+}
+
+func (a *App) retrieve(ctx context.Context, rootDir, query string, limit int) ([]retrieval.Hit, error) {
+	index, embedder, err := a.retrievalIndexFor(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := a.updateRetrievalIndex(ctx, rootDir, index, embedder, a.retrievalWalkFor(rootDir)); err != nil {
+		return nil, fmt.Errorf("failed to update retrieval index: %w", err)
+	}
+	vectors, err := embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, errors.New("embedder returned no vector for the query")
+	}
+	return index.Search(vectors[0], limit), nil
+}
+
+// retrievalPrefilter returns the files auto-context should choose from for task,
+// the PrefilterTopK files most similar to it. Embedding a whole project takes long,
+// so the index is never updated here: until a background build has brought it up
+// to date, one is started and errRetrievalIndexBuilding returned.
+func (a *App) retrievalPrefilter(rootDir, task string) (map[string]bool, error) {
+	limit := a.settings.Retrieval.PrefilterTopK
+	if limit <= 0 {
+		limit = defaultRetrievalPrefilter
+	}
+	index, embedder, err := a.retrievalIndexFor(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	a.retrieval.mu.Lock()
+	ready := a.retrieval.ready && a.retrieval.index == index
+	a.retrieval.mu.Unlock()
+	if !ready {
+		a.startRetrievalBuild(rootDir)
+		return nil, errRetrievalIndexBuilding
+	}
+	vectors, err := embedder.Embed(a.ctx, []string{task})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed task: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, errors.New("embedder returned no vector for the task")
+	}
+	hits := index.Search(vectors[0], limit)
+	keep := make(map[string]bool, len(hits))
+	for _, hit := range hits {
+		keep[hit.RelPath] = true
+	}
+	return keep, nil
+}
+
+// startRetrievalBuild brings the index of rootDir up to date in the background,
+// unless an update is already running. It returns at once.
+func (a *App) startRetrievalBuild(rootDir string) {
+	index, embedder, err := a.retrievalIndexFor(rootDir)
+	if err != nil {
+		runtime.LogWarningf(a.ctx, "Retrieval index build skipped: %v", err)
+		return
+	}
+	a.retrieval.mu.Lock()
+	if a.retrieval.building {
+		a.retrieval.mu.Unlock()
+		return
+	}
+	a.retrieval.building = true
+	a.retrieval.mu.Unlock()
+
+	walk := a.retrievalWalkFor(rootDir)
+	go func() {
+		defer func() {
+			a.retrieval.mu.Lock()
+			a.retrieval.building = false
+			a.retrieval.mu.Unlock()
+		}()
+		stats, err := a.updateRetrievalIndex(a.ctx, rootDir, index, embedder, walk)
+		if err != nil {
+			runtime.LogWarningf(a.ctx, "Retrieval index build for %s failed: %v", rootDir, err)
+			return
+		}
+		files, chunks := index.Len()
+		runtime.LogInfof(a.ctx, "Retrieval index for %s ready: %d files, %d chunks (%d embedded)", rootDir, files, chunks, stats.Embedded)
+	}()
+}
+
+// retrievalEmbedder returns the configured embeddings endpoint, or the active
+// provider when it can compute embeddings.
+func (a *App) retrievalEmbedder() (provider.Embedder, error) {
+	settings := a.settings.Retrieval
+	if settings.BaseURL != "" {
+		return provider.NewOpenAICompatibleEmbedder(settings.BaseURL, settings.APIKey, settings.Model)
+	}
+	cfg := buildProviderConfig(a.settings.LLMSettings)
+	instance, err := a.getOrCreateProvider(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("no embeddings source: configure an embeddings endpoint or an LLM provider (%w)", err)
+	}
+	embedder, ok := instance.(provider.Embedder)
+	if !ok {
+		return nil, fmt.Errorf("provider %s cannot compute embeddings; configure an embeddings endpoint", cfg.Provider)
+	}
+	return embedder, nil
+}
+
+// retrievalIndexFor opens the index of rootDir for the current embedding model,
+// reusing the loaded one when it matches.
+func (a *App) retrievalIndexFor(rootDir string) (*retrieval.Index, provider.Embedder, error) {
+	embedder, err := a.retrievalEmbedder()
+	if err != nil {
+		return nil, nil, err
+	}
+	walk := a.retrievalWalkFor(rootDir)
+	a.retrieval.mu.Lock()
+	defer a.retrieval.mu.Unlock()
+	a.retrieval.walk = walk
+	if a.retrieval.index != nil && a.retrieval.rootDir == rootDir && a.retrieval.index.Model() == embedder.EmbeddingModel() {
+		return a.retrieval.index, embedder, nil
+	}
+	path, err := a.retrievalIndexPath(rootDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	index, err := retrieval.Open(path, embedder.EmbeddingModel())
+	if err != nil {
+		return nil, nil, err
+	}
+	a.retrieval.rootDir = rootDir
+	a.retrieval.index, a.retrieval.embedder, a.retrieval.ready = index, embedder, false
+	return index, embedder, nil
+}
+
+// retrievalIndexPath places each project's index under the config dir, named by a
+// hash of the project path.
+func (a *App) retrievalIndexPath(rootDir string) (string, error) {
+	if a.configPath == "" {
+		return "", errors.New("config path not initialized in App")
+	}
+	absRoot, err := filepath.Abs(rootDir)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(absRoot))
+	return filepath.Join(filepath.Dir(a.configPath), "retrieval", hex.EncodeToString(sum[:8])+".gob"), nil
+}
+
+// updateRetrievalIndex embeds what changed in the files the tree shows under the
+// ignore toggles and include mode of walk, then saves the index.
+func (a *App) updateRetrievalIndex(ctx context.Context, rootDir string, index *retrieval.Index, embedder provider.Embedder, walk retrievalWalk) (retrieval.UpdateStats, error) {
+	a.retrieval.updateMu.Lock()
+	defer a.retrieval.updateMu.Unlock()
+
+	tree, err := walker.Walk(ctx, rootDir, walk.opts)
+	if err != nil {
+		return retrieval.UpdateStats{}, fmt.Errorf("failed to walk %s: %w", rootDir, err)
+	}
+	var files []retrieval.File
+	tree.Visit(func(entry *walker.Entry) error {
+		if !entry.HasContent() || entry.Marked(walker.FilterSize) {
+			return nil
+		}
+		if (walk.useGitignore && entry.Marked(walker.FilterGitignore)) || (walk.useCustomIgnore && entry.Marked(walker.FilterCustomIgnore)) {
+			return nil
+		}
+		files = append(files, retrieval.File{RelPath: entry.SlashPath(), AbsPath: entry.Path, ModTime: entry.ModTime, Size: entry.Size})
+		return nil
+	})
+
+	stats, err := index.Update(ctx, files, embedder)
+	if saveErr := index.Save(); saveErr != nil && err == nil {
+		err = saveErr
+	}
+	if err == nil {
+		a.retrieval.mu.Lock()
+		if a.retrieval.index == index {
+			a.retrieval.ready = true
+		}
+		a.retrieval.mu.Unlock()
+	}
+	return stats, err
+}
+
+// noteRetrievalChange is called by Watchman for every relevant event. When retrieval
+// is enabled and the project's index is loaded, the changed file is marked stale and
+// a refresh is scheduled; events arriving within retrievalRefreshDelay share it.
+func (a *App) noteRetrievalChange(rootDir string, event fsnotify.Event) {
+	a.retrieval.mu.Lock()
+	defer a.retrieval.mu.Unlock()
+	index, embedder, walk, refreshed := a.retrieval.index, a.retrieval.embedder, a.retrieval.walk, a.retrieval.refreshed
+	if !a.retrieval.enabled || index == nil || a.retrieval.rootDir != rootDir {
+		return
+	}
+	if rel, err := filepath.Rel(rootDir, event.Name); err == nil && !strings.HasPrefix(rel, "..") {
+		index.MarkStale(filepath.ToSlash(rel))
+	}
+	if a.retrieval.timer != nil {
+		a.retrieval.timer.Stop()
+	}
+	// The refresh reuses the index's embedder and the walk captured when the settings
+	// last changed: settings are not read off the goroutines that change them.
+	a.retrieval.timer = time.AfterFunc(retrievalRefreshDelay, func() {
+		stats, err := a.updateRetrievalIndex(a.ctx, rootDir, index, embedder, walk)
+		if refreshed != nil {
+			refreshed(stats, err)
+			return
+		}
+		if err != nil {
+			runtime.LogWarningf(a.ctx, "Retrieval index refresh failed: %v", err)
+			return
+		}
+		runtime.LogDebugf(a.ctx, "Retrieval index refreshed: %d embedded, %d removed", stats.Embedded, stats.Removed)
+	})
+}
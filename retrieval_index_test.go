@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"shotgun_code/internal/retrieval"
+)
+
+// lengthEmbedder embeds a text as its length, enough to fill an index.
+type lengthEmbedder struct{}
+
+func (lengthEmbedder) EmbeddingModel() string { return "test-embedding" }
+
+func (lengthEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = []float32{float32(len(text)), 1}
+	}
+	return vectors, nil
+}
+
+func TestRetrievalRefreshUsesCapturedSettings(t *testing.T) {
+	root := writeProject(t, map[string]string{
+		"app.go":         "package app\n",
+		"scratch/big.go": "package scratch\n",
+		".gitignore":     "scratch/\n",
+	})
+	delay := retrievalRefreshDelay
+	retrievalRefreshDelay = time.Millisecond
+	t.Cleanup(func() { retrievalRefreshDelay = delay })
+
+	index, err := retrieval.Open(filepath.Join(t.TempDir(), "index.gob"), lengthEmbedder{}.EmbeddingModel())
+	if err != nil {
+		t.Fatal(err)
+	}
+	refreshes := make(chan error, 200)
+	a := &App{ctx: context.Background(), useGitignore: true}
+	a.retrieval.enabled, a.retrieval.rootDir = true, root
+	a.retrieval.index, a.retrieval.embedder = index, lengthEmbedder{}
+	a.retrieval.refreshed = func(_ retrieval.UpdateStats, err error) { refreshes <- err }
+	a.noteRetrievalSettings()
+
+	// Settings change here, as in the bound setters, while refreshes run on timers.
+	event := fsnotify.Event{Name: filepath.Join(root, "app.go"), Op: fsnotify.Write}
+	for range 20 {
+		a.noteRetrievalChange(root, event)
+		for i, until := 0, time.Now().Add(2*retrievalRefreshDelay); time.Now().Before(until); i++ {
+			a.useGitignore = i%2 == 1
+			a.settings.MaxFileSizeBytes = int64(1_000 + i)
+			a.settings.SymlinkPolicy = []string{symlinkPolicySkip, symlinkPolicyFollow}[i%2]
+		}
+		a.useGitignore = true
+		a.noteRetrievalSettings()
+	}
+	a.noteRetrievalChange(root, event)
+
+	// Wait until the refreshes settle; the last one walks with gitignore in use.
+	for settled := false; !settled; {
+		select {
+		case err := <-refreshes:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(200 * time.Millisecond):
+			settled = true
+		}
+	}
+	if files, _ := index.Len(); files != 2 {
+		t.Errorf("index has %d files, want app.go and .gitignore without the gitignored file", files)
+	}
+}
@@ -3,7 +3,6 @@ package main
 import (
 	"context"
 	"fmt"
-	"regexp"
 	"strings"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
@@ -21,42 +20,16 @@ func (a *App) SplitShotgunDiff(gitDiffText string, approxLineLimit int) ([]strin
 		return []string{}, nil
 	}
 
-	// Regex to find the start of a file diff block.
-	// Go's regex engine (RE2) does not support lookarounds like (?=).
-	// We will find the start indices of each file block and split manually.
-	fileDiffStartRegex := regexp.MustCompile(`(?m)^diff --git `)
-	startIndices := fileDiffStartRegex.FindAllStringIndex(gitDiffText, -1)
-
-	var fileDiffBlocks []string
-
-	if len(startIndices) == 0 {
+	fileDiffBlocks, found := splitGitDiffBlocks(gitDiffText)
+	if !found {
 		// If no "diff --git" is found, treat the whole input as a single block
 		runtime.LogWarning(a.ctx, fmt.Sprintf("SplitShotgunDiff: No 'diff --git' blocks found in input. Treating as single block."))
-		if strings.TrimSpace(gitDiffText) != "" {
-			fileDiffBlocks = append(fileDiffBlocks, gitDiffText)
-		}
-	} else {
-		// Split the text based on the start indices
-		for i := 0; i < len(startIndices); i++ {
-			start := startIndices[i][0]
-			end := len(gitDiffText)
-			if i+1 < len(startIndices) {
-				end = startIndices[i+1][0]
-			}
-			block := gitDiffText[start:end]
-			block = strings.TrimSpace(block)
-			if block != "" {
-				fileDiffBlocks = append(fileDiffBlocks, block)
-			}
-		}
 	}
 
 	var splitDiffs []string
 	var currentSplitContent strings.Builder
 	currentSplitLines := 0
 
-	hunkHeaderRegex := regexp.MustCompile(`^@@ .* @@`)
-
 	for _, fileBlock := range fileDiffBlocks {
 		// fileBlock is already trimmed by the splitting logic above, but continue check is fine
 		if fileBlock == "" { continue }
@@ -74,55 +47,39 @@ func (a *App) SplitShotgunDiff(gitDiffText string, approxLineLimit int) ([]strin
 			}
 
 			// This fileBlock is too large, needs to be split by hunks
-			// Extract file header (lines before the first hunk)
-			firstHunkIndex := -1
-			for i, line := range fileBlockLines {
-				if hunkHeaderRegex.MatchString(line) {
-					firstHunkIndex = i
-					break
-				}
-			}
-
-			if firstHunkIndex == -1 { // No hunks found, but block is large? Unusual. Treat as one large piece.
+			fileDiff := parseGitFileDiff(fileBlock)
+			if len(fileDiff.Hunks) == 0 { // No hunks found, but block is large? Unusual. Treat as one large piece.
 				runtime.LogWarning(a.ctx, fmt.Sprintf("SplitShotgunDiff: Large file block without hunks in '%s'. Treating as single block.", getPathFromDiffHeader(fileBlockLines[0])))
 				splitDiffs = append(splitDiffs, fileBlock+"\n") // Add newline for consistency if it's a full block
 				continue
 			}
 
-			fileHeader := strings.Join(fileBlockLines[:firstHunkIndex], "\n") + "\n"
-			numLinesInHeader := firstHunkIndex
+			// File header: the lines before the first hunk
+			fileHeader := strings.Join(fileDiff.Header, "\n") + "\n"
+			numLinesInHeader := len(fileDiff.Header)
 
 			var currentFileSplitHunks strings.Builder
 			currentFileSplitHunkLines := 0
 
-			hunkStartIndex := firstHunkIndex
-			for hunkStartIndex < len(fileBlockLines) {
-				// Find the end of the current hunk
-				hunkEndIndex := hunkStartIndex + 1
-				for hunkEndIndex < len(fileBlockLines) && !hunkHeaderRegex.MatchString(fileBlockLines[hunkEndIndex]) {
-					hunkEndIndex++
-				}
-				
-				currentHunkContent := strings.Join(fileBlockLines[hunkStartIndex:hunkEndIndex], "\n")
-				numLinesInCurrentHunk := hunkEndIndex - hunkStartIndex
+			for _, hunk := range fileDiff.Hunks {
+				currentHunkContent := strings.Join(append([]string{hunk.Header}, hunk.Lines...), "\n")
+				numLinesInCurrentHunk := 1 + len(hunk.Lines)
 
 				// If this single hunk (plus header) is larger than limit, it gets its own split
-				if numLinesInHeader + numLinesInCurrentHunk > approxLineLimit && currentFileSplitHunkLines == 0 {
-					splitDiffs = append(splitDiffs, fileHeader + currentHunkContent + "\n")
-					hunkStartIndex = hunkEndIndex
+				if numLinesInHeader+numLinesInCurrentHunk > approxLineLimit && currentFileSplitHunkLines == 0 {
+					splitDiffs = append(splitDiffs, fileHeader+currentHunkContent+"\n")
 					continue
 				}
 
 				// If adding this hunk exceeds the limit (for this file's partial split)
-				if currentFileSplitHunkLines > 0 && (numLinesInHeader + currentFileSplitHunkLines + numLinesInCurrentHunk > approxLineLimit) {
-					splitDiffs = append(splitDiffs, fileHeader + currentFileSplitHunks.String())
+				if currentFileSplitHunkLines > 0 && (numLinesInHeader+currentFileSplitHunkLines+numLinesInCurrentHunk > approxLineLimit) {
+					splitDiffs = append(splitDiffs, fileHeader+currentFileSplitHunks.String())
 					currentFileSplitHunks.Reset()
 					currentFileSplitHunkLines = 0
 				}
 
 				currentFileSplitHunks.WriteString(currentHunkContent + "\n")
 				currentFileSplitHunkLines += numLinesInCurrentHunk
-				hunkStartIndex = hunkEndIndex
 			}
 
 			// Add any remaining hunks for the current file
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"shotgun_code/internal/symbols"
+	"shotgun_code/internal/walker"
+)
+
+// GetIncludeSymbolMap reports whether generated context lists the symbols of unselected files.
+func (a *App) GetIncludeSymbolMap() bool {
+	return a.settings.IncludeSymbolMap
+}
+
+// SetIncludeSymbolMap toggles the symbol map section of the generated context.
+func (a *App) SetIncludeSymbolMap(enabled bool) error {
+	a.settings.IncludeSymbolMap = enabled
+	runtime.LogInfof(a.ctx, "App setting includeSymbolMap changed to: %v", enabled)
+	if err := a.saveSettings(); err != nil {
+		return err
+	}
+	a.notifySettingsChanged()
+	return nil
+}
+
+// buildSymbolMap lists the exported symbols of every file the tree shows that is
+// not part of selected, so the model sees a map of the whole repository while only
+// selected files carry full contents. Files are skipped when the active ignore
+// toggles deselect them, when they are over the per-file ceiling or binary. The
+// listing comes from the search index, which Watchman keeps current, and symbols
+// are cached until a file changes. progress is called as files are read; the
+// section is cut short with a note so it never exceeds budget bytes.
+func (a *App) buildSymbolMap(ctx context.Context, rootDir string, selected *walker.Entry, budget int, progress func(done, total int)) (string, error) {
+	inSelection := make(map[string]bool)
+	selected.Visit(func(entry *walker.Entry) error {
+		if entry.HasContent() {
+			inSelection[entry.SlashPath()] = true
+		}
+		return nil
+	})
+
+	index, err := a.fileSearchIndex(rootDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to list project for symbol map: %w", err)
+	}
+	var files []symbolFile
+	listed := make(map[string]bool) // Full paths worth keeping in the cache
+	index.mu.RLock()
+	for rel, p := range index.paths {
+		if !p.hasContent {
+			continue
+		}
+		full := filepath.Join(rootDir, filepath.FromSlash(rel))
+		listed[full] = true
+		if inSelection[rel] || (a.useGitignore && p.gitignored) || (a.useCustomIgnore && p.customIgnored) {
+			continue
+		}
+		files = append(files, symbolFile{relPath: rel, path: full})
+	}
+	index.mu.RUnlock()
+	sort.Slice(files, func(i, j int) bool { return files[i].relPath < files[j].relPath })
+
+	sizeLimit := a.largeFileSettings().MaxFileSizeBytes
+	symbolMap, err := writeSymbolMap(ctx, files, func(f symbolFile) []symbols.Symbol {
+		return a.symbolCache.extract(f.path, sizeLimit)
+	}, budget, progress)
+	if err == nil {
+		a.symbolCache.retain(listed)
+	}
+	return symbolMap, err
+}
+
+// symbolFile is a file the symbol map may list.
+type symbolFile struct {
+	relPath string // Slash-separated, relative to the project root
+	path    string // Full path
+}
+
+// writeSymbolMap renders the <symbols> section for files, in order, with the
+// symbols extract finds in each. Once a block would not fit, it and all later
+// files are replaced by a note; the whole section, note included, stays within
+// budget bytes, or is "" when even the note does not fit. It also returns "" when
+// no file has symbols.
+func writeSymbolMap(ctx context.Context, files []symbolFile, extract func(symbolFile) []symbols.Symbol, budget int, progress func(done, total int)) (string, error) {
+	const header, footer = "<symbols>\n", "</symbols>\n"
+	note := func(omitted int) string {
+		if omitted == 0 {
+			return ""
+		}
+		return fmt.Sprintf("(%d more files omitted to stay within the context limit)\n", omitted)
+	}
+
+	var blocks []string
+	for i, f := range files {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		if syms := extract(f); len(syms) > 0 {
+			blocks = append(blocks, symbols.Format(f.relPath, syms))
+		}
+		if progress != nil {
+			progress(i+1, len(files))
+		}
+	}
+	if len(blocks) == 0 {
+		return "", nil
+	}
+
+	// Keep the longest prefix of blocks that fits together with the note.
+	kept, size := 0, 0
+	for kept < len(blocks) && len(header)+size+len(blocks[kept])+len(note(len(blocks)-kept-1))+len(footer) <= budget {
+		size += len(blocks[kept])
+		kept++
+	}
+	if len(header)+size+len(note(len(blocks)-kept))+len(footer) > budget {
+		return "", nil
+	}
+	var b strings.Builder
+	b.WriteString(header)
+	for _, block := range blocks[:kept] {
+		b.WriteString(block)
+	}
+	b.WriteString(note(len(blocks) - kept))
+	b.WriteString(footer)
+	return b.String(), nil
+}
+
+// symbolCache keeps the symbols of files by full path until their size or
+// modification time changes. The zero value is ready to use.
+type symbolCache struct {
+	mu    sync.Mutex
+	files map[string]cachedSymbols
+}
+
+type cachedSymbols struct {
+	size    int64
+	modTime time.Time
+	symbols []symbols.Symbol // nil for binary files and files without symbols
+}
+
+// extract returns the symbols of the file at path, reading it only when it
+// changed since the last call. Files above sizeLimit and binary files have none.
+func (c *symbolCache) extract(path string, sizeLimit int64) []symbols.Symbol {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() > sizeLimit {
+		return nil
+	}
+	c.mu.Lock()
+	cached, ok := c.files[path]
+	c.mu.Unlock()
+	if ok && cached.size == info.Size() && cached.modTime.Equal(info.ModTime()) {
+		return cached.symbols
+	}
+
+	content, _, err := readFileLimited(path, sizeLimit)
+	if err != nil {
+		return nil
+	}
+	var syms []symbols.Symbol
+	if !walker.IsBinary(content) {
+		syms = symbols.Extract(filepath.ToSlash(path), content)
+	}
+	c.mu.Lock()
+	if c.files == nil {
+		c.files = make(map[string]cachedSymbols)
+	}
+	c.files[path] = cachedSymbols{size: info.Size(), modTime: info.ModTime(), symbols: syms}
+	c.mu.Unlock()
+	return syms
+}
+
+// retain drops cached files whose full path is not in keep, e.g. after they were
+// deleted or the project changed.
+func (c *symbolCache) retain(keep map[string]bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for path := range c.files {
+		if !keep[path] {
+			delete(c.files, path)
+		}
+	}
+}
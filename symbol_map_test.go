@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"shotgun_code/internal/ignore"
+	"shotgun_code/internal/symbols"
+	"shotgun_code/internal/walker"
+)
+
+func TestWriteSymbolMapStaysWithinBudget(t *testing.T) {
+	var files []symbolFile
+	for i := range 20 {
+		files = append(files, symbolFile{relPath: fmt.Sprintf("pkg/file%02d.go", i)})
+	}
+	extract := func(symbolFile) []symbols.Symbol {
+		return []symbols.Symbol{{Name: "Exported", Kind: "func", Line: 1}}
+	}
+	full, err := writeSymbolMap(context.Background(), files, extract, 1<<20, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for budget := 0; budget <= len(full); budget++ {
+		got, err := writeSymbolMap(context.Background(), files, extract, budget, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) > budget {
+			t.Fatalf("budget %d: section is %d bytes", budget, len(got))
+		}
+		if got != "" && got != full && !strings.Contains(got, "more files omitted") {
+			t.Fatalf("budget %d: shortened section has no note:\n%s", budget, got)
+		}
+	}
+}
+
+func TestSymbolCacheRereadsChangedFiles(t *testing.T) {
+	root := t.TempDir()
+	file := filepath.Join(root, "a.go")
+	if err := os.WriteFile(file, []byte("package a\n\nfunc First() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var cache symbolCache
+	if got := cache.extract(file, 1000); len(got) != 1 || got[0].Name != "First" {
+		t.Fatalf("symbols = %v, want First", got)
+	}
+	if err := os.WriteFile(file, []byte("package a\n\nfunc Second() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(file, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if got := cache.extract(file, 1000); len(got) != 1 || got[0].Name != "Second" {
+		t.Fatalf("symbols after change = %v, want Second", got)
+	}
+	cache.retain(map[string]bool{})
+	if len(cache.files) != 0 {
+		t.Errorf("retain kept %d files", len(cache.files))
+	}
+}
+
+func TestBuildSymbolMapReportsProgress(t *testing.T) {
+	root := writeProject(t, map[string]string{
+		"main.go":     "package main\n\nfunc main() {}\n",
+		"lib/a.go":    "package lib\n\nfunc A() {}\n",
+		"lib/b.py":    "class B:\n  def run(self):\n    pass\n",
+		"vendor/v.go": "package v\n\nfunc V() {}\n",
+	})
+	a := &App{ctx: context.Background(), useCustomIgnore: true}
+	a.settings.MaxFileSizeBytes = 1000
+	a.settings.CustomIgnoreRules = "vendor/\n"
+	a.currentCustomIgnorePatterns = ignore.ParseRules(customIgnoreRulesSource, "", a.settings.CustomIgnoreRules)
+	// Only main.go is selected.
+	selected, err := walker.Walk(context.Background(), root, a.selectionWalkOptions([]string{"lib"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls, lastDone, lastTotal int
+	section, err := a.buildSymbolMap(context.Background(), root, selected, 1<<20, func(done, total int) {
+		calls++
+		lastDone, lastTotal = done, total
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 || lastDone != 2 || lastTotal != 2 {
+		t.Errorf("progress: %d calls, last %d/%d; want 2 calls ending at 2/2", calls, lastDone, lastTotal)
+	}
+	for _, want := range []string{"lib/a.go", "B.run"} {
+		if !strings.Contains(section, want) {
+			t.Errorf("symbol map lacks %s:\n%s", want, section)
+		}
+	}
+	for _, unwanted := range []string{"main.go", "vendor/v.go"} {
+		if strings.Contains(section, unwanted) {
+			t.Errorf("symbol map lists %s:\n%s", unwanted, section)
+		}
+	}
+}
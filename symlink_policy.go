@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"shotgun_code/internal/walker"
+)
+
+// Symlink policies as stored in settings and exchanged with the frontend.
+const (
+	symlinkPolicySkip   = "skip"   // Leave symlinks out of the tree and the context
+	symlinkPolicyList   = "list"   // Show symlinks in the tree, never read their targets
+	symlinkPolicyFollow = "follow" // Treat links to paths inside the project like their targets
+)
+
+// walkerSymlinkPolicy maps the saved setting to the walker policy. Unknown or empty
+// values fall back to listing links without following them.
+func (a *App) walkerSymlinkPolicy() walker.SymlinkPolicy {
+	switch a.settings.SymlinkPolicy {
+	case symlinkPolicySkip:
+		return walker.SymlinkSkip
+	case symlinkPolicyFollow:
+		return walker.SymlinkFollowWithinRoot
+	default:
+		return walker.SymlinkListOnly
+	}
+}
+
+// GetSymlinkPolicy returns the symlink policy: "skip", "list" or "follow".
+func (a *App) GetSymlinkPolicy() string {
+	if a.settings.SymlinkPolicy == "" {
+		return symlinkPolicyList
+	}
+	return a.settings.SymlinkPolicy
+}
+
+// SetSymlinkPolicy saves the symlink policy and refreshes the file tree.
+func (a *App) SetSymlinkPolicy(policy string) error {
+	switch policy {
+	case symlinkPolicySkip, symlinkPolicyList, symlinkPolicyFollow:
+	default:
+		return fmt.Errorf("unknown symlink policy %q (expected %q, %q or %q)", policy, symlinkPolicySkip, symlinkPolicyList, symlinkPolicyFollow)
+	}
+	a.settings.SymlinkPolicy = policy
+	runtime.LogInfof(a.ctx, "App setting symlinkPolicy changed to: %s", policy)
+	if err := a.saveSettings(); err != nil {
+		return err
+	}
+	a.notifySettingsChanged()
+	return nil
+}
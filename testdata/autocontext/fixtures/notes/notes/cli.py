@@ -0,0 +1,22 @@
+import argparse
+from pathlib import Path
+
+from .store import NoteStore
+
+
+def main() -> None:
+    parser = argparse.ArgumentParser(prog="notes")
+    sub = parser.add_subparsers(dest="command", required=True)
+    add = sub.add_parser("add", help="add a note")
+    add.add_argument("title")
+    add.add_argument("body")
+    sub.add_parser("list", help="list notes")
+    args = parser.parse_args()
+
+    store = NoteStore(Path.home() / ".notes.json")
+    if args.command == "add":
+        note = store.add(args.title, args.body)
+        print(f"added note {note.id}")
+    elif args.command == "list":
+        for note in store.load():
+            print(f"{note.id}: {note.title}")
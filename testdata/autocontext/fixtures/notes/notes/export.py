@@ -0,0 +1,6 @@
+from .store import NoteStore
+
+
+def to_markdown(store: NoteStore) -> str:
+    """Renders every note as a Markdown section."""
+    return "\n\n".join(f"## {n.title}\n\n{n.body}" for n in store.load())
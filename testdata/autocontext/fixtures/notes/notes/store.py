@@ -0,0 +1,32 @@
+import json
+from dataclasses import dataclass, asdict
+from pathlib import Path
+
+
+@dataclass
+class Note:
+    id: int
+    title: str
+    body: str
+
+
+class NoteStore:
+    """Keeps notes in a JSON file."""
+
+    def __init__(self, path: Path):
+        self.path = path
+
+    def load(self) -> list[Note]:
+        if not self.path.exists():
+            return []
+        return [Note(**raw) for raw in json.loads(self.path.read_text())]
+
+    def save(self, notes: list[Note]) -> None:
+        self.path.write_text(json.dumps([asdict(n) for n in notes], indent=2))
+
+    def add(self, title: str, body: str) -> Note:
+        notes = self.load()
+        note = Note(id=max((n.id for n in notes), default=0) + 1, title=title, body=body)
+        notes.append(note)
+        self.save(notes)
+        return note
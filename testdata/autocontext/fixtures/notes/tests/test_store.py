@@ -0,0 +1,7 @@
+from notes.store import NoteStore
+
+
+def test_add_assigns_increasing_ids(tmp_path):
+    store = NoteStore(tmp_path / "notes.json")
+    assert store.add("a", "x").id == 1
+    assert store.add("b", "y").id == 2
@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+type credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// HandleLogin checks the credentials and starts a session.
+func HandleLogin(w http.ResponseWriter, r *http.Request) {
+	var creds credentials
+	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if !checkPassword(creds.Username, creds.Password) {
+		http.Error(w, "invalid username or password", http.StatusUnauthorized)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: "session", Value: newSession(creds.Username), HttpOnly: true})
+}
+
+func checkPassword(username, password string) bool {
+	hash, ok := users[username]
+	return ok && hash == hashPassword(password)
+}
@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sync"
+)
+
+var (
+	users    = map[string]string{}
+	mu       sync.Mutex
+	sessions = map[string]string{}
+)
+
+func hashPassword(password string) string {
+	sum := sha256.Sum256([]byte(password))
+	return hex.EncodeToString(sum[:])
+}
+
+func newSession(username string) string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	id := hex.EncodeToString(buf)
+	mu.Lock()
+	sessions[id] = username
+	mu.Unlock()
+	return id
+}
+
+// RequireSession rejects requests without a valid session cookie.
+func RequireSession(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("session")
+		mu.Lock()
+		_, ok := sessions[cookie.Value]
+		mu.Unlock()
+		if err != nil || !ok {
+			http.Error(w, "login required", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,27 @@
+package catalog
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Product is an item for sale. Prices are in cents.
+type Product struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Price int    `json:"price"`
+}
+
+var Products = map[string]Product{
+	"mug":   {ID: "mug", Name: "Coffee mug", Price: 1200},
+	"shirt": {ID: "shirt", Name: "T-shirt", Price: 2500},
+}
+
+// HandleList returns every product.
+func HandleList(w http.ResponseWriter, r *http.Request) {
+	list := make([]Product, 0, len(Products))
+	for _, p := range Products {
+		list = append(list, p)
+	}
+	json.NewEncoder(w).Encode(list)
+}
@@ -0,0 +1,26 @@
+package checkout
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"webshop/orders"
+)
+
+type checkoutRequest struct {
+	Lines []orders.Line `json:"lines"`
+}
+
+// HandleCheckout turns the posted cart into an order.
+func HandleCheckout(w http.ResponseWriter, r *http.Request) {
+	var req checkoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if len(req.Lines) == 0 {
+		http.Error(w, "cart is empty", http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(orders.New(req.Lines))
+}
@@ -0,0 +1,3 @@
+module webshop
+
+go 1.22
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"log"
+	"net/http"
+
+	"webshop/auth"
+	"webshop/catalog"
+	"webshop/checkout"
+)
+
+func main() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", auth.HandleLogin)
+	mux.HandleFunc("/products", catalog.HandleList)
+	mux.HandleFunc("/checkout", auth.RequireSession(checkout.HandleCheckout))
+	log.Fatal(http.ListenAndServe(":8080", mux))
+}
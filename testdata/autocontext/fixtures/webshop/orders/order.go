@@ -0,0 +1,24 @@
+package orders
+
+import "webshop/catalog"
+
+// Line is a product and quantity in an order.
+type Line struct {
+	ProductID string `json:"productId"`
+	Quantity  int    `json:"quantity"`
+}
+
+// Order is a checked-out cart.
+type Order struct {
+	Lines []Line `json:"lines"`
+	Total int    `json:"total"` // Cents
+}
+
+// New prices the lines of an order.
+func New(lines []Line) Order {
+	order := Order{Lines: lines}
+	for _, line := range lines {
+		order.Total += catalog.Products[line.ProductID].Price * line.Quantity
+	}
+	return order
+}
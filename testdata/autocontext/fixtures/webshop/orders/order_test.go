@@ -0,0 +1,10 @@
+package orders
+
+import "testing"
+
+func TestNewTotal(t *testing.T) {
+	order := New([]Line{{ProductID: "mug", Quantity: 2}, {ProductID: "shirt", Quantity: 1}})
+	if order.Total != 4900 {
+		t.Fatalf("total = %d, want 4900", order.Total)
+	}
+}
@@ -0,0 +1,6 @@
+package scratch
+
+// Scratch notes, ignored by git: rate limit failed login attempts per username.
+// Count failed login attempts per username and rate limit the login once the
+// failed attempts pass the limit. Auto-context must never select this file.
+func rateLimitLogin(username string, failedAttempts int) bool { return failedAttempts > 5 }